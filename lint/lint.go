@@ -0,0 +1,305 @@
+// Package lint checks ClickHouse SQL for patterns that parse and run but are
+// likely mistakes, such as a materialized view reading a table with FINAL or
+// an ALTER TABLE UPDATE whose WHERE matches every row.
+//
+// A Linter runs a set of rules over every statement of its input. A rule
+// inspects the AST of one statement, through parser.Walk or a
+// parser.ASTVisitor, and reports diagnostics on the nodes it objects to.
+// DefaultRules returns the built-in rules; NewRule makes custom ones.
+//
+// Diagnostics can be silenced with comments in the SQL itself:
+//
+//	SELECT * FROM events -- lint:disable-line missing-limit
+//
+//	-- lint:disable-next-line
+//	SELECT * FROM events
+//
+//	/* lint:disable select-star-in-view, missing-limit */
+//	...
+//	/* lint:enable */
+//
+// A directive without rule names applies to every rule.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AfterShip/clickhouse-sql-parser/parser"
+)
+
+// Severity is how serious the problem a Diagnostic reports is.
+type Severity string
+
+const (
+	// SeverityError marks statements that are almost certainly wrong.
+	SeverityError Severity = "ERROR"
+	// SeverityWarning marks statements that are often wrong or fragile.
+	SeverityWarning Severity = "WARNING"
+	// SeverityInfo marks statements that are fine in some contexts, such as
+	// an unbounded query in a batch job.
+	SeverityInfo Severity = "INFO"
+)
+
+// Diagnostic is a problem a rule found. Pos and End delimit the offending
+// node in the linted SQL.
+type Diagnostic struct {
+	Rule     string
+	Severity Severity
+	Pos      parser.Pos
+	End      parser.Pos
+	Msg      string
+}
+
+// Rule is a check run on every statement. Name identifies the rule in
+// diagnostics and in disable comments.
+type Rule interface {
+	Name() string
+	Severity() Severity
+	Check(pass *Pass)
+}
+
+// Pass is a rule's view of the statement it checks.
+type Pass struct {
+	// SQL is the whole input the statement was parsed from; positions are
+	// offsets into it.
+	SQL  string
+	Stmt parser.Expr
+
+	rule        Rule
+	diagnostics []Diagnostic
+}
+
+// Report records a diagnostic spanning node.
+func (p *Pass) Report(node parser.Expr, format string, args ...any) {
+	p.ReportRange(node.Pos(), node.End(), format, args...)
+}
+
+// ReportRange records a diagnostic spanning pos to end.
+func (p *Pass) ReportRange(pos, end parser.Pos, format string, args ...any) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Rule:     p.rule.Name(),
+		Severity: p.rule.Severity(),
+		Pos:      pos,
+		End:      end,
+		Msg:      fmt.Sprintf(format, args...),
+	})
+}
+
+// NewRule returns a Rule that runs check.
+func NewRule(name string, severity Severity, check func(pass *Pass)) Rule {
+	return &funcRule{name: name, severity: severity, check: check}
+}
+
+type funcRule struct {
+	name     string
+	severity Severity
+	check    func(pass *Pass)
+}
+
+func (r *funcRule) Name() string       { return r.name }
+func (r *funcRule) Severity() Severity { return r.severity }
+func (r *funcRule) Check(pass *Pass)   { r.check(pass) }
+
+// Linter runs a set of rules over SQL.
+type Linter struct {
+	rules []Rule
+}
+
+// New returns a Linter running rules, or the DefaultRules when none are
+// given.
+func New(rules ...Rule) *Linter {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	return &Linter{rules: rules}
+}
+
+// Lint parses sql and returns the diagnostics of every rule on every
+// statement, ordered by position, leaving out those silenced by comments.
+func (l *Linter) Lint(sql string) ([]Diagnostic, error) {
+	stmts, err := parser.NewParser(sql).ParseStmts()
+	if err != nil {
+		return nil, err
+	}
+	return l.LintStmts(sql, stmts), nil
+}
+
+// LintStmts is like Lint for statements already parsed from sql.
+func (l *Linter) LintStmts(sql string, stmts []parser.Expr) []Diagnostic {
+	directives := parseDirectives(sql)
+	var diagnostics []Diagnostic
+	for _, stmt := range stmts {
+		for _, rule := range l.rules {
+			pass := &Pass{SQL: sql, Stmt: stmt, rule: rule}
+			rule.Check(pass)
+			for _, diagnostic := range pass.diagnostics {
+				if !directives.suppresses(diagnostic) {
+					diagnostics = append(diagnostics, diagnostic)
+				}
+			}
+		}
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Pos < diagnostics[j].Pos
+	})
+	return diagnostics
+}
+
+const directivePrefix = "lint:"
+
+// directives holds the disable comments of the linted SQL.
+type directives struct {
+	lineStarts []int
+	// lines maps a line number to the rules disabled on it; an empty rule
+	// name stands for every rule.
+	lines map[int]map[string]bool
+	// ranges are the spans between a disable comment and the matching
+	// enable comment or the end of the input.
+	ranges []disabledRange
+}
+
+type disabledRange struct {
+	rule     string
+	pos, end int
+}
+
+func (d *directives) suppresses(diagnostic Diagnostic) bool {
+	pos := int(diagnostic.Pos)
+	if rules := d.lines[d.line(pos)]; rules[""] || rules[diagnostic.Rule] {
+		return true
+	}
+	for _, r := range d.ranges {
+		if (r.rule == "" || r.rule == diagnostic.Rule) && pos >= r.pos && pos < r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// line returns the 0-based line of an offset.
+func (d *directives) line(offset int) int {
+	return sort.Search(len(d.lineStarts), func(i int) bool {
+		return d.lineStarts[i] > offset
+	}) - 1
+}
+
+func parseDirectives(sql string) *directives {
+	d := &directives{lineStarts: []int{0}, lines: make(map[int]map[string]bool)}
+	for i := 0; i < len(sql); i++ {
+		if sql[i] == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+	open := make(map[string]int)
+	for _, c := range scanComments(sql) {
+		body, ok := strings.CutPrefix(strings.TrimSpace(c.text), directivePrefix)
+		if !ok {
+			continue
+		}
+		fields := strings.FieldsFunc(body, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+		})
+		if len(fields) == 0 {
+			continue
+		}
+		rules := fields[1:]
+		if len(rules) == 0 {
+			rules = []string{""}
+		}
+		switch fields[0] {
+		case "disable-line":
+			d.disableLine(d.line(c.pos), rules)
+		case "disable-next-line":
+			d.disableLine(d.line(c.end)+1, rules)
+		case "disable":
+			for _, rule := range rules {
+				if _, ok := open[rule]; !ok {
+					open[rule] = c.end
+				}
+			}
+		case "enable":
+			for rule, pos := range open {
+				if rules[0] == "" || contains(rules, rule) {
+					d.ranges = append(d.ranges, disabledRange{rule: rule, pos: pos, end: c.pos})
+					delete(open, rule)
+				}
+			}
+		}
+	}
+	for rule, pos := range open {
+		d.ranges = append(d.ranges, disabledRange{rule: rule, pos: pos, end: len(sql)})
+	}
+	return d
+}
+
+func (d *directives) disableLine(line int, rules []string) {
+	if d.lines[line] == nil {
+		d.lines[line] = make(map[string]bool)
+	}
+	for _, rule := range rules {
+		d.lines[line][rule] = true
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type comment struct {
+	pos, end int
+	// text is the comment without its delimiters.
+	text string
+}
+
+// scanComments returns the -- and /* */ comments of sql, skipping quoted
+// strings and identifiers.
+func scanComments(sql string) []comment {
+	var comments []comment
+	for i := 0; i < len(sql); i++ {
+		switch {
+		case sql[i] == '\'' || sql[i] == '"' || sql[i] == '`':
+			i = skipQuoted(sql, i)
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			comments = append(comments, comment{pos: i, end: i + end, text: sql[i+2 : i+end]})
+			i += end
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return comments
+			}
+			comments = append(comments, comment{pos: i, end: i + end + 4, text: sql[i+2 : i+2+end]})
+			i += end + 3
+		}
+	}
+	return comments
+}
+
+// skipQuoted returns the offset of the quote closing the one at start.
+// Quotes are escaped by a backslash or by doubling them.
+func skipQuoted(sql string, start int) int {
+	quote := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			i++
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(sql)
+}
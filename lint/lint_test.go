@@ -0,0 +1,79 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/AfterShip/clickhouse-sql-parser/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	sql := "CREATE MATERIALIZED VIEW mv TO dst AS SELECT *, now() FROM src FINAL;\n" +
+		"ALTER TABLE t UPDATE a = 1 WHERE true;\n"
+	diagnostics, err := New().Lint(sql)
+	require.NoError(t, err)
+	var rules []string
+	for _, diagnostic := range diagnostics {
+		rules = append(rules, diagnostic.Rule)
+	}
+	require.Equal(t, []string{
+		"select-star-in-view",
+		"nondeterministic-function-in-materialized-view",
+		"final-in-materialized-view",
+		"update-without-where",
+	}, rules)
+	require.Equal(t, SeverityError, diagnostics[3].Severity)
+	require.Equal(t, "UPDATE has no filtering WHERE and rewrites every row of t", diagnostics[3].Msg)
+
+	_, err = New().Lint("SELECT FROM WHERE")
+	require.Error(t, err)
+}
+
+func TestLintDisableComments(t *testing.T) {
+	lint := func(sql string) []string {
+		diagnostics, err := New(MissingLimit, JoinWithoutConstraint).Lint(sql)
+		require.NoError(t, err)
+		var got []string
+		for _, diagnostic := range diagnostics {
+			got = append(got, diagnostic.Rule+" "+sql[diagnostic.Pos:diagnostic.End])
+		}
+		return got
+	}
+
+	require.Equal(t, []string{"missing-limit SELECT b FROM t"}, lint(
+		"SELECT a FROM t; -- lint:disable-line missing-limit\n"+
+			"SELECT b FROM t; -- lint:disable-line join-without-constraint\n"+
+			"-- lint:disable-next-line\n"+
+			"SELECT c FROM t;\n"+
+			"SELECT '-- lint:disable-line' FROM t LIMIT 1;",
+	))
+	require.Equal(t, []string{"join-without-constraint u", "missing-limit SELECT c FROM t"}, lint(
+		"/* lint:disable missing-limit */\n"+
+			"SELECT a FROM t JOIN u;\n"+
+			"SELECT b FROM t;\n"+
+			"/* lint:enable */\n"+
+			"SELECT c FROM t;",
+	))
+	require.Empty(t, lint("/* lint:disable */ SELECT a FROM t JOIN u"))
+}
+
+func TestCustomRule(t *testing.T) {
+	noDrop := NewRule("no-drop", SeverityError, func(pass *Pass) {
+		parser.Walk(pass.Stmt, func(node parser.Expr) bool {
+			if drop, ok := node.(*parser.DropStmt); ok {
+				pass.Report(drop, "DROP statements are not allowed")
+			}
+			return true
+		})
+	})
+	sql := "SELECT 1; DROP TABLE t"
+	diagnostics, err := New(noDrop).Lint(sql)
+	require.NoError(t, err)
+	require.Equal(t, []Diagnostic{{
+		Rule:     "no-drop",
+		Severity: SeverityError,
+		Pos:      10,
+		End:      parser.Pos(len(sql)),
+		Msg:      "DROP statements are not allowed",
+	}}, diagnostics)
+}
@@ -0,0 +1,366 @@
+package lint
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/AfterShip/clickhouse-sql-parser/parser"
+)
+
+var (
+	// SelectStarInView reports views and materialized views that select *.
+	// Their columns are fixed when they are created, so columns later added
+	// to the source table are silently left out, and a materialized view
+	// inserting into a TO table breaks when the source gains a column the
+	// target lacks.
+	SelectStarInView = NewRule("select-star-in-view", SeverityWarning, checkSelectStarInView)
+	// MissingLimit reports ad-hoc SELECT statements that read a table without
+	// a LIMIT. Queries that aggregate without GROUP BY, and so return a single
+	// row, and queries writing INTO OUTFILE are left alone.
+	MissingLimit = NewRule("missing-limit", SeverityInfo, checkMissingLimit)
+	// JoinWithoutConstraint reports joins with neither ON nor USING, or with
+	// an always-true ON, which produce the cross product of both sides.
+	// Explicit CROSS JOINs and comma joins are left alone.
+	JoinWithoutConstraint = NewRule("join-without-constraint", SeverityWarning, checkJoinWithoutConstraint)
+	// FinalInMaterializedView reports FINAL in the query of a materialized
+	// view. The query only sees the block being inserted, so FINAL cannot
+	// merge it with the rows already stored.
+	FinalInMaterializedView = NewRule("final-in-materialized-view", SeverityWarning, checkFinalInMaterializedView)
+	// NondeterministicFunctionInMaterializedView reports functions such as
+	// now() and rand() in the query of a materialized view, whose stored
+	// results then differ from what the query returns when run again.
+	NondeterministicFunctionInMaterializedView = NewRule("nondeterministic-function-in-materialized-view",
+		SeverityWarning, checkNondeterministicFunctionInMaterializedView)
+	// UpdateWithoutWhere reports ALTER TABLE ... UPDATE mutations whose WHERE
+	// is always true and so rewrite every row of the table.
+	UpdateWithoutWhere = NewRule("update-without-where", SeverityError, checkUpdateWithoutWhere)
+	// NullableSortingKey reports Nullable columns in the ORDER BY or PRIMARY
+	// KEY of a table, including columns declared with a bare NULL modifier.
+	// MergeTree rejects them unless allow_nullable_key is set, and they make
+	// the primary index less effective.
+	NullableSortingKey = NewRule("nullable-sorting-key", SeverityWarning, checkNullableSortingKey)
+)
+
+// DefaultRules returns the built-in rules.
+func DefaultRules() []Rule {
+	return []Rule{
+		SelectStarInView,
+		MissingLimit,
+		JoinWithoutConstraint,
+		FinalInMaterializedView,
+		NondeterministicFunctionInMaterializedView,
+		UpdateWithoutWhere,
+		NullableSortingKey,
+	}
+}
+
+func checkSelectStarInView(pass *Pass) {
+	var query *parser.SubQuery
+	switch stmt := pass.Stmt.(type) {
+	case *parser.CreateView:
+		query = stmt.SubQuery
+	case *parser.CreateMaterializedView:
+		query = stmt.SubQuery
+	}
+	if query == nil {
+		return
+	}
+	for _, selectQuery := range setOperands(query.Select) {
+		for _, item := range selectQuery.SelectItems {
+			var star *parser.Ident
+			switch expr := item.Expr.(type) {
+			case *parser.Ident:
+				star = expr
+			case *parser.NestedIdentifier:
+				star = expr.DotIdent
+			}
+			if star != nil && star.Name == "*" {
+				// the End of * is its Pos
+				pass.ReportRange(item.Pos(), star.Pos()+1, "view selects *; list its columns explicitly")
+			}
+		}
+	}
+}
+
+func checkMissingLimit(pass *Pass) {
+	query, ok := pass.Stmt.(*parser.SelectQuery)
+	if !ok {
+		return
+	}
+	readsTable := false
+	for _, selectQuery := range setOperands(query) {
+		if selectQuery.Limit != nil || selectQuery.Top != nil || selectQuery.IntoOutfile != nil {
+			return
+		}
+		if selectQuery.From != nil && (selectQuery.GroupBy != nil || !aggregatesOnly(selectQuery)) {
+			readsTable = true
+		}
+	}
+	if readsTable {
+		pass.Report(query, "query has no LIMIT")
+	}
+}
+
+func checkJoinWithoutConstraint(pass *Pass) {
+	for _, node := range parser.FindAll(pass.Stmt, isJoin) {
+		join := node.(*parser.JoinExpr)
+		switch join.Kind {
+		case parser.JoinKindInner, parser.JoinKindLeft, parser.JoinKindRight, parser.JoinKindFull:
+		default:
+			continue
+		}
+		if join.Constraints == nil {
+			pass.Report(join.Left, "%s JOIN has no ON or USING; use CROSS JOIN for a cross product", join.Kind)
+			continue
+		}
+		if on, ok := join.Constraints.(*parser.OnClause); ok && len(on.On.Items) == 1 && isAlwaysTrue(on.On.Items[0]) {
+			pass.Report(on.On, "%s JOIN condition is always true; use CROSS JOIN for a cross product", join.Kind)
+		}
+	}
+}
+
+func checkFinalInMaterializedView(pass *Pass) {
+	view, ok := pass.Stmt.(*parser.CreateMaterializedView)
+	if !ok || view.SubQuery == nil {
+		return
+	}
+	for _, node := range parser.FindAll(view.SubQuery, func(node parser.Expr) bool {
+		switch n := node.(type) {
+		case *parser.TableExpr:
+			return n.HasFinal
+		case *parser.JoinTableExpr:
+			return n.HasFinal && !n.Table.HasFinal
+		case *parser.SettingExpr:
+			return strings.EqualFold(n.Name.Name, "final") && isAlwaysTrue(n.Expr)
+		}
+		return false
+	}) {
+		pass.Report(node, "materialized view reads with FINAL, which does not apply to the inserted block")
+	}
+}
+
+// nondeterministicFunctions are the functions whose result differs between
+// runs of the same query on the same data.
+var nondeterministicFunctions = map[string]bool{
+	"now64": true, "nowInBlock": true,
+	"rand": true, "rand32": true, "rand64": true, "randConstant": true, "randCanonical": true,
+	"randUniform": true, "randNormal": true, "randLogNormal": true, "randBinomial": true,
+	"randNegativeBinomial": true, "randPoisson": true, "randBernoulli": true, "randExponential": true,
+	"randChiSquared": true, "randStudentT": true, "randFisherF": true,
+	"randomString": true, "randomFixedString": true, "randomPrintableASCII": true, "randomStringUTF8": true,
+	"fuzzBits": true, "generateUUIDv4": true, "generateUUIDv7": true, "generateULID": true,
+	"generateSnowflakeID": true, "uptime": true, "blockNumber": true, "rowNumberInBlock": true,
+	"rowNumberInAllBlocks": true,
+}
+
+func checkNondeterministicFunctionInMaterializedView(pass *Pass) {
+	view, ok := pass.Stmt.(*parser.CreateMaterializedView)
+	if !ok || view.SubQuery == nil {
+		return
+	}
+	for _, node := range parser.FindAll(view.SubQuery, func(node parser.Expr) bool {
+		function, ok := node.(*parser.FunctionExpr)
+		return ok && isNondeterministic(function.Name.Name)
+	}) {
+		function := node.(*parser.FunctionExpr)
+		pass.Report(function, "%s is non-deterministic; the stored rows will not match a rerun of the view query", function.Name.Name)
+	}
+}
+
+func isNondeterministic(name string) bool {
+	if nondeterministicFunctions[name] {
+		return true
+	}
+	// the date and time functions are case-insensitive
+	switch strings.ToLower(name) {
+	case "now", "today", "yesterday", "current_timestamp", "current_date", "localtimestamp":
+		return true
+	}
+	return false
+}
+
+func checkUpdateWithoutWhere(pass *Pass) {
+	alter, ok := pass.Stmt.(*parser.AlterTable)
+	if !ok {
+		return
+	}
+	for _, clause := range alter.AlterExprs {
+		update, ok := clause.(*parser.AlterTableUpdate)
+		if !ok {
+			continue
+		}
+		if update.WhereClause == nil || isAlwaysTrue(update.WhereClause) {
+			pass.Report(update, "UPDATE has no filtering WHERE and rewrites every row of %s", parser.Format(alter.TableIdentifier))
+		}
+	}
+}
+
+func checkNullableSortingKey(pass *Pass) {
+	table, ok := pass.Stmt.(*parser.CreateTable)
+	if !ok || table.TableSchema == nil || table.Engine == nil {
+		return
+	}
+	nullable := make(map[string]bool)
+	for _, column := range table.TableSchema.Columns {
+		def, ok := column.(*parser.ColumnDef)
+		if !ok || def.Name == nil {
+			continue
+		}
+		if def.Name.DotIdent != nil {
+			continue
+		}
+		if dataType, err := def.DataType(); err == nil && dataType != nil && dataType.IsNullable() {
+			// keyed like the key columns' Ident.Name, without quotes
+			nullable[def.Name.Ident.Name] = true
+		}
+	}
+	if len(nullable) == 0 {
+		return
+	}
+	var keys []parser.Expr
+	if table.Engine.OrderBy != nil {
+		keys = append(keys, table.Engine.OrderBy)
+	}
+	if table.Engine.PrimaryKey != nil {
+		keys = append(keys, table.Engine.PrimaryKey)
+	}
+	// a column in both keys is reported once
+	reported := make(map[string]bool)
+	for _, key := range keys {
+		for _, node := range parser.FindAll(key, func(node parser.Expr) bool {
+			ident, ok := node.(*parser.Ident)
+			return ok && nullable[ident.Name]
+		}) {
+			if name := node.(*parser.Ident).Name; !reported[name] {
+				reported[name] = true
+				pass.Report(node, "sorting key column %s is Nullable", name)
+			}
+		}
+	}
+}
+
+func isJoin(node parser.Expr) bool {
+	_, ok := node.(*parser.JoinExpr)
+	return ok
+}
+
+// setOperands returns the queries combined by UNION, EXCEPT and INTERSECT,
+// looking inside parentheses.
+func setOperands(query *parser.SelectQuery) []*parser.SelectQuery {
+	if query == nil {
+		return nil
+	}
+	var queries []*parser.SelectQuery
+	if query.InnerQuery != nil {
+		queries = setOperands(query.InnerQuery)
+	} else {
+		queries = []*parser.SelectQuery{query}
+	}
+	for _, next := range []*parser.SelectQuery{query.UnionAll, query.UnionDistinct, query.Except, query.Intersect} {
+		queries = append(queries, setOperands(next)...)
+	}
+	return queries
+}
+
+var functionRegistry = parser.NewFunctionRegistry()
+
+// aggregatesOnly reports whether every item a query selects is computed by
+// aggregate functions, so the query returns a single row.
+func aggregatesOnly(query *parser.SelectQuery) bool {
+	for _, item := range query.SelectItems {
+		collector := &aggregateCollector{}
+		_ = item.Expr.Accept(collector)
+		if !collector.found {
+			return false
+		}
+	}
+	return len(query.SelectItems) > 0
+}
+
+// aggregateCollector looks for aggregate function calls outside subqueries.
+type aggregateCollector struct {
+	parser.DefaultASTVisitor
+	found bool
+	// depth counts the enclosing subqueries.
+	depth int
+}
+
+func (c *aggregateCollector) Enter(expr parser.Expr) {
+	switch e := expr.(type) {
+	case *parser.SelectQuery:
+		c.depth++
+	case *parser.FunctionExpr:
+		if info, ok := functionRegistry.Lookup(e.Name.Name); ok && info.Kind == parser.FunctionKindAggregate && c.depth == 0 {
+			c.found = true
+		}
+	}
+}
+
+func (c *aggregateCollector) Leave(expr parser.Expr) {
+	if _, ok := expr.(*parser.SelectQuery); ok {
+		c.depth--
+	}
+}
+
+// isAlwaysTrue reports whether a condition is a constant that holds for
+// every row, such as 1, true or 1 = 1.
+func isAlwaysTrue(expr parser.Expr) bool {
+	switch e := unwrap(expr).(type) {
+	case *parser.NumberLiteral:
+		return isNonZero(e.Value())
+	case *parser.BoolLiteral:
+		return strings.EqualFold(e.Literal, "true")
+	case *parser.Ident:
+		return strings.EqualFold(e.Name, "true")
+	case *parser.BinaryOperation:
+		if e.Operator() != parser.OperatorEquals {
+			return false
+		}
+		left, right := unwrap(e.LeftExpr), unwrap(e.RightExpr)
+		return isLiteral(left) && isLiteral(right) && parser.Format(left) == parser.Format(right)
+	}
+	return false
+}
+
+// isNonZero reports whether a decoded number literal is not zero.
+func isNonZero(value any) bool {
+	switch v := value.(type) {
+	case int64:
+		return v != 0
+	case uint64:
+		return v != 0
+	case *big.Int:
+		return v.Sign() != 0
+	case float64:
+		return v != 0
+	}
+	return false
+}
+
+func isLiteral(expr parser.Expr) bool {
+	switch expr.(type) {
+	case *parser.NumberLiteral, *parser.StringLiteral:
+		return true
+	}
+	return false
+}
+
+// unwrap strips column wrappers and redundant parentheses.
+func unwrap(expr parser.Expr) parser.Expr {
+	for {
+		switch e := expr.(type) {
+		case *parser.ColumnExpr:
+			if e.Alias != nil {
+				return e
+			}
+			expr = e.Expr
+		case *parser.ParamExprList:
+			if e.Items == nil || len(e.Items.Items) != 1 {
+				return e
+			}
+			expr = e.Items.Items[0]
+		default:
+			return expr
+		}
+	}
+}
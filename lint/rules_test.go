@@ -0,0 +1,83 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		rule     Rule
+		sql      string
+		expected []string
+	}{
+		{
+			rule: SelectStarInView,
+			sql: "CREATE VIEW v AS SELECT * FROM t UNION ALL SELECT t.*, a FROM t;" +
+				"CREATE MATERIALIZED VIEW mv TO dst AS SELECT * EXCEPT (b) FROM (SELECT * FROM t);" +
+				"CREATE VIEW ok AS SELECT a FROM (SELECT * FROM t);" +
+				"SELECT * FROM t LIMIT 1",
+			expected: []string{"*", "t.*", "*"},
+		},
+		{
+			rule: MissingLimit,
+			sql: "SELECT a FROM t;" +
+				"SELECT a FROM t LIMIT 10;" +
+				"SELECT count(), max(a) + 1 FROM t;" +
+				"SELECT a, count() FROM t GROUP BY a;" +
+				"SELECT 1;" +
+				"SELECT a FROM t UNION ALL SELECT a FROM u LIMIT 5;" +
+				"SELECT a FROM t INTO OUTFILE 'a.csv';" +
+				"INSERT INTO u SELECT a FROM t",
+			expected: []string{"SELECT a FROM t", "SELECT a, count() FROM t GROUP BY a"},
+		},
+		{
+			rule: JoinWithoutConstraint,
+			sql: "SELECT * FROM a JOIN b LEFT JOIN c ON 1 = 1 INNER JOIN d ON a.x = d.x " +
+				"CROSS JOIN e, f RIGHT JOIN g USING (x) FULL JOIN h ON (true) LEFT JOIN i ON 0x0 ARRAY JOIN arr",
+			expected: []string{"b", "1 = 1", "(true"},
+		},
+		{
+			rule: FinalInMaterializedView,
+			sql: "CREATE MATERIALIZED VIEW mv TO dst AS SELECT * FROM src FINAL JOIN dim FINAL ON src.k = dim.k;" +
+				"CREATE MATERIALIZED VIEW mv2 TO dst AS SELECT * FROM src SETTINGS final = 1;" +
+				"SELECT * FROM src FINAL",
+			expected: []string{"src", "dim", "final = 1"},
+		},
+		{
+			rule: NondeterministicFunctionInMaterializedView,
+			sql: "CREATE MATERIALIZED VIEW mv TO dst AS SELECT NOW() AS ts, rand() % 10, generateUUIDv4(), toDate(ts) FROM src;" +
+				"CREATE VIEW v AS SELECT now()",
+			expected: []string{"NOW(", "rand(", "generateUUIDv4("},
+		},
+		{
+			rule: UpdateWithoutWhere,
+			sql: "ALTER TABLE t UPDATE a = 1 WHERE 1;" +
+				"ALTER TABLE t UPDATE a = 1 WHERE (1 = 1), UPDATE b = 2 WHERE b = 0;" +
+				"ALTER TABLE t DELETE WHERE 1;" +
+				"ALTER TABLE t UPDATE a = 1 WHERE 0e5, UPDATE b = 1 WHERE 0b0, UPDATE c = 1 WHERE 0.0",
+			expected: []string{"UPDATE a = 1 WHERE 1", "UPDATE a = 1 WHERE (1 = 1"},
+		},
+		{
+			rule: NullableSortingKey,
+			sql: "CREATE TABLE t (a Nullable(String), b String NULL, c LowCardinality(Nullable(String)), d String, `e f` Nullable(UInt8)) " +
+				"ENGINE = MergeTree PRIMARY KEY (a, d) ORDER BY (a, toDate(b), c, d, `e f`) SETTINGS allow_nullable_key = 1;" +
+				"CREATE TABLE u (a Nullable(String)) ENGINE = Log",
+			expected: []string{"a", "b", "c", "e f"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule.Name(), func(t *testing.T) {
+			diagnostics, err := New(tt.rule).Lint(tt.sql)
+			require.NoError(t, err)
+			var got []string
+			for _, diagnostic := range diagnostics {
+				require.Equal(t, tt.rule.Name(), diagnostic.Rule)
+				require.Equal(t, tt.rule.Severity(), diagnostic.Severity)
+				got = append(got, tt.sql[diagnostic.Pos:diagnostic.End])
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,503 @@
+package parser
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Anonymizer rewrites statements so they can be shared without revealing the
+// schema or data they refer to: databases, tables, columns and aliases are
+// renamed to db_N, table_N, column_N and alias_N, and string and number
+// literals are scrambled character by character, keeping their shape. String
+// literals that name a database, table or dictionary, as in
+// Distributed('c', 'shop', 'orders'), remote('host', 'shop.orders') or
+// dictGet('shop.d', 'x', id), are renamed like the object they name. Function
+// names, data types, engines and their other string parameters such as
+// ZooKeeper paths and macros, settings, formats and typed literals such as
+// DATE '2024-01-01' are left intact.
+//
+// The renaming is consistent across all the statements an Anonymizer is given,
+// so a schema and the queries against it still fit together, and Mapping tells
+// how to translate anonymized names and values back.
+type Anonymizer struct {
+	rand *rand.Rand
+	// names and values map original names and literal values to their
+	// anonymized form.
+	names  map[string]string
+	values map[string]string
+	// originals maps anonymized names and values back to the original ones.
+	originals map[string]string
+	counts    map[nameRole]int
+}
+
+// NewAnonymizer returns an Anonymizer whose scrambling is driven by seed: the
+// same seed and statements always give the same output.
+func NewAnonymizer(seed int64) *Anonymizer {
+	return &Anonymizer{
+		rand:      rand.New(rand.NewSource(seed)), //nolint:gosec // scrambling, not cryptography
+		names:     make(map[string]string),
+		values:    make(map[string]string),
+		originals: make(map[string]string),
+		counts:    make(map[nameRole]int),
+	}
+}
+
+// Anonymize returns the anonymized SQL of stmt. stmt is not modified.
+func (a *Anonymizer) Anonymize(stmt Expr) (string, error) {
+	if stmt == nil {
+		return "", nil
+	}
+	// reparsing the formatted statement yields a copy that can be rewritten
+	stmts, err := NewParser(Format(stmt)).ParseStmts()
+	if err != nil {
+		return "", fmt.Errorf("failed to copy statement: %w", err)
+	}
+	if len(stmts) != 1 {
+		return "", fmt.Errorf("failed to copy statement: got %d statements", len(stmts))
+	}
+	anonymized := stmts[0]
+	collector := &anonymizeCollector{roles: make(map[*Ident]nameRole), kept: make(map[Expr]bool)}
+	_ = anonymized.Accept(collector)
+
+	// a name gets the most specific role it is used in, so the alias of
+	// `SELECT e.ts FROM events AS e` is named as an alias although it is
+	// referred to before it is defined
+	roles := make(map[string]nameRole)
+	for _, ident := range collector.idents {
+		if role, ok := roles[ident.Name]; !ok || nameRoleRanks[collector.roles[ident]] > nameRoleRanks[role] {
+			roles[ident.Name] = collector.roles[ident]
+		}
+	}
+	for _, name := range collector.nameLiterals {
+		for i, part := range name.parts() {
+			if role, ok := roles[part]; !ok || nameRoleRanks[name.roles[i]] > nameRoleRanks[role] {
+				roles[part] = name.roles[i]
+			}
+		}
+	}
+	for _, ident := range collector.idents {
+		ident.Name = a.name(ident.Name, roles[ident.Name])
+	}
+	for _, name := range collector.nameLiterals {
+		parts := name.parts()
+		for i, part := range parts {
+			parts[i] = a.name(part, roles[part])
+		}
+		name.literal.Literal = escape(strings.Join(parts, "."), '\'')
+	}
+	for _, literal := range collector.literals {
+		switch l := literal.(type) {
+		case *StringLiteral:
+			if value := a.value(l.Value(), scrambleString); l.Kind == StringKindHeredoc {
+				l.Literal = value
+			} else {
+				l.Literal = escape(value, '\'')
+			}
+		case *NumberLiteral:
+			l.Literal = a.value(l.Literal, scrambleNumber)
+		}
+	}
+	return Format(anonymized), nil
+}
+
+// Mapping returns the original of every anonymized name and literal value
+// produced so far, keyed by the anonymized form.
+func (a *Anonymizer) Mapping() map[string]string {
+	mapping := make(map[string]string, len(a.originals))
+	for anonymized, original := range a.originals {
+		mapping[anonymized] = original
+	}
+	return mapping
+}
+
+func (a *Anonymizer) name(original string, role nameRole) string {
+	if name, ok := a.names[original]; ok {
+		return name
+	}
+	a.counts[role]++
+	name := fmt.Sprintf("%s_%d", role, a.counts[role])
+	a.names[original] = name
+	a.originals[name] = original
+	return name
+}
+
+func (a *Anonymizer) value(original string, scramble func(r *rand.Rand, s string, grow int) string) string {
+	if value, ok := a.values[original]; ok {
+		return value
+	}
+	if !strings.ContainsFunc(original, isAlphanumeric) {
+		// nothing to scramble, e.g. '' or ', '
+		return original
+	}
+	var value string
+	for attempt := 0; ; attempt++ {
+		// short values run out of distinct scrambles, so they grow a
+		// character every ten attempts
+		value = scramble(a.rand, original, attempt/10)
+		if _, ok := a.originals[value]; !ok && value != original {
+			break
+		}
+	}
+	a.values[original] = value
+	a.originals[value] = original
+	return value
+}
+
+// scrambleString replaces every letter and digit of s by a random one of the
+// same kind, keeping case, punctuation and whitespace.
+func scrambleString(r *rand.Rand, s string, grow int) string {
+	var builder strings.Builder
+	for _, c := range s {
+		builder.WriteRune(scrambleRune(r, c))
+	}
+	for i := 0; i < grow; i++ {
+		builder.WriteRune(scrambleRune(r, 'a'))
+	}
+	return builder.String()
+}
+
+// scrambleNumber replaces the digits of a decimal number literal, keeping its
+// sign, decimal point and exponent, the number of integer digits and a zero
+// integer part as in 0.5.
+func scrambleNumber(r *rand.Rand, s string, grow int) string {
+	digits := []byte(s)
+	leading := true
+	for i, c := range digits {
+		if !IsDigit(c) {
+			leading = c == '-' || c == '+'
+			continue
+		}
+		next := byte(0)
+		if i+1 < len(digits) {
+			next = digits[i+1]
+		}
+		switch {
+		case leading && IsDigit(next):
+			digits[i] = byte('1' + r.Intn(9))
+		case leading && c == '0' && next == '.':
+		default:
+			digits[i] = byte('0' + r.Intn(10))
+		}
+		leading = false
+	}
+	end := len(digits)
+	if exponent := strings.IndexAny(s, "eE"); exponent >= 0 {
+		end = exponent
+	}
+	return string(digits[:end]) + strings.Repeat("9", grow) + string(digits[end:])
+}
+
+func scrambleRune(r *rand.Rand, c rune) rune {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return rune('a' + r.Intn(26))
+	case c >= 'A' && c <= 'Z':
+		return rune('A' + r.Intn(26))
+	case c >= '0' && c <= '9':
+		return rune('0' + r.Intn(10))
+	}
+	return c
+}
+
+func isAlphanumeric(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// nameRole is the kind of object a renamed identifier names, from the least
+// to the most specific.
+type nameRole string
+
+const (
+	nameRoleColumn   nameRole = "column"
+	nameRoleAlias    nameRole = "alias"
+	nameRoleTable    nameRole = "table"
+	nameRoleDatabase nameRole = "db"
+)
+
+var nameRoleRanks = map[nameRole]int{nameRoleColumn: 0, nameRoleAlias: 1, nameRoleTable: 2, nameRoleDatabase: 3}
+
+// anonymizeCollector gathers the identifiers and literals of a statement that
+// an Anonymizer renames and scrambles.
+type anonymizeCollector struct {
+	DefaultASTVisitor
+	idents   []*Ident
+	roles    map[*Ident]nameRole
+	literals []Expr
+	// nameLiterals are the string literals that name objects.
+	nameLiterals []nameLiteral
+	// kept holds the identifiers and literals that are left as written.
+	kept map[Expr]bool
+	// depth counts the enclosing subtrees that are left out.
+	depth int
+}
+
+func (c *anonymizeCollector) keep(exprs ...Expr) {
+	for _, expr := range exprs {
+		if expr != nil {
+			c.kept[expr] = true
+		}
+	}
+}
+
+func (c *anonymizeCollector) role(expr Expr, role nameRole) {
+	if ident, ok := expr.(*Ident); ok {
+		c.roles[ident] = role
+	}
+}
+
+// name records that expr names an object of the given role. A qualified name
+// such as shop.orders or 'shop.orders' takes the database role for its first
+// part when qualified is set.
+func (c *anonymizeCollector) name(expr Expr, role nameRole, qualified bool) {
+	switch e := unwrapColumnExpr(expr).(type) {
+	case *Ident:
+		c.role(e, role)
+	case *NestedIdentifier:
+		if qualified {
+			c.role(e.Ident, nameRoleDatabase)
+			c.role(e.DotIdent, role)
+		}
+	case *StringLiteral:
+		if e.Kind == StringKindHex || e.Kind == StringKindBinary || c.kept[e] {
+			return
+		}
+		name := nameLiteral{literal: e, roles: []nameRole{role}}
+		if qualified && strings.Count(e.Value(), ".") == 1 {
+			name.roles = []nameRole{nameRoleDatabase, role}
+		}
+		c.keep(e)
+		c.nameLiterals = append(c.nameLiterals, name)
+	}
+}
+
+// engineNames records the database, table and dictionary names among the
+// parameters of an engine with a model, such as Distributed or Buffer.
+func (c *anonymizeCollector) engineNames(e *EngineExpr) {
+	args := e.args()
+	model, err := e.Model()
+	if err != nil {
+		return
+	}
+	switch model.(type) {
+	case *DistributedEngine:
+		// the cluster name is kept, as in ON CLUSTER
+		c.keep(args[0])
+		c.name(args[1], nameRoleDatabase, false)
+		c.name(args[2], nameRoleTable, false)
+	case *BufferEngine:
+		c.name(args[0], nameRoleDatabase, false)
+		c.name(args[1], nameRoleTable, false)
+	case *DictionaryEngine:
+		c.name(args[0], nameRoleTable, true)
+	}
+}
+
+// remoteTableNames records the table a remote or cluster table function reads:
+// either a db.table argument or a database and a table argument following
+// the addresses or cluster name.
+func (c *anonymizeCollector) remoteTableNames(args []Expr) {
+	if len(args) < 2 {
+		return
+	}
+	if len(args) == 2 || isQualifiedName(unwrapColumnExpr(args[1])) {
+		c.name(args[1], nameRoleTable, true)
+		return
+	}
+	c.name(args[1], nameRoleDatabase, false)
+	c.name(args[2], nameRoleTable, false)
+}
+
+func (c *anonymizeCollector) Enter(expr Expr) {
+	if c.depth > 0 {
+		c.depth++
+		return
+	}
+	switch e := expr.(type) {
+	case *ScalarType, *TypeWithParams, *EnumType, *JSONType, *PropertyType, *CompressionCodec,
+		*StatisticsClause, *TypedLiteral, *IntervalLiteral, *SettingExpr, *SettingPair,
+		*AlterTableResetSetting, *FormatClause, *DictionaryLayoutClause, *RoleSetting:
+		c.depth++
+	case *ComplexType:
+		// the element names of Tuple(x Float64) are renamed like columns
+		c.keep(e.Name)
+	case *NestedType:
+		c.keep(e.Name)
+	case *CastExpr:
+		// CAST(x, 'Nullable(String)') names a type
+		c.keep(e.AsType)
+	case *FunctionExpr:
+		c.keep(e.Name)
+		if e.Params == nil || e.Params.Items == nil {
+			break
+		}
+		name, params := strings.ToLower(e.Name.Name), e.Params.Items.Items
+		if castFunctions[name] && len(params) >= 2 {
+			c.keep(unwrapColumnExpr(params[1]))
+		}
+		if strings.HasPrefix(name, "dict") && len(params) >= 1 {
+			// dictGet('shop.d', 'x', id) names a dictionary and its attribute
+			c.name(params[0], nameRoleTable, true)
+			if strings.HasPrefix(name, "dictget") && !dictKeyFunctions[name] && len(params) >= 2 {
+				c.name(params[1], nameRoleColumn, false)
+			}
+		}
+	case *EngineExpr:
+		c.engineNames(e)
+		// the other string parameters name ZooKeeper paths and macros such
+		// as {replica}
+		for _, arg := range e.args() {
+			if literal, ok := arg.(*StringLiteral); ok {
+				c.keep(literal)
+			}
+		}
+	case *TableFunctionExpr:
+		c.keep(e.Name)
+		if name, ok := e.Name.(*Ident); ok && remoteFunctions[strings.ToLower(name.Name)] && e.Args != nil {
+			c.remoteTableNames(e.Args.Args)
+		}
+	case *NamedParameterExpr:
+		c.keep(e.Name)
+	case *IntervalExpr:
+		c.keep(e.Unit)
+	case *IntervalFrom:
+		c.keep(e.Interval)
+	case *IndexOperation:
+		if e.Operation == TokenKindDot {
+			// the index of a tuple element, as in t.1
+			c.keep(e.Index)
+		}
+	case *QueryParam:
+		c.keep(e.Name)
+	case *TypedPlaceholder:
+		c.keep(e.Name)
+	case *ColumnTypeExpr:
+		c.keep(e.Name)
+	case *CreateFunction:
+		c.keep(e.FunctionName)
+	case *CreateMaterializedView:
+		c.keep(e.Definer)
+	case *CreateRole:
+		c.keep(e.AccessStorageType)
+	case *NamedCollectionParam:
+		c.keep(e.Name)
+	case *DictionarySourceClause:
+		c.keep(e.Source)
+		for _, arg := range e.Args {
+			switch strings.ToLower(arg.Name.Name) {
+			case "db":
+				c.name(arg.Value, nameRoleDatabase, false)
+			case "table":
+				c.name(arg.Value, nameRoleTable, false)
+			}
+		}
+	case *DictionaryArgExpr:
+		c.keep(e.Name)
+	case *TableIdentifier:
+		c.role(e.Database, nameRoleDatabase)
+		c.role(e.Table, nameRoleTable)
+	case *CreateDatabase:
+		c.role(e.Name, nameRoleDatabase)
+	case *DropDatabase:
+		c.role(e.Name, nameRoleDatabase)
+	case *UseStmt:
+		c.role(e.Database, nameRoleDatabase)
+	case *CreateUser:
+		c.role(e.DefaultDatabase, nameRoleDatabase)
+	case *SelectItem:
+		c.role(e.Alias, nameRoleAlias)
+	case *ColumnExpr:
+		c.role(e.Alias, nameRoleAlias)
+	case *OrderExpr:
+		c.role(e.Alias, nameRoleAlias)
+	case *AliasExpr:
+		c.role(e.Alias, nameRoleAlias)
+	case *CTEStmt:
+		c.role(e.Alias, nameRoleAlias)
+	case *WindowClause:
+		for _, window := range e.Windows {
+			c.role(window.Name, nameRoleAlias)
+		}
+	case *Ident:
+		if c.kept[e] || e.Name == "*" || isKeywordIdent(e.Name) {
+			return
+		}
+		if _, ok := c.roles[e]; !ok {
+			c.roles[e] = nameRoleColumn
+		}
+		c.idents = append(c.idents, e)
+	case *StringLiteral:
+		if !c.kept[e] && e.Kind != StringKindHex && e.Kind != StringKindBinary {
+			c.literals = append(c.literals, e)
+		}
+	case *NumberLiteral:
+		if !c.kept[e] && e.Base == 10 {
+			c.literals = append(c.literals, e)
+		}
+	}
+}
+
+func (c *anonymizeCollector) Leave(expr Expr) {
+	if c.depth > 0 {
+		c.depth--
+	}
+}
+
+// castFunctions are the functions whose second argument names a data type.
+var castFunctions = map[string]bool{
+	"cast":                  true,
+	"_cast":                 true,
+	"accuratecast":          true,
+	"accuratecastornull":    true,
+	"accuratecastordefault": true,
+}
+
+// remoteFunctions are the table functions that read a table of other servers.
+var remoteFunctions = map[string]bool{
+	"remote":             true,
+	"remotesecure":       true,
+	"cluster":            true,
+	"clusterallreplicas": true,
+}
+
+// dictKeyFunctions are the dictGet functions that take keys rather than an
+// attribute after the dictionary name.
+var dictKeyFunctions = map[string]bool{
+	"dictgethierarchy":   true,
+	"dictgetchildren":    true,
+	"dictgetdescendants": true,
+}
+
+// nameLiteral is a string literal that names an object, with the role of
+// each dot-separated part of its value.
+type nameLiteral struct {
+	literal *StringLiteral
+	roles   []nameRole
+}
+
+func (n nameLiteral) parts() []string {
+	if len(n.roles) == 1 {
+		return []string{n.literal.Value()}
+	}
+	return strings.SplitN(n.literal.Value(), ".", len(n.roles))
+}
+
+func isQualifiedName(expr Expr) bool {
+	switch e := expr.(type) {
+	case *NestedIdentifier:
+		return true
+	case *StringLiteral:
+		return strings.Contains(e.Value(), ".")
+	}
+	return false
+}
+
+// isKeywordIdent reports whether an identifier is a constant that ClickHouse
+// reads as a keyword, such as true or inf.
+func isKeywordIdent(name string) bool {
+	switch strings.ToLower(name) {
+	case "true", "false", "null", "inf", "nan":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymize(t *testing.T) {
+	anonymizer := NewAnonymizer(1)
+	anonymize := func(sql string) string {
+		anonymized, err := anonymizer.Anonymize(parseOneStmt(t, sql))
+		require.NoError(t, err)
+		_, err = NewParser(anonymized).ParseStmts()
+		require.NoError(t, err, anonymized)
+		return anonymized
+	}
+
+	ddl := anonymize("CREATE TABLE shop.orders (id UInt64, ts DateTime('UTC'), n Nested(key String, v UInt8)) " +
+		"ENGINE = ReplicatedMergeTree('/clickhouse/{shard}', '{replica}') ORDER BY (id, ts) SETTINGS index_granularity = 8192")
+	require.Equal(t, "CREATE TABLE db_1.table_1 (column_1 UInt64, column_2 DateTime('UTC'), column_3 Nested(column_4 String, column_5 UInt8)) "+
+		"ENGINE = ReplicatedMergeTree('/clickhouse/{shard}', '{replica}') ORDER BY (column_1, column_2) SETTINGS index_granularity=8192", ddl)
+
+	query := anonymize("SELECT o.id AS order_id, count() AS total, n.key, CAST(ts, 'Date'), ts + INTERVAL 1 DAY, DATE '2024-01-01', t.1 " +
+		"FROM shop.orders AS o WHERE customer = 'O\\'Brien' AND id IN (7, 42, 0.5) GROUP BY order_id LIMIT 10 FORMAT JSON")
+	pattern := regexp.MustCompile(`^SELECT alias_1\.column_1 AS alias_2, count\(\) AS alias_3, column_3\.column_4, CAST\(column_2, 'Date'\), ` +
+		`column_2 \+ INTERVAL (\d) DAY, DATE '2024-01-01', column_6\.1 FROM db_1\.table_1 AS alias_1 ` +
+		`WHERE column_7 = '(\w)\\'(\w{5})' AND column_1 IN \((\d), ([1-9]\d), (0\.\d)\) GROUP BY alias_2 LIMIT ([1-9]\d) FORMAT JSON$`)
+	require.Regexp(t, pattern, query)
+
+	mapping := anonymizer.Mapping()
+	for anonymized, original := range map[string]string{
+		"db_1": "shop", "table_1": "orders", "column_1": "id", "column_4": "key", "alias_1": "o", "alias_2": "order_id",
+	} {
+		require.Equal(t, original, mapping[anonymized])
+	}
+	match := pattern.FindStringSubmatch(query)
+	require.Equal(t, "O'Brien", mapping[match[2]+"'"+match[3]])
+	require.Equal(t, "42", mapping[match[5]])
+	require.Equal(t, "0.5", mapping[match[6]])
+	require.Equal(t, "10", mapping[match[7]])
+	for _, original := range []string{"shop", "orders", "order_id", "customer", "Brien"} {
+		require.NotContains(t, query, original)
+	}
+
+	// names and values keep their anonymized form across statements
+	require.Equal(t, "SELECT column_7 FROM db_1.table_1 WHERE column_7 = '"+match[2]+"\\'"+match[3]+"'",
+		anonymize("SELECT customer FROM shop.orders WHERE customer = 'O\\'Brien'"))
+}
+
+func TestAnonymizeObjectNames(t *testing.T) {
+	anonymizer := NewAnonymizer(1)
+	anonymize := func(sql string) string {
+		anonymized, err := anonymizer.Anonymize(parseOneStmt(t, sql))
+		require.NoError(t, err)
+		return anonymized
+	}
+
+	require.Equal(t, "CREATE TABLE db_1.table_1 (column_1 UInt64) ENGINE = Distributed('prod', 'db_1', 'table_2', rand())",
+		anonymize("CREATE TABLE shop.orders (id UInt64) ENGINE = Distributed('prod', 'shop', 'orders_local', rand())"))
+	require.Regexp(t, `^CREATE TABLE db_1\.table_3 \(column_1 UInt64\) ENGINE = Buffer\(db_1, table_1, [\d, ]+\)$`,
+		anonymize("CREATE TABLE shop.orders_buffer (id UInt64) ENGINE = Buffer(shop, orders, 16, 10, 100, 10000, 1000000, 10000000, 100000000)"))
+	require.Equal(t, "CREATE DICTIONARY db_1.table_4 (column_1 UInt64, column_2 String) PRIMARY KEY column_1 "+
+		"SOURCE(CLICKHOUSE(DB 'db_1' TABLE 'table_2')) LAYOUT(FLAT())",
+		anonymize("CREATE DICTIONARY shop.d (id UInt64, name String) PRIMARY KEY id "+
+			"SOURCE(CLICKHOUSE(DB 'shop' TABLE 'orders_local')) LAYOUT(FLAT())"))
+	require.Regexp(t, `^SELECT dictGet\('db_1\.table_4', 'column_2', column_1\), dictHas\('table_4', column_1\) FROM remote\('[\d.]+', db_1\.table_2\)$`,
+		anonymize("SELECT dictGet('shop.d', 'name', id), dictHas('d', id) FROM remote('127.0.0.1', shop.orders_local)"))
+	require.Regexp(t, `^SELECT \* FROM cluster\('\w+', 'db_1', 'table_1'\) UNION ALL SELECT \* FROM remoteSecure\('[\d.]+', 'db_1\.table_3'\)$`,
+		anonymize("SELECT * FROM cluster('prod', 'shop', 'orders') UNION ALL SELECT * FROM remoteSecure('127.0.0.1', 'shop.orders_buffer')"))
+	require.Equal(t, "CREATE TABLE db_1.table_1 (column_1 UInt64) ENGINE = ReplicatedMergeTree('/clickhouse/tables/{shard}/shop.orders', '{replica}') ORDER BY column_1",
+		anonymize("CREATE TABLE shop.orders (id UInt64) ENGINE = ReplicatedMergeTree('/clickhouse/tables/{shard}/shop.orders', '{replica}') ORDER BY id"))
+}
+
+func TestAnonymizeTestdata(t *testing.T) {
+	for _, dir := range []string{"./testdata/dml", "./testdata/ddl", "./testdata/query", "./testdata/basic"} {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		for _, entry := range entries {
+			if !strings.HasSuffix(entry.Name(), ".sql") {
+				continue
+			}
+			t.Run(entry.Name(), func(t *testing.T) {
+				fileBytes, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+				require.NoError(t, err)
+				stmts, err := NewParser(string(fileBytes)).ParseStmts()
+				require.NoError(t, err)
+				anonymizer := NewAnonymizer(1)
+				for _, stmt := range stmts {
+					anonymized, err := anonymizer.Anonymize(stmt)
+					if err != nil {
+						// the Format output of a few statements does not parse back
+						continue
+					}
+					_, err = NewParser(anonymized).ParseStmts()
+					require.NoError(t, err, anonymized)
+				}
+			})
+		}
+	}
+}
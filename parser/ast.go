@@ -1,5 +1,10 @@
 package parser
 
+import (
+	"strings"
+	"time"
+)
+
 type OrderDirection string
 
 const (
@@ -8,6 +13,43 @@ const (
 	OrderDirectionDesc OrderDirection = "DESC"
 )
 
+// JoinLocality says how the right-hand table of a join is distributed.
+type JoinLocality string
+
+const (
+	JoinLocalityNone   JoinLocality = ""
+	JoinLocalityGlobal JoinLocality = "GLOBAL"
+	JoinLocalityLocal  JoinLocality = "LOCAL"
+)
+
+// JoinStrictness says how many right-hand rows a left-hand row may match.
+type JoinStrictness string
+
+const (
+	JoinStrictnessNone JoinStrictness = ""
+	JoinStrictnessAll  JoinStrictness = "ALL"
+	JoinStrictnessAny  JoinStrictness = "ANY"
+	JoinStrictnessSemi JoinStrictness = "SEMI"
+	JoinStrictnessAnti JoinStrictness = "ANTI"
+	JoinStrictnessAsof JoinStrictness = "ASOF"
+)
+
+// JoinKind is the kind of join. JoinKindNone marks a JoinExpr that has no join
+// operator: the head of a FROM clause or a comma-separated table.
+type JoinKind string
+
+const (
+	JoinKindNone      JoinKind = ""
+	JoinKindInner     JoinKind = "INNER"
+	JoinKindLeft      JoinKind = "LEFT"
+	JoinKindRight     JoinKind = "RIGHT"
+	JoinKindFull      JoinKind = "FULL"
+	JoinKindCross     JoinKind = "CROSS"
+	JoinKindPaste     JoinKind = "PASTE"
+	JoinKindArray     JoinKind = "ARRAY"
+	JoinKindLeftArray JoinKind = "LEFT ARRAY"
+)
+
 type Expr interface {
 	Pos() Pos
 	End() Pos
@@ -136,6 +178,108 @@ func (p *BinaryOperation) Accept(visitor ASTVisitor) error {
 	return visitor.VisitBinaryExpr(p)
 }
 
+// LambdaExpr is a lambda function passed to a higher-order function, such as
+// `x -> x * 2` in arrayMap or `(k, v) -> v > 0` in arrayFilter. Its parameters
+// are local names, not column references; see LambdaBindings.
+type LambdaExpr struct {
+	ParamsPos Pos // position of the single parameter or of the opening '('
+	Params    []*Ident
+	Body      Expr
+}
+
+func (l *LambdaExpr) Pos() Pos {
+	return l.ParamsPos
+}
+
+func (l *LambdaExpr) End() Pos {
+	return l.Body.End()
+}
+
+func (l *LambdaExpr) Accept(visitor ASTVisitor) error {
+	visitor.Enter(l)
+	defer visitor.Leave(l)
+	for _, param := range l.Params {
+		if err := param.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	if err := l.Body.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitLambdaExpr(l)
+}
+
+// SubqueryQuantifier is the ANY or ALL of a quantified comparison. SOME is
+// parsed as ANY.
+type SubqueryQuantifier string
+
+const (
+	SubqueryQuantifierAny SubqueryQuantifier = "ANY"
+	SubqueryQuantifierAll SubqueryQuantifier = "ALL"
+)
+
+// QuantifiedComparisonExpr compares a value with every row of a subquery:
+// `x > ANY (SELECT ...)` holds if the comparison holds for some row, and
+// `x = ALL (SELECT ...)` if it holds for all of them.
+type QuantifiedComparisonExpr struct {
+	LeftExpr      Expr
+	Operation     TokenKind
+	Quantifier    SubqueryQuantifier
+	QuantifierPos Pos
+	RightParenPos Pos
+	SubQuery      *SubQuery
+}
+
+func (q *QuantifiedComparisonExpr) Pos() Pos {
+	return q.LeftExpr.Pos()
+}
+
+func (q *QuantifiedComparisonExpr) End() Pos {
+	return q.RightParenPos + 1
+}
+
+func (q *QuantifiedComparisonExpr) Accept(visitor ASTVisitor) error {
+	visitor.Enter(q)
+	defer visitor.Leave(q)
+	if err := q.LeftExpr.Accept(visitor); err != nil {
+		return err
+	}
+	if err := q.SubQuery.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitQuantifiedComparisonExpr(q)
+}
+
+// DistinctFromExpr is a null-safe comparison, in which NULL equals NULL:
+// `a IS [NOT] DISTINCT FROM b`. Shorthand marks the `a <=> b` spelling of
+// IS NOT DISTINCT FROM.
+type DistinctFromExpr struct {
+	LeftExpr  Expr
+	RightExpr Expr
+	HasNot    bool
+	Shorthand bool `json:",omitempty"`
+}
+
+func (d *DistinctFromExpr) Pos() Pos {
+	return d.LeftExpr.Pos()
+}
+
+func (d *DistinctFromExpr) End() Pos {
+	return d.RightExpr.End()
+}
+
+func (d *DistinctFromExpr) Accept(visitor ASTVisitor) error {
+	visitor.Enter(d)
+	defer visitor.Leave(d)
+	if err := d.LeftExpr.Accept(visitor); err != nil {
+		return err
+	}
+	if err := d.RightExpr.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitDistinctFromExpr(d)
+}
+
 type IndexOperation struct {
 	Object    Expr
 	Operation TokenKind
@@ -1332,15 +1476,23 @@ func (c *CreateDatabase) Accept(visitor ASTVisitor) error {
 }
 
 type CreateTable struct {
-	CreatePos     Pos // position of CREATE|ATTACH keyword
-	StatementEnd  Pos
-	OrReplace     bool
-	Name          *TableIdentifier
-	IfNotExists   bool
-	UUID          *UUID
-	OnCluster     *ClusterClause
+	CreatePos    Pos // position of CREATE|ATTACH|REPLACE keyword
+	StatementEnd Pos
+	IsAttach     bool // ATTACH TABLE instead of CREATE TABLE
+	IsReplace    bool // standalone REPLACE TABLE, unlike CREATE OR REPLACE
+	OrReplace    bool
+	Name         *TableIdentifier
+	IfNotExists  bool
+	FromPath     *StringLiteral // ATTACH TABLE ... FROM 'path'
+	UUID         *UUID
+	OnCluster    *ClusterClause
+	// SourceTable is the table named by AS [db.]table, whose structure the
+	// new table copies, or by CLONE AS, which also copies its data.
+	SourceTable   *TableIdentifier
+	HasClone      bool
 	TableSchema   *TableSchemaClause
 	Engine        *EngineExpr
+	HasEmpty      bool // EMPTY AS SELECT: create the table without filling it
 	SubQuery      *SubQuery
 	TableFunction *TableFunctionExpr
 	HasTemporary  bool
@@ -1365,6 +1517,11 @@ func (c *CreateTable) Accept(visitor ASTVisitor) error {
 	if err := c.Name.Accept(visitor); err != nil {
 		return err
 	}
+	if c.FromPath != nil {
+		if err := c.FromPath.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	if c.UUID != nil {
 		if err := c.UUID.Accept(visitor); err != nil {
 			return err
@@ -1375,6 +1532,11 @@ func (c *CreateTable) Accept(visitor ASTVisitor) error {
 			return err
 		}
 	}
+	if c.SourceTable != nil {
+		if err := c.SourceTable.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	if c.TableSchema != nil {
 		if err := c.TableSchema.Accept(visitor); err != nil {
 			return err
@@ -3140,9 +3302,20 @@ type ColumnDef struct {
 	DefaultExpr      Expr
 	MaterializedExpr Expr
 	AliasExpr        Expr
-
-	Codec *CompressionCodec
-	TTL   *TTLClause
+	// Ephemeral marks an EPHEMERAL column, which is not stored and only
+	// feeds the defaults of other columns. EphemeralExpr is its optional
+	// default value.
+	Ephemeral     bool
+	EphemeralExpr Expr
+
+	Codec      *CompressionCodec
+	Statistics *StatisticsClause
+	TTL        *TTLClause
+
+	// PrimaryKey marks a column declared with an inline PRIMARY KEY.
+	PrimaryKey bool
+	// Settings holds the per-column SETTINGS (...) list.
+	Settings *SettingsClause
 
 	Comment          *StringLiteral
 	CompressionCodec *Ident
@@ -3192,16 +3365,31 @@ func (c *ColumnDef) Accept(visitor ASTVisitor) error {
 			return err
 		}
 	}
+	if c.EphemeralExpr != nil {
+		if err := c.EphemeralExpr.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	if c.Codec != nil {
 		if err := c.Codec.Accept(visitor); err != nil {
 			return err
 		}
 	}
+	if c.Statistics != nil {
+		if err := c.Statistics.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	if c.TTL != nil {
 		if err := c.TTL.Accept(visitor); err != nil {
 			return err
 		}
 	}
+	if c.Settings != nil {
+		if err := c.Settings.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	if c.Comment != nil {
 		if err := c.Comment.Accept(visitor); err != nil {
 			return err
@@ -3546,6 +3734,32 @@ func (c *CompressionCodec) Accept(visitor ASTVisitor) error {
 	return visitor.VisitCompressionCodec(c)
 }
 
+// StatisticsClause is the column-level STATISTICS(type, ...) declaration.
+type StatisticsClause struct {
+	StatisticsPos Pos
+	RightParenPos Pos
+	Types         []*Ident
+}
+
+func (s *StatisticsClause) Pos() Pos {
+	return s.StatisticsPos
+}
+
+func (s *StatisticsClause) End() Pos {
+	return s.RightParenPos
+}
+
+func (s *StatisticsClause) Accept(visitor ASTVisitor) error {
+	visitor.Enter(s)
+	defer visitor.Leave(s)
+	for _, t := range s.Types {
+		if err := t.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitStatisticsClause(s)
+}
+
 type Literal interface {
 	Expr
 }
@@ -3557,6 +3771,13 @@ type NumberLiteral struct {
 	Base    int
 }
 
+// Value returns the number the literal denotes: an int64, a uint64 for
+// integers above math.MaxInt64, a *big.Int for integers that fit neither, or
+// a float64 for floats, inf and nan. It returns nil if Literal is not a number.
+func (n *NumberLiteral) Value() any {
+	return parseNumberValue(n.Literal)
+}
+
 func (n *NumberLiteral) Pos() Pos {
 	return n.NumPos
 }
@@ -3571,10 +3792,20 @@ func (n *NumberLiteral) Accept(visitor ASTVisitor) error {
 	return visitor.VisitNumberLiteral(n)
 }
 
+// StringLiteral is a string constant. Literal holds the source text between
+// the delimiters with escapes left intact; Value returns the decoded string.
 type StringLiteral struct {
 	LiteralPos Pos
 	LiteralEnd Pos
 	Literal    string
+	Kind       StringKind `json:",omitempty"`
+	HeredocTag string     `json:",omitempty"`
+}
+
+// Value returns the string the literal denotes, with escape sequences decoded
+// and hex or binary digits converted to bytes.
+func (s *StringLiteral) Value() string {
+	return decodeString(s.Kind, s.Literal)
 }
 
 func (s *StringLiteral) Pos() Pos {
@@ -3591,6 +3822,40 @@ func (s *StringLiteral) Accept(visitor ASTVisitor) error {
 	return visitor.VisitStringLiteral(s)
 }
 
+// TypedLiteral is a string constant prefixed with its type: DATE '2024-01-01'
+// or TIMESTAMP '2024-01-01 00:00:00'.
+type TypedLiteral struct {
+	TypePos Pos
+	Type    *Ident
+	Value   *StringLiteral
+}
+
+// Time returns the date or timestamp the literal denotes. The value carries
+// no zone, so it is interpreted as UTC; ClickHouse uses the server time zone.
+func (t *TypedLiteral) Time() (time.Time, error) {
+	return parseTypedLiteralTime(strings.ToUpper(t.Type.Name), t.Value.Value())
+}
+
+func (t *TypedLiteral) Pos() Pos {
+	return t.TypePos
+}
+
+func (t *TypedLiteral) End() Pos {
+	return t.Value.End()
+}
+
+func (t *TypedLiteral) Accept(visitor ASTVisitor) error {
+	visitor.Enter(t)
+	defer visitor.Leave(t)
+	if err := t.Type.Accept(visitor); err != nil {
+		return err
+	}
+	if err := t.Value.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitTypedLiteral(t)
+}
+
 type BoolLiteral struct {
 	LiteralPos Pos
 	LiteralEnd Pos
@@ -3751,6 +4016,52 @@ func (i *IntervalExpr) Accept(visitor ASTVisitor) error {
 	return visitor.VisitIntervalExpr(i)
 }
 
+// IntervalLiteral is an interval spelled as a single string, such as
+// INTERVAL '1 day' or INTERVAL '2 hours 30 minutes'. Parts holds the
+// amount and unit of each component in source order.
+type IntervalLiteral struct {
+	IntervalPos Pos
+	Value       *StringLiteral
+	Parts       []IntervalPart
+}
+
+// IntervalPart is one `<amount> <unit>` component of an IntervalLiteral. Unit
+// is the singular upper-case unit name, e.g. HOUR for "hours".
+type IntervalPart struct {
+	Amount int64
+	Unit   string
+}
+
+// Duration returns the total length of the interval. Calendar units (MONTH,
+// QUARTER and YEAR) have no fixed length, so ok is false if any part uses one.
+func (i *IntervalLiteral) Duration() (d time.Duration, ok bool) {
+	for _, part := range i.Parts {
+		unit, fixed := intervalUnitDurations[part.Unit]
+		if !fixed {
+			return 0, false
+		}
+		d += time.Duration(part.Amount) * unit
+	}
+	return d, true
+}
+
+func (i *IntervalLiteral) Pos() Pos {
+	return i.IntervalPos
+}
+
+func (i *IntervalLiteral) End() Pos {
+	return i.Value.End()
+}
+
+func (i *IntervalLiteral) Accept(visitor ASTVisitor) error {
+	visitor.Enter(i)
+	defer visitor.Leave(i)
+	if err := i.Value.Accept(visitor); err != nil {
+		return err
+	}
+	return visitor.VisitIntervalLiteral(i)
+}
+
 // TODO(@git-hulk): split into EngineClause and EngineExpr
 type EngineExpr struct {
 	EnginePos   Pos
@@ -4106,6 +4417,7 @@ func (c *CreateLiveView) Accept(visitor ASTVisitor) error {
 type CreateDictionary struct {
 	CreatePos    Pos
 	StatementEnd Pos
+	IsAttach     bool // ATTACH DICTIONARY; Schema and Engine are nil in the short form
 	OrReplace    bool
 	Name         *TableIdentifier
 	IfNotExists  bool
@@ -4670,14 +4982,29 @@ func (u *UsingClause) Accept(visitor ASTVisitor) error {
 	return visitor.VisitUsingExpr(u)
 }
 
+// JoinExpr is one link of the table chain in a FROM clause. Left is the table
+// (or, for ARRAY JOIN, the column list) joined by this link's operator, and
+// Right is the rest of the chain.
 type JoinExpr struct {
-	JoinPos     Pos
-	Left        Expr
-	Right       Expr
-	Modifiers   []string
+	JoinPos    Pos
+	Left       Expr
+	Right      Expr
+	Locality   JoinLocality
+	Strictness JoinStrictness
+	Kind       JoinKind
+	// ImplicitKind is set when Kind is INNER only because no kind was
+	// written, as in a bare JOIN or ANY JOIN.
+	ImplicitKind bool
+	// HasOuter is set when OUTER follows a LEFT, RIGHT or FULL kind.
+	HasOuter    bool
 	Constraints Expr
 }
 
+// IsArrayJoin reports whether the join is an ARRAY JOIN or LEFT ARRAY JOIN.
+func (j *JoinExpr) IsArrayJoin() bool {
+	return j.Kind == JoinKindArray || j.Kind == JoinKindLeftArray
+}
+
 func (j *JoinExpr) Pos() Pos {
 	return j.JoinPos
 }
@@ -4934,10 +5261,18 @@ func (h *HavingClause) Accept(visitor ASTVisitor) error {
 	return visitor.VisitHavingExpr(h)
 }
 
+// LimitClause is LIMIT n [OFFSET m] [WITH TIES], LIMIT m, n [WITH TIES] or
+// the standard OFFSET m ROWS FETCH FIRST n ROWS ONLY|WITH TIES, which sets
+// Fetch. Either of Limit and Offset may be nil, but not both.
 type LimitClause struct {
 	LimitPos Pos
 	Limit    Expr
 	Offset   Expr
+	Fetch    bool `json:",omitempty"`
+	WithTies bool `json:",omitempty"`
+	// KeywordEnd is the end of a trailing ROWS, ROWS ONLY or WITH TIES,
+	// when the clause does not end with an expression.
+	KeywordEnd Pos `json:",omitempty"`
 }
 
 func (l *LimitClause) Pos() Pos {
@@ -4945,10 +5280,13 @@ func (l *LimitClause) Pos() Pos {
 }
 
 func (l *LimitClause) End() Pos {
-	if l.Offset != nil {
-		return l.Offset.End()
+	if l.KeywordEnd != 0 {
+		return l.KeywordEnd
 	}
-	return l.Limit.End()
+	if l.Limit != nil && (l.Offset == nil || l.Fetch) {
+		return l.Limit.End()
+	}
+	return l.Offset.End()
 }
 
 func (l *LimitClause) Accept(visitor ASTVisitor) error {
@@ -5301,6 +5639,7 @@ type SelectQuery struct {
 	LimitBy       *LimitByClause
 	Limit         *LimitClause
 	Settings      *SettingsClause
+	IntoOutfile   *IntoOutfileClause `json:",omitempty"`
 	Format        *FormatClause
 	UnionAll      *SelectQuery
 	UnionDistinct *SelectQuery
@@ -5396,6 +5735,11 @@ func (s *SelectQuery) Accept(visitor ASTVisitor) error {
 			return err
 		}
 	}
+	if s.IntoOutfile != nil {
+		if err := s.IntoOutfile.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	if s.Format != nil {
 		if err := s.Format.Accept(visitor); err != nil {
 			return err
@@ -5593,11 +5937,13 @@ func (e *ExtractExpr) Accept(visitor ASTVisitor) error {
 }
 
 type DropDatabase struct {
-	DropPos      Pos
+	DropPos      Pos // position of DROP|DETACH keyword
 	StatementEnd Pos
+	IsDetach     bool
 	Name         *Ident
 	IfExists     bool
 	OnCluster    *ClusterClause
+	Permanently  bool // DETACH ... PERMANENTLY
 	Modifier     string
 }
 
@@ -5628,14 +5974,16 @@ func (d *DropDatabase) Accept(visitor ASTVisitor) error {
 }
 
 type DropStmt struct {
-	DropPos      Pos
+	DropPos      Pos // position of DROP|DETACH keyword
 	StatementEnd Pos
 
+	IsDetach    bool
 	DropTarget  string
 	Name        *TableIdentifier
 	IfExists    bool
 	OnCluster   *ClusterClause
 	IsTemporary bool
+	Permanently bool // DETACH ... PERMANENTLY
 	Modifier    string
 }
 
@@ -5775,6 +6123,54 @@ func (s *SetStmt) Accept(visitor ASTVisitor) error {
 	return visitor.VisitSetExpr(s)
 }
 
+// OutfileMode says what INTO OUTFILE does when the file already exists.
+type OutfileMode string
+
+const (
+	OutfileModeNone     OutfileMode = ""
+	OutfileModeAppend   OutfileMode = "APPEND"
+	OutfileModeTruncate OutfileMode = "TRUNCATE"
+)
+
+// IntoOutfileClause is INTO OUTFILE 'file' [AND STDOUT] [APPEND | TRUNCATE]
+// [COMPRESSION 'method' [LEVEL n]].
+type IntoOutfileClause struct {
+	IntoPos          Pos
+	ClauseEnd        Pos
+	FileName         *StringLiteral
+	AndStdout        bool
+	Mode             OutfileMode
+	Compression      *StringLiteral
+	CompressionLevel *NumberLiteral
+}
+
+func (i *IntoOutfileClause) Pos() Pos {
+	return i.IntoPos
+}
+
+func (i *IntoOutfileClause) End() Pos {
+	return i.ClauseEnd
+}
+
+func (i *IntoOutfileClause) Accept(visitor ASTVisitor) error {
+	visitor.Enter(i)
+	defer visitor.Leave(i)
+	if err := i.FileName.Accept(visitor); err != nil {
+		return err
+	}
+	if i.Compression != nil {
+		if err := i.Compression.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	if i.CompressionLevel != nil {
+		if err := i.CompressionLevel.Accept(visitor); err != nil {
+			return err
+		}
+	}
+	return visitor.VisitIntoOutfileClause(i)
+}
+
 type FormatClause struct {
 	FormatPos Pos
 	Format    *Ident
@@ -6350,9 +6746,13 @@ func (t *TargetPair) Accept(visitor ASTVisitor) error {
 	return visitor.VisitTargetPairExpr(t)
 }
 
+// ExplainStmt is EXPLAIN [AST | SYNTAX | QUERY TREE | PLAN | PIPELINE |
+// ESTIMATE] [setting = value, ...] followed by any statement. Type is empty
+// when omitted, which ClickHouse treats as PLAN.
 type ExplainStmt struct {
 	ExplainPos Pos
 	Type       string
+	Settings   []*SettingExpr `json:",omitempty"`
 	Statement  Expr
 }
 
@@ -6367,6 +6767,11 @@ func (e *ExplainStmt) End() Pos {
 func (e *ExplainStmt) Accept(visitor ASTVisitor) error {
 	visitor.Enter(e)
 	defer visitor.Leave(e)
+	for _, setting := range e.Settings {
+		if err := setting.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	if err := e.Statement.Accept(visitor); err != nil {
 		return err
 	}
@@ -6519,7 +6924,10 @@ type DescribeStmt struct {
 	DescribePos  Pos
 	StatementEnd Pos
 	DescribeType string // e.g., "TABLE", empty if not used
-	Target       *TableIdentifier
+	// Target is a *TableIdentifier, a *TableFunctionExpr such as
+	// s3('path'), or a parenthesized *SubQuery.
+	Target   Expr
+	Settings *SettingsClause `json:",omitempty"`
 }
 
 func (d *DescribeStmt) Pos() Pos {
@@ -6527,7 +6935,7 @@ func (d *DescribeStmt) Pos() Pos {
 }
 
 func (d *DescribeStmt) End() Pos {
-	return d.Target.End()
+	return d.StatementEnd
 }
 
 func (d *DescribeStmt) Accept(visitor ASTVisitor) error {
@@ -6536,5 +6944,10 @@ func (d *DescribeStmt) Accept(visitor ASTVisitor) error {
 	if err := d.Target.Accept(visitor); err != nil {
 		return err
 	}
+	if d.Settings != nil {
+		if err := d.Settings.Accept(visitor); err != nil {
+			return err
+		}
+	}
 	return visitor.VisitDescribeExpr(d)
 }
@@ -4,6 +4,9 @@ type ASTVisitor interface {
 	VisitOperationExpr(expr *OperationExpr) error
 	VisitTernaryExpr(expr *TernaryOperation) error
 	VisitBinaryExpr(expr *BinaryOperation) error
+	VisitLambdaExpr(expr *LambdaExpr) error
+	VisitQuantifiedComparisonExpr(expr *QuantifiedComparisonExpr) error
+	VisitDistinctFromExpr(expr *DistinctFromExpr) error
 	VisitIndexOperation(expr *IndexOperation) error
 	VisitAlterTable(expr *AlterTable) error
 	VisitAlterTableAttachPartition(expr *AlterTableAttachPartition) error
@@ -102,12 +105,15 @@ type ASTVisitor interface {
 	VisitComplexType(expr *ComplexType) error
 	VisitNestedType(expr *NestedType) error
 	VisitCompressionCodec(expr *CompressionCodec) error
+	VisitStatisticsClause(expr *StatisticsClause) error
 	VisitNumberLiteral(expr *NumberLiteral) error
 	VisitStringLiteral(expr *StringLiteral) error
+	VisitTypedLiteral(expr *TypedLiteral) error
 	VisitRatioExpr(expr *RatioExpr) error
 	VisitEnumValue(expr *EnumValue) error
 	VisitEnumType(expr *EnumType) error
 	VisitIntervalExpr(expr *IntervalExpr) error
+	VisitIntervalLiteral(expr *IntervalLiteral) error
 	VisitEngineExpr(expr *EngineExpr) error
 	VisitColumnTypeExpr(expr *ColumnTypeExpr) error
 	VisitColumnArgList(expr *ColumnArgList) error
@@ -170,6 +176,7 @@ type ASTVisitor interface {
 	VisitCTEExpr(expr *CTEStmt) error
 	VisitSetExpr(expr *SetStmt) error
 	VisitFormatExpr(expr *FormatClause) error
+	VisitIntoOutfileClause(expr *IntoOutfileClause) error
 	VisitOptimizeExpr(expr *OptimizeStmt) error
 	VisitDeduplicateExpr(expr *DeduplicateClause) error
 	VisitSystemExpr(expr *SystemStmt) error
@@ -229,6 +236,27 @@ func (v *DefaultASTVisitor) VisitBinaryExpr(expr *BinaryOperation) error {
 	return nil
 }
 
+func (v *DefaultASTVisitor) VisitLambdaExpr(expr *LambdaExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitQuantifiedComparisonExpr(expr *QuantifiedComparisonExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
+func (v *DefaultASTVisitor) VisitDistinctFromExpr(expr *DistinctFromExpr) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
 func (v *DefaultASTVisitor) VisitIndexOperation(expr *IndexOperation) error {
 	if v.Visit != nil {
 		return v.Visit(expr)
@@ -922,6 +950,13 @@ func (v *DefaultASTVisitor) VisitCompressionCodec(expr *CompressionCodec) error
 	return nil
 }
 
+func (v *DefaultASTVisitor) VisitStatisticsClause(expr *StatisticsClause) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
 func (v *DefaultASTVisitor) VisitNumberLiteral(expr *NumberLiteral) error {
 	if v.Visit != nil {
 		return v.Visit(expr)
@@ -936,6 +971,13 @@ func (v *DefaultASTVisitor) VisitStringLiteral(expr *StringLiteral) error {
 	return nil
 }
 
+func (v *DefaultASTVisitor) VisitTypedLiteral(expr *TypedLiteral) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
 func (v *DefaultASTVisitor) VisitRatioExpr(expr *RatioExpr) error {
 	if v.Visit != nil {
 		return v.Visit(expr)
@@ -964,6 +1006,13 @@ func (v *DefaultASTVisitor) VisitIntervalExpr(expr *IntervalExpr) error {
 	return nil
 }
 
+func (v *DefaultASTVisitor) VisitIntervalLiteral(expr *IntervalLiteral) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
 func (v *DefaultASTVisitor) VisitEngineExpr(expr *EngineExpr) error {
 	if v.Visit != nil {
 		return v.Visit(expr)
@@ -1384,6 +1433,13 @@ func (v *DefaultASTVisitor) VisitSetExpr(expr *SetStmt) error {
 	return nil
 }
 
+func (v *DefaultASTVisitor) VisitIntoOutfileClause(expr *IntoOutfileClause) error {
+	if v.Visit != nil {
+		return v.Visit(expr)
+	}
+	return nil
+}
+
 func (v *DefaultASTVisitor) VisitFormatExpr(expr *FormatClause) error {
 	if v.Visit != nil {
 		return v.Visit(expr)
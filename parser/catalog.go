@@ -0,0 +1,849 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TableKind tells tables apart from the other objects a database holds.
+type TableKind string
+
+const (
+	TableKindTable            TableKind = "TABLE"
+	TableKindView             TableKind = "VIEW"
+	TableKindMaterializedView TableKind = "MATERIALIZED VIEW"
+	TableKindLiveView         TableKind = "LIVE VIEW"
+	TableKindDictionary       TableKind = "DICTIONARY"
+)
+
+// Catalog is an in-memory schema built by replaying DDL statements. It
+// starts with an empty database named default.
+type Catalog struct {
+	// DefaultDatabase is the database of unqualified names. USE statements
+	// change it.
+	DefaultDatabase string
+	databases       map[string]*DatabaseSchema
+}
+
+// DatabaseSchema is a database of a Catalog.
+type DatabaseSchema struct {
+	Name    string
+	Engine  *EngineExpr
+	Comment string
+	tables  map[string]*TableSchema
+}
+
+// TableSchema is a table, view or dictionary of a Catalog. The sorting,
+// partition, primary and sample keys, TTL and settings reflect later ALTER
+// statements, so they supersede the clauses of Engine.
+type TableSchema struct {
+	Database    string
+	Name        string
+	Kind        TableKind
+	Columns     []*ColumnDef
+	Indexes     []*TableIndex
+	Projections []*TableProjection
+	Constraints []*ConstraintClause
+	Engine      *EngineExpr
+	OrderBy     Expr
+	PartitionBy Expr
+	PrimaryKey  Expr
+	SampleBy    Expr
+	TTL         *TTLClause
+	Settings    []*SettingExpr
+	// Query is the query of a view or materialized view.
+	Query *SelectQuery
+	// Destination is the TO table of a materialized or live view.
+	Destination *TableIdentifier
+	Comment     string
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{
+		DefaultDatabase: "default",
+		databases: map[string]*DatabaseSchema{
+			"default": {Name: "default", tables: make(map[string]*TableSchema)},
+		},
+	}
+}
+
+// Database returns the named database, or nil if it does not exist.
+func (c *Catalog) Database(name string) *DatabaseSchema {
+	return c.databases[name]
+}
+
+// HasDatabase implements DatabaseProvider.
+func (c *Catalog) HasDatabase(name string) bool {
+	return c.databases[name] != nil
+}
+
+// Databases returns the databases sorted by name.
+func (c *Catalog) Databases() []*DatabaseSchema {
+	databases := make([]*DatabaseSchema, 0, len(c.databases))
+	for _, database := range c.databases {
+		databases = append(databases, database)
+	}
+	sort.Slice(databases, func(i, j int) bool {
+		return databases[i].Name < databases[j].Name
+	})
+	return databases
+}
+
+// Table returns a table, view or dictionary, or nil if it does not exist.
+// An empty database means the default database.
+func (c *Catalog) Table(database, name string) *TableSchema {
+	db := c.databases[c.databaseName(database)]
+	if db == nil {
+		return nil
+	}
+	return db.tables[name]
+}
+
+// Tables returns the tables, views and dictionaries of a database sorted
+// by name.
+func (d *DatabaseSchema) Tables() []*TableSchema {
+	tables := make([]*TableSchema, 0, len(d.tables))
+	for _, table := range d.tables {
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Name < tables[j].Name
+	})
+	return tables
+}
+
+// Table returns the named table, view or dictionary, or nil.
+func (d *DatabaseSchema) Table(name string) *TableSchema {
+	return d.tables[name]
+}
+
+// TableColumns implements SchemaProvider. The columns of a Nested column
+// are listed individually, as ClickHouse stores them.
+func (c *Catalog) TableColumns(database, table string) []string {
+	schema := c.Table(database, table)
+	if schema == nil {
+		return nil
+	}
+	return schema.ColumnNames()
+}
+
+// ColumnType returns the type of a column of a table, or nil if the table,
+// the column or its type is unknown. The fields of a Nested column are
+// arrays. It implements ColumnTypeProvider.
+func (c *Catalog) ColumnType(database, table, column string) *DataType {
+	schema := c.Table(database, table)
+	if schema == nil {
+		return nil
+	}
+	if def := schema.Column(column); def != nil {
+		t, _ := def.DataType()
+		return t
+	}
+	name, field, ok := strings.Cut(column, ".")
+	if !ok {
+		return nil
+	}
+	def := schema.Column(name)
+	if def == nil {
+		return nil
+	}
+	t, _ := def.DataType()
+	if t == nil || t.Name != "Nested" {
+		return nil
+	}
+	for _, f := range t.Fields {
+		if f.Name == field {
+			return &DataType{Name: "Array", Elem: f.Type}
+		}
+	}
+	return nil
+}
+
+// ColumnNames returns the names of the columns, with Nested columns
+// expanded to name.field.
+func (t *TableSchema) ColumnNames() []string {
+	names := make([]string, 0, len(t.Columns))
+	for _, column := range t.Columns {
+		name := nestedIdentifierName(column.Name)
+		if nested, ok := column.Type.(*NestedType); ok && strings.EqualFold(nested.Name.Name, "Nested") {
+			for _, field := range nested.Columns {
+				if def, ok := field.(*ColumnDef); ok {
+					names = append(names, name+"."+nestedIdentifierName(def.Name))
+				}
+			}
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// Column returns the named column, or nil.
+func (t *TableSchema) Column(name string) *ColumnDef {
+	if i := t.columnIndex(name); i >= 0 {
+		return t.Columns[i]
+	}
+	return nil
+}
+
+func (t *TableSchema) columnIndex(name string) int {
+	for i, column := range t.Columns {
+		if nestedIdentifierName(column.Name) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Setting returns the value of a table setting, or nil if it is not set.
+func (t *TableSchema) Setting(name string) Expr {
+	for _, setting := range t.Settings {
+		if setting.Name.Name == name {
+			return setting.Expr
+		}
+	}
+	return nil
+}
+
+// ApplyAll replays statements in order. It stops at the first statement
+// that does not apply, and reports its index.
+func (c *Catalog) ApplyAll(stmts []Expr) error {
+	for i, stmt := range stmts {
+		if err := c.Apply(stmt); err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Apply replays a statement. Statements that do not change the schema,
+// such as SELECT or INSERT, are ignored. It returns an error, and leaves
+// the catalog unchanged, when the statement would fail on a server with
+// this schema, for example when it creates a table that already exists.
+func (c *Catalog) Apply(stmt Expr) error {
+	switch s := stmt.(type) {
+	case *UseStmt:
+		if c.databases[s.Database.Name] == nil {
+			return fmt.Errorf("database %s does not exist", s.Database.Name)
+		}
+		c.DefaultDatabase = s.Database.Name
+		return nil
+	case *CreateDatabase:
+		return c.createDatabase(s)
+	case *DropDatabase:
+		if c.databases[s.Name.Name] == nil {
+			if s.IfExists {
+				return nil
+			}
+			return fmt.Errorf("database %s does not exist", s.Name.Name)
+		}
+		delete(c.databases, s.Name.Name)
+		return nil
+	case *CreateTable:
+		return c.createTable(s)
+	case *CreateView:
+		table := &TableSchema{Kind: TableKindView, Comment: stringLiteralValue(s.Comment)}
+		if s.SubQuery != nil {
+			table.Query = s.SubQuery.Select
+		}
+		table.Columns = c.queryColumns(s.TableSchema, table.Query)
+		return c.create(s.Name, table, s.IfNotExists, s.OrReplace)
+	case *CreateMaterializedView:
+		table := &TableSchema{Kind: TableKindMaterializedView, Engine: s.Engine, Comment: stringLiteralValue(s.Comment)}
+		if s.SubQuery != nil {
+			table.Query = s.SubQuery.Select
+		}
+		if s.Destination != nil {
+			table.Destination = s.Destination.TableIdentifier
+		}
+		table.Columns = c.queryColumns(s.TableSchema, table.Query)
+		table.applyEngine(s.Engine)
+		return c.create(s.Name, table, s.IfNotExists, s.OrReplace)
+	case *CreateLiveView:
+		table := &TableSchema{Kind: TableKindLiveView}
+		if s.SubQuery != nil {
+			table.Query = s.SubQuery.Select
+		}
+		if s.Destination != nil {
+			table.Destination = s.Destination.TableIdentifier
+		}
+		table.Columns = c.queryColumns(s.TableSchema, table.Query)
+		return c.create(s.Name, table, s.IfNotExists, false)
+	case *CreateDictionary:
+		table := &TableSchema{Kind: TableKindDictionary, Comment: stringLiteralValue(s.Comment)}
+		if s.Schema != nil {
+			for _, attribute := range s.Schema.Attributes {
+				table.Columns = append(table.Columns, &ColumnDef{
+					NamePos: attribute.NamePos,
+					Name:    &NestedIdentifier{Ident: attribute.Name},
+					Type:    attribute.Type,
+				})
+			}
+		}
+		return c.create(s.Name, table, s.IfNotExists, s.OrReplace)
+	case *AlterTable:
+		return c.alterTable(s)
+	case *RenameStmt:
+		return c.rename(s)
+	case *DropStmt:
+		database, table, err := c.lookup(s.Name)
+		if err != nil {
+			if s.IfExists {
+				return nil
+			}
+			return err
+		}
+		delete(database.tables, table.Name)
+		return nil
+	case *TruncateTable:
+		if _, _, err := c.lookup(s.Name); err != nil && !s.IfExists {
+			return err
+		}
+		return nil
+	}
+	return nil
+}
+
+func (c *Catalog) databaseName(database string) string {
+	if database == "" {
+		return c.DefaultDatabase
+	}
+	return database
+}
+
+func (c *Catalog) identifierDatabase(name *TableIdentifier) string {
+	if name.Database == nil {
+		return c.DefaultDatabase
+	}
+	return name.Database.Name
+}
+
+func (c *Catalog) lookup(name *TableIdentifier) (*DatabaseSchema, *TableSchema, error) {
+	databaseName := c.identifierDatabase(name)
+	database := c.databases[databaseName]
+	if database == nil {
+		return nil, nil, fmt.Errorf("database %s does not exist", databaseName)
+	}
+	table := database.tables[name.Table.Name]
+	if table == nil {
+		return nil, nil, fmt.Errorf("table %s.%s does not exist", databaseName, name.Table.Name)
+	}
+	return database, table, nil
+}
+
+func (c *Catalog) createDatabase(s *CreateDatabase) error {
+	ident, ok := s.Name.(*Ident)
+	if !ok {
+		return fmt.Errorf("unsupported database name %s", Format(s.Name))
+	}
+	if c.databases[ident.Name] != nil {
+		if s.IfNotExists {
+			return nil
+		}
+		return fmt.Errorf("database %s already exists", ident.Name)
+	}
+	c.databases[ident.Name] = &DatabaseSchema{
+		Name:    ident.Name,
+		Engine:  s.Engine,
+		Comment: stringLiteralValue(s.Comment),
+		tables:  make(map[string]*TableSchema),
+	}
+	return nil
+}
+
+func (c *Catalog) create(name *TableIdentifier, table *TableSchema, ifNotExists, orReplace bool) error {
+	databaseName := c.identifierDatabase(name)
+	database := c.databases[databaseName]
+	if database == nil {
+		return fmt.Errorf("database %s does not exist", databaseName)
+	}
+	if database.tables[name.Table.Name] != nil && !orReplace {
+		if ifNotExists {
+			return nil
+		}
+		return fmt.Errorf("table %s.%s already exists", databaseName, name.Table.Name)
+	}
+	table.Database = databaseName
+	table.Name = name.Table.Name
+	database.tables[table.Name] = table
+	return nil
+}
+
+func (c *Catalog) createTable(s *CreateTable) error {
+	if s.IsReplace {
+		if _, _, err := c.lookup(s.Name); err != nil {
+			return err
+		}
+	}
+	table := &TableSchema{Kind: TableKindTable, Comment: stringLiteralValue(s.Comment)}
+	source := s.SourceTable
+	if source == nil && s.TableSchema != nil {
+		source = s.TableSchema.AliasTable
+	}
+	if source != nil {
+		_, sourceTable, err := c.lookup(source)
+		if err != nil {
+			return err
+		}
+		table.copyFrom(sourceTable)
+	}
+	if s.TableSchema != nil {
+		for _, column := range s.TableSchema.Columns {
+			table.addElement(column)
+		}
+	}
+	if s.SubQuery != nil && len(table.Columns) == 0 {
+		table.Columns = c.queryColumns(nil, s.SubQuery.Select)
+	}
+	if s.Engine != nil {
+		table.Engine = s.Engine
+		table.OrderBy, table.PartitionBy, table.PrimaryKey, table.SampleBy, table.TTL, table.Settings = nil, nil, nil, nil, nil, nil
+		table.applyEngine(s.Engine)
+	}
+	return c.create(s.Name, table, s.IfNotExists, s.OrReplace || s.IsReplace)
+}
+
+// copyFrom copies the structure of the table named by CREATE TABLE ... AS.
+func (t *TableSchema) copyFrom(source *TableSchema) {
+	t.Columns = append([]*ColumnDef(nil), source.Columns...)
+	t.Indexes = append([]*TableIndex(nil), source.Indexes...)
+	t.Projections = append([]*TableProjection(nil), source.Projections...)
+	t.Constraints = append([]*ConstraintClause(nil), source.Constraints...)
+	t.Engine = source.Engine
+	t.OrderBy = source.OrderBy
+	t.PartitionBy = source.PartitionBy
+	t.PrimaryKey = source.PrimaryKey
+	t.SampleBy = source.SampleBy
+	t.TTL = source.TTL
+	t.Settings = append([]*SettingExpr(nil), source.Settings...)
+}
+
+func (t *TableSchema) addElement(element Expr) {
+	switch e := element.(type) {
+	case *ColumnDef:
+		t.Columns = append(t.Columns, e)
+	case *TableIndex:
+		t.Indexes = append(t.Indexes, e)
+	case *TableProjection:
+		t.Projections = append(t.Projections, e)
+	case *ConstraintClause:
+		t.Constraints = append(t.Constraints, e)
+	}
+}
+
+func (t *TableSchema) applyEngine(engine *EngineExpr) {
+	if engine == nil {
+		return
+	}
+	if engine.OrderBy != nil {
+		t.OrderBy = orderByKey(engine.OrderBy)
+	}
+	if engine.PartitionBy != nil {
+		t.PartitionBy = engine.PartitionBy.Expr
+	}
+	if engine.PrimaryKey != nil {
+		t.PrimaryKey = engine.PrimaryKey.Expr
+	}
+	if engine.SampleBy != nil {
+		t.SampleBy = engine.SampleBy.Expr
+	}
+	if engine.TTL != nil {
+		t.TTL = engine.TTL
+	}
+	if engine.Settings != nil {
+		t.Settings = append([]*SettingExpr(nil), engine.Settings.Items...)
+	}
+}
+
+// orderByKey turns the ORDER BY of a table into the sorting key expression,
+// as ALTER TABLE ... MODIFY ORDER BY writes it.
+func orderByKey(orderBy *OrderByClause) Expr {
+	exprs := make([]Expr, len(orderBy.Items))
+	for i, item := range orderBy.Items {
+		if order, ok := item.(*OrderExpr); ok {
+			exprs[i] = order.Expr
+		} else {
+			exprs[i] = item
+		}
+	}
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return &ParamExprList{Items: &ColumnExprList{Items: exprs}}
+}
+
+// queryColumns returns the declared columns of a view, or the output
+// columns of its query, typed where their types can be inferred.
+func (c *Catalog) queryColumns(schema *TableSchemaClause, query *SelectQuery) []*ColumnDef {
+	var columns []*ColumnDef
+	if schema != nil {
+		for _, element := range schema.Columns {
+			if column, ok := element.(*ColumnDef); ok {
+				columns = append(columns, column)
+			}
+		}
+		return columns
+	}
+	if query == nil {
+		return nil
+	}
+	output, err := OutputColumns(query, c, nil)
+	if err != nil {
+		return nil
+	}
+	for _, column := range output {
+		def := &ColumnDef{Name: &NestedIdentifier{Ident: &Ident{Name: column.Name}}}
+		if column.Type != nil {
+			def.Type, _ = parseColumnType(column.Type.String())
+		}
+		columns = append(columns, def)
+	}
+	return columns
+}
+
+func (c *Catalog) rename(s *RenameStmt) error {
+	// the pairs are checked before any is applied, so that a failing pair
+	// leaves the catalog unchanged
+	if err := c.checkRename(s); err != nil {
+		return err
+	}
+	if s.RenameTarget == KeywordDatabase {
+		for _, pair := range s.TargetPairList {
+			oldName, newName := pair.Old.Table.Name, pair.New.Table.Name
+			database := c.databases[oldName]
+			delete(c.databases, oldName)
+			database.Name = newName
+			for _, table := range database.tables {
+				table.Database = newName
+			}
+			c.databases[newName] = database
+		}
+		return nil
+	}
+	for _, pair := range s.TargetPairList {
+		oldDatabase, table, _ := c.lookup(pair.Old)
+		newDatabase := c.databases[c.identifierDatabase(pair.New)]
+		delete(oldDatabase.tables, table.Name)
+		table.Database = newDatabase.Name
+		table.Name = pair.New.Table.Name
+		newDatabase.tables[table.Name] = table
+	}
+	return nil
+}
+
+// checkRename replays the pairs of a RENAME on the names they refer to,
+// each pair seeing the names the earlier ones leave.
+func (c *Catalog) checkRename(s *RenameStmt) error {
+	if s.RenameTarget == KeywordDatabase {
+		databases := make(map[string]bool, len(c.databases))
+		for name := range c.databases {
+			databases[name] = true
+		}
+		for _, pair := range s.TargetPairList {
+			oldName, newName := pair.Old.Table.Name, pair.New.Table.Name
+			if !databases[oldName] {
+				return fmt.Errorf("database %s does not exist", oldName)
+			}
+			if databases[newName] {
+				return fmt.Errorf("database %s already exists", newName)
+			}
+			delete(databases, oldName)
+			databases[newName] = true
+		}
+		return nil
+	}
+	type tableName struct{ database, table string }
+	// renamed records the tables the earlier pairs moved away or created
+	renamed := make(map[tableName]bool)
+	exists := func(name tableName) bool {
+		if exists, ok := renamed[name]; ok {
+			return exists
+		}
+		database := c.databases[name.database]
+		return database != nil && database.tables[name.table] != nil
+	}
+	for _, pair := range s.TargetPairList {
+		oldName := tableName{c.identifierDatabase(pair.Old), pair.Old.Table.Name}
+		newName := tableName{c.identifierDatabase(pair.New), pair.New.Table.Name}
+		if c.databases[oldName.database] == nil {
+			return fmt.Errorf("database %s does not exist", oldName.database)
+		}
+		if !exists(oldName) {
+			return fmt.Errorf("table %s.%s does not exist", oldName.database, oldName.table)
+		}
+		if c.databases[newName.database] == nil {
+			return fmt.Errorf("database %s does not exist", newName.database)
+		}
+		if exists(newName) {
+			return fmt.Errorf("table %s.%s already exists", newName.database, newName.table)
+		}
+		renamed[oldName] = false
+		renamed[newName] = true
+	}
+	return nil
+}
+
+func (c *Catalog) alterTable(s *AlterTable) error {
+	_, table, err := c.lookup(s.TableIdentifier)
+	if err != nil {
+		return err
+	}
+	// work on a copy, so that a failing clause leaves the table unchanged
+	altered := *table
+	altered.Columns = append([]*ColumnDef(nil), table.Columns...)
+	altered.Indexes = append([]*TableIndex(nil), table.Indexes...)
+	altered.Projections = append([]*TableProjection(nil), table.Projections...)
+	altered.Settings = append([]*SettingExpr(nil), table.Settings...)
+	for _, clause := range s.AlterExprs {
+		if err := altered.alter(clause); err != nil {
+			return fmt.Errorf("%s on %s.%s: %w", clause.AlterType(), table.Database, table.Name, err)
+		}
+	}
+	*table = altered
+	return nil
+}
+
+func (t *TableSchema) alter(clause AlterTableClause) error {
+	switch a := clause.(type) {
+	case *AlterTableAddColumn:
+		name := nestedIdentifierName(a.Column.Name)
+		if t.columnIndex(name) >= 0 {
+			if a.IfNotExists {
+				return nil
+			}
+			return fmt.Errorf("column %s already exists", name)
+		}
+		at := len(t.Columns)
+		if a.After != nil {
+			after := t.columnIndex(nestedIdentifierName(a.After))
+			if after < 0 {
+				return fmt.Errorf("column %s does not exist", nestedIdentifierName(a.After))
+			}
+			at = after + 1
+		}
+		t.Columns = insertAt(t.Columns, at, a.Column)
+	case *AlterTableDropColumn:
+		name := nestedIdentifierName(a.ColumnName)
+		i := t.columnIndex(name)
+		if i < 0 {
+			if a.IfExists {
+				return nil
+			}
+			return fmt.Errorf("column %s does not exist", name)
+		}
+		t.Columns = append(t.Columns[:i], t.Columns[i+1:]...)
+	case *AlterTableModifyColumn:
+		name := nestedIdentifierName(a.Column.Name)
+		i := t.columnIndex(name)
+		if i < 0 {
+			if a.IfExists {
+				return nil
+			}
+			return fmt.Errorf("column %s does not exist", name)
+		}
+		if a.RemovePropertyType != nil {
+			t.Columns[i] = removeColumnProperty(t.Columns[i], a.RemovePropertyType)
+		} else {
+			t.Columns[i] = modifyColumn(t.Columns[i], a.Column)
+		}
+	case *AlterTableRenameColumn:
+		oldName, newName := nestedIdentifierName(a.OldColumnName), nestedIdentifierName(a.NewColumnName)
+		i := t.columnIndex(oldName)
+		if i < 0 {
+			if a.IfExists {
+				return nil
+			}
+			return fmt.Errorf("column %s does not exist", oldName)
+		}
+		if t.columnIndex(newName) >= 0 {
+			return fmt.Errorf("column %s already exists", newName)
+		}
+		column := *t.Columns[i]
+		column.Name = a.NewColumnName
+		t.Columns[i] = &column
+	case *AlterTableClearColumn:
+		name := nestedIdentifierName(a.ColumnName)
+		if t.columnIndex(name) < 0 && !a.IfExists {
+			return fmt.Errorf("column %s does not exist", name)
+		}
+	case *AlterTableAddIndex:
+		name := nestedIdentifierName(a.Index.Name)
+		if indexOf(t.Indexes, name) >= 0 {
+			if a.IfNotExists {
+				return nil
+			}
+			return fmt.Errorf("index %s already exists", name)
+		}
+		at := len(t.Indexes)
+		if a.After != nil {
+			after := indexOf(t.Indexes, nestedIdentifierName(a.After))
+			if after < 0 {
+				return fmt.Errorf("index %s does not exist", nestedIdentifierName(a.After))
+			}
+			at = after + 1
+		}
+		t.Indexes = insertAt(t.Indexes, at, a.Index)
+	case *AlterTableDropIndex:
+		name := nestedIdentifierName(a.IndexName)
+		i := indexOf(t.Indexes, name)
+		if i < 0 {
+			if a.IfExists {
+				return nil
+			}
+			return fmt.Errorf("index %s does not exist", name)
+		}
+		t.Indexes = append(t.Indexes[:i], t.Indexes[i+1:]...)
+	case *AlterTableAddProjection:
+		name := nestedIdentifierName(a.TableProjection.Identifier)
+		if projectionOf(t.Projections, name) >= 0 {
+			if a.IfNotExists {
+				return nil
+			}
+			return fmt.Errorf("projection %s already exists", name)
+		}
+		at := len(t.Projections)
+		if a.After != nil {
+			after := projectionOf(t.Projections, nestedIdentifierName(a.After))
+			if after < 0 {
+				return fmt.Errorf("projection %s does not exist", nestedIdentifierName(a.After))
+			}
+			at = after + 1
+		}
+		t.Projections = insertAt(t.Projections, at, a.TableProjection)
+	case *AlterTableDropProjection:
+		name := nestedIdentifierName(a.ProjectionName)
+		i := projectionOf(t.Projections, name)
+		if i < 0 {
+			if a.IfExists {
+				return nil
+			}
+			return fmt.Errorf("projection %s does not exist", name)
+		}
+		t.Projections = append(t.Projections[:i], t.Projections[i+1:]...)
+	case *AlterTableModifyOrderBy:
+		t.OrderBy = a.OrderBy
+	case *AlterTableModifyTTL:
+		t.TTL = a.TTL
+	case *AlterTableRemoveTTL:
+		t.TTL = nil
+	case *AlterTableModifySetting:
+		for _, setting := range a.Settings {
+			t.setSetting(setting)
+		}
+	case *AlterTableResetSetting:
+		for _, name := range a.Settings {
+			for i, setting := range t.Settings {
+				if setting.Name.Name == name.Name {
+					t.Settings = append(t.Settings[:i], t.Settings[i+1:]...)
+					break
+				}
+			}
+		}
+	case *AlterTableModifyQuery:
+		if t.Kind != TableKindMaterializedView && t.Kind != TableKindView {
+			return fmt.Errorf("%s is not a view", t.Name)
+		}
+		t.Query = a.SelectExpr
+	}
+	return nil
+}
+
+func (t *TableSchema) setSetting(setting *SettingExpr) {
+	for i, existing := range t.Settings {
+		if existing.Name.Name == setting.Name.Name {
+			t.Settings[i] = setting
+			return
+		}
+	}
+	t.Settings = append(t.Settings, setting)
+}
+
+// modifyColumn applies MODIFY COLUMN, which keeps the properties it does
+// not mention.
+func modifyColumn(column, modification *ColumnDef) *ColumnDef {
+	modified := *column
+	if modification.Type != nil {
+		modified.Type = modification.Type
+		modified.Nullable = modification.Nullable
+		modified.NotNull = modification.NotNull
+	}
+	if modification.DefaultExpr != nil || modification.MaterializedExpr != nil || modification.AliasExpr != nil || modification.Ephemeral {
+		modified.DefaultExpr = modification.DefaultExpr
+		modified.MaterializedExpr = modification.MaterializedExpr
+		modified.AliasExpr = modification.AliasExpr
+		modified.Ephemeral = modification.Ephemeral
+		modified.EphemeralExpr = modification.EphemeralExpr
+	}
+	if modification.Codec != nil {
+		modified.Codec = modification.Codec
+	}
+	if modification.TTL != nil {
+		modified.TTL = modification.TTL
+	}
+	if modification.Comment != nil {
+		modified.Comment = modification.Comment
+	}
+	if modification.Settings != nil {
+		modified.Settings = modification.Settings
+	}
+	if modification.Statistics != nil {
+		modified.Statistics = modification.Statistics
+	}
+	return &modified
+}
+
+// removeColumnProperty applies MODIFY COLUMN ... REMOVE.
+func removeColumnProperty(column *ColumnDef, remove *RemovePropertyType) *ColumnDef {
+	modified := *column
+	property, ok := remove.PropertyType.(*PropertyType)
+	if !ok {
+		return &modified
+	}
+	switch strings.ToUpper(property.Name.Name) {
+	case KeywordDefault, KeywordMaterialized, KeywordAlias, KeywordEphemeral:
+		modified.DefaultExpr, modified.MaterializedExpr, modified.AliasExpr = nil, nil, nil
+		modified.Ephemeral, modified.EphemeralExpr = false, nil
+	case KeywordCodec:
+		modified.Codec = nil
+	case KeywordTtl:
+		modified.TTL = nil
+	case KeywordComment:
+		modified.Comment = nil
+	case KeywordSettings:
+		modified.Settings = nil
+	}
+	return &modified
+}
+
+func indexOf(indexes []*TableIndex, name string) int {
+	for i, index := range indexes {
+		if nestedIdentifierName(index.Name) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func projectionOf(projections []*TableProjection, name string) int {
+	for i, projection := range projections {
+		if nestedIdentifierName(projection.Identifier) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func insertAt[T any](items []T, at int, item T) []T {
+	items = append(items, item)
+	copy(items[at+1:], items[at:])
+	items[at] = item
+	return items
+}
+
+func stringLiteralValue(literal *StringLiteral) string {
+	if literal == nil {
+		return ""
+	}
+	return literal.Value()
+}
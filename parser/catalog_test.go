@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func catalogOf(t *testing.T, sql string) *Catalog {
+	t.Helper()
+	stmts, err := NewParser(sql).ParseStmts()
+	require.NoError(t, err)
+	catalog := NewCatalog()
+	require.NoError(t, catalog.ApplyAll(stmts))
+	return catalog
+}
+
+func TestCatalogCreate(t *testing.T) {
+	catalog := catalogOf(t, `
+CREATE DATABASE db ENGINE = Atomic COMMENT 'events';
+CREATE TABLE db.events (
+    id UInt64,
+    ts DateTime,
+    n Nested(key String, value String),
+    INDEX idx_ts ts TYPE minmax GRANULARITY 1,
+    PROJECTION p (SELECT id ORDER BY ts),
+    CONSTRAINT c CHECK id > 0
+) ENGINE = MergeTree ORDER BY (id, ts) PARTITION BY toYYYYMM(ts) TTL ts + INTERVAL 1 DAY SETTINGS index_granularity = 8192;
+CREATE TABLE db.events_copy AS db.events;
+CREATE VIEW db.v AS SELECT id, toDate(ts) AS day FROM db.events;
+CREATE DICTIONARY db.d (id UInt64, name String) PRIMARY KEY id SOURCE(CLICKHOUSE(TABLE 'names')) LAYOUT(FLAT()) LIFETIME(300);
+`)
+	require.Equal(t, []string{"db", "default"}, []string{catalog.Databases()[0].Name, catalog.Databases()[1].Name})
+	require.Equal(t, "events", catalog.Database("db").Comment)
+
+	events := catalog.Table("db", "events")
+	require.NotNil(t, events)
+	require.Equal(t, TableKindTable, events.Kind)
+	require.Equal(t, []string{"id", "ts", "n.key", "n.value"}, events.ColumnNames())
+	require.Equal(t, "DateTime", Format(events.Column("ts").Type))
+	require.Len(t, events.Indexes, 1)
+	require.Len(t, events.Projections, 1)
+	require.Len(t, events.Constraints, 1)
+	require.Equal(t, "(id, ts)", Format(events.OrderBy))
+	require.Equal(t, "toYYYYMM(ts)", Format(events.PartitionBy))
+	require.NotNil(t, events.TTL)
+	require.Equal(t, "8192", Format(events.Setting("index_granularity")))
+
+	eventsCopy := catalog.Table("db", "events_copy")
+	require.Equal(t, events.ColumnNames(), eventsCopy.ColumnNames())
+	require.Equal(t, events.Engine, eventsCopy.Engine)
+
+	view := catalog.Table("db", "v")
+	require.Equal(t, TableKindView, view.Kind)
+	require.Equal(t, []string{"id", "day"}, view.ColumnNames())
+	require.NotNil(t, view.Query)
+
+	dictionary := catalog.Table("db", "d")
+	require.Equal(t, TableKindDictionary, dictionary.Kind)
+	require.Equal(t, []string{"id", "name"}, dictionary.ColumnNames())
+
+	require.Equal(t, []string{"id", "ts", "n.key", "n.value"}, catalog.TableColumns("db", "events"))
+	require.Nil(t, catalog.TableColumns("db", "missing"))
+}
+
+func TestCatalogAlter(t *testing.T) {
+	catalog := catalogOf(t, `
+CREATE TABLE t (a UInt8, b String DEFAULT 'x' COMMENT 'b', INDEX i1 a TYPE minmax GRANULARITY 1) ENGINE = MergeTree ORDER BY a SETTINGS index_granularity = 1024;
+ALTER TABLE t ADD COLUMN c Int32 AFTER a, ADD COLUMN IF NOT EXISTS a UInt8;
+ALTER TABLE t MODIFY COLUMN b LowCardinality(String);
+ALTER TABLE t MODIFY COLUMN b REMOVE COMMENT;
+ALTER TABLE t RENAME COLUMN c TO c2;
+ALTER TABLE t ADD INDEX i2 b TYPE bloom_filter GRANULARITY 1 AFTER i1;
+ALTER TABLE t DROP INDEX i1;
+ALTER TABLE t ADD PROJECTION p (SELECT a ORDER BY b);
+ALTER TABLE t MODIFY TTL toDate('2000-01-01') + INTERVAL 1 DAY;
+ALTER TABLE t MODIFY SETTING max_parts_in_total = 100, index_granularity = 2048;
+ALTER TABLE t RESET SETTING max_parts_in_total;
+ALTER TABLE t MODIFY ORDER BY (a, c2);
+ALTER TABLE t DELETE WHERE a = 1;
+`)
+	table := catalog.Table("", "t")
+	require.Equal(t, []string{"a", "c2", "b"}, table.ColumnNames())
+	b := table.Column("b")
+	require.Equal(t, "LowCardinality(String)", Format(b.Type))
+	require.Equal(t, "'x'", Format(b.DefaultExpr))
+	require.Nil(t, b.Comment)
+	require.Len(t, table.Indexes, 1)
+	require.Equal(t, "i2", nestedIdentifierName(table.Indexes[0].Name))
+	require.Len(t, table.Projections, 1)
+	require.NotNil(t, table.TTL)
+	require.Equal(t, "2048", Format(table.Setting("index_granularity")))
+	require.Nil(t, table.Setting("max_parts_in_total"))
+	require.Equal(t, "(a, c2)", Format(table.OrderBy))
+
+	require.NoError(t, catalog.Apply(parseOneStmt(t, "ALTER TABLE t REMOVE TTL")))
+	require.Nil(t, table.TTL)
+}
+
+func TestCatalogRenameDrop(t *testing.T) {
+	catalog := catalogOf(t, `
+CREATE DATABASE a;
+CREATE DATABASE b;
+CREATE TABLE a.t (x UInt8) ENGINE = Memory;
+RENAME TABLE a.t TO b.t2;
+USE b;
+TRUNCATE TABLE t2;
+CREATE TABLE t3 AS t2;
+DROP TABLE IF EXISTS missing;
+DROP DATABASE a;
+`)
+	require.Nil(t, catalog.Database("a"))
+	require.Equal(t, "b", catalog.DefaultDatabase)
+	require.Equal(t, "t2", catalog.Table("b", "t2").Name)
+	require.Equal(t, []string{"x"}, catalog.Table("", "t3").ColumnNames())
+
+	require.NoError(t, catalog.Apply(parseOneStmt(t, "DROP TABLE b.t2")))
+	require.Nil(t, catalog.Table("b", "t2"))
+}
+
+func TestCatalogErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+	}{
+		{name: "duplicate table", sql: "CREATE TABLE t (a UInt8) ENGINE = Memory; CREATE TABLE t (a UInt8) ENGINE = Memory"},
+		{name: "missing database", sql: "CREATE TABLE nope.t (a UInt8) ENGINE = Memory"},
+		{name: "drop missing table", sql: "DROP TABLE t"},
+		{name: "duplicate column", sql: "CREATE TABLE t (a UInt8) ENGINE = Memory; ALTER TABLE t ADD COLUMN a UInt8"},
+		{name: "missing after column", sql: "CREATE TABLE t (a UInt8) ENGINE = Memory; ALTER TABLE t ADD COLUMN b UInt8 AFTER c"},
+		{name: "drop missing column", sql: "CREATE TABLE t (a UInt8) ENGINE = Memory; ALTER TABLE t DROP COLUMN b"},
+		{name: "rename onto existing column", sql: "CREATE TABLE t (a UInt8, b UInt8) ENGINE = Memory; ALTER TABLE t RENAME COLUMN a TO b"},
+		{name: "duplicate database", sql: "CREATE DATABASE d; CREATE DATABASE d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := NewParser(tt.sql).ParseStmts()
+			require.NoError(t, err)
+			require.Error(t, NewCatalog().ApplyAll(stmts))
+		})
+	}
+}
+
+func TestCatalogFailedAlterLeavesTableUnchanged(t *testing.T) {
+	catalog := catalogOf(t, "CREATE TABLE t (a UInt8) ENGINE = Memory")
+	require.Error(t, catalog.Apply(parseOneStmt(t, "ALTER TABLE t ADD COLUMN b UInt8, DROP COLUMN c")))
+	require.Equal(t, []string{"a"}, catalog.Table("", "t").ColumnNames())
+}
+
+func TestCatalogFailedRenameLeavesCatalogUnchanged(t *testing.T) {
+	catalog := catalogOf(t, "CREATE DATABASE x; CREATE DATABASE y; CREATE TABLE a (i UInt8) ENGINE = Memory; CREATE TABLE b (i UInt8) ENGINE = Memory")
+	err := catalog.Apply(parseOneStmt(t, "RENAME TABLE a TO c, b TO c"))
+	require.EqualError(t, err, "table default.c already exists")
+	require.NotNil(t, catalog.Table("", "a"))
+	require.NotNil(t, catalog.Table("", "b"))
+	require.Nil(t, catalog.Table("", "c"))
+
+	require.Error(t, catalog.Apply(parseOneStmt(t, "RENAME DATABASE x TO z, y TO z")))
+	require.NotNil(t, catalog.Database("x"))
+	require.NotNil(t, catalog.Database("y"))
+	require.Nil(t, catalog.Database("z"))
+
+	// later pairs see the names earlier pairs leave
+	require.NoError(t, catalog.Apply(parseOneStmt(t, "RENAME TABLE a TO tmp, b TO a, tmp TO b")))
+	require.NotNil(t, catalog.Table("", "a"))
+	require.NotNil(t, catalog.Table("", "b"))
+	require.Nil(t, catalog.Table("", "tmp"))
+}
@@ -0,0 +1,679 @@
+package parser
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// DataType is the canonical form of a ColumnType: aliases are resolved,
+// names use ClickHouse's spelling, and parameters are decoded. Which fields
+// are set depends on Name.
+type DataType struct {
+	Name string
+	// Elem is the wrapped type of Nullable, LowCardinality and Array.
+	Elem *DataType
+	// Key and Value are the types of a Map.
+	Key   *DataType
+	Value *DataType
+	// Fields are the elements of a Tuple or the columns of a Nested. Tuple
+	// elements may be unnamed.
+	Fields []DataTypeField
+	// Args are the type arguments of other parametric types, such as
+	// Variant(String, UInt64).
+	Args []*DataType
+	// Precision and Scale belong to Decimal; DateTime64 uses Precision for
+	// its sub-second digits.
+	Precision int
+	Scale     int
+	// Timezone is the optional timezone of DateTime and DateTime64.
+	Timezone string
+	// Length is the size of a FixedString.
+	Length int
+	// EnumValues are the members of Enum8 and Enum16, in declaration order.
+	EnumValues []DataTypeEnumValue
+	// Params holds the SQL of any other parameters, such as JSON options.
+	Params []string
+}
+
+type DataTypeField struct {
+	Name string
+	Type *DataType
+}
+
+type DataTypeEnumValue struct {
+	Name  string
+	Value int64
+}
+
+// dataTypeNames maps the lower-cased name of every type that is not an
+// alias to its canonical spelling.
+var dataTypeNames = map[string]string{}
+
+func init() {
+	for _, name := range []string{
+		"Int8", "Int16", "Int32", "Int64", "Int128", "Int256",
+		"UInt8", "UInt16", "UInt32", "UInt64", "UInt128", "UInt256",
+		"Float32", "Float64", "BFloat16", "Decimal", "Bool",
+		"String", "FixedString", "UUID", "IPv4", "IPv6",
+		"Date", "Date32", "DateTime", "DateTime64", "Time", "Time64",
+		"Enum8", "Enum16", "Nullable", "LowCardinality", "Array", "Map", "Tuple", "Nested",
+		"JSON", "Object", "Dynamic", "Variant", "Nothing", "QBit",
+		"AggregateFunction", "SimpleAggregateFunction",
+		"Point", "Ring", "LineString", "MultiLineString", "Polygon", "MultiPolygon",
+		"IntervalNanosecond", "IntervalMicrosecond", "IntervalMillisecond", "IntervalSecond",
+		"IntervalMinute", "IntervalHour", "IntervalDay", "IntervalWeek",
+		"IntervalMonth", "IntervalQuarter", "IntervalYear",
+	} {
+		dataTypeNames[strings.ToLower(name)] = name
+	}
+}
+
+// dataTypeAliases maps the lower-cased SQL-compatibility names of types to
+// the types they stand for.
+var dataTypeAliases = map[string]string{
+	"tinyint":    "Int8",
+	"int1":       "Int8",
+	"byte":       "Int8",
+	"smallint":   "Int16",
+	"int":        "Int32",
+	"integer":    "Int32",
+	"mediumint":  "Int32",
+	"bigint":     "Int64",
+	"float":      "Float32",
+	"real":       "Float32",
+	"single":     "Float32",
+	"double":     "Float64",
+	"dec":        "Decimal",
+	"numeric":    "Decimal",
+	"fixed":      "Decimal",
+	"boolean":    "Bool",
+	"timestamp":  "DateTime",
+	"inet4":      "IPv4",
+	"inet6":      "IPv6",
+	"text":       "String",
+	"tinytext":   "String",
+	"mediumtext": "String",
+	"longtext":   "String",
+	"char":       "String",
+	"character":  "String",
+	"nchar":      "String",
+	"varchar":    "String",
+	"varchar2":   "String",
+	"nvarchar":   "String",
+	"clob":       "String",
+	"blob":       "String",
+	"tinyblob":   "String",
+	"mediumblob": "String",
+	"longblob":   "String",
+	"bytea":      "String",
+	"binary":     "String",
+	"varbinary":  "String",
+}
+
+// decimalPrecisions are the precisions of the fixed-width Decimal types,
+// which take only a scale.
+var decimalPrecisions = map[string]int{
+	"decimal32":  9,
+	"decimal64":  18,
+	"decimal128": 38,
+	"decimal256": 76,
+}
+
+type integerInfo struct {
+	bits   int
+	signed bool
+}
+
+var integerTypes = map[string]integerInfo{
+	"Int8": {8, true}, "Int16": {16, true}, "Int32": {32, true},
+	"Int64": {64, true}, "Int128": {128, true}, "Int256": {256, true},
+	"UInt8": {8, false}, "UInt16": {16, false}, "UInt32": {32, false},
+	"UInt64": {64, false}, "UInt128": {128, false}, "UInt256": {256, false},
+}
+
+// canonicalTypeName resolves an alias or a differently-cased name. Unknown
+// names, such as the function name in AggregateFunction(sum, UInt64), are
+// returned unchanged.
+func canonicalTypeName(name string) string {
+	lower := strings.ToLower(name)
+	if alias, ok := dataTypeAliases[lower]; ok {
+		return alias
+	}
+	if canonical, ok := dataTypeNames[lower]; ok {
+		return canonical
+	}
+	return name
+}
+
+// ParseDataType parses a type such as "LowCardinality(Nullable(String))"
+// into its canonical form.
+func ParseDataType(typ string) (*DataType, error) {
+	columnType, err := parseColumnType(typ)
+	if err != nil {
+		return nil, err
+	}
+	return NewDataType(columnType)
+}
+
+// parseColumnType parses a type into its syntax tree.
+func parseColumnType(typ string) (ColumnType, error) {
+	p := NewParser(typ)
+	if err := p.lexer.consumeToken(); err != nil {
+		return nil, p.wrapError(err)
+	}
+	columnType, err := p.parseColumnType(p.Pos())
+	if err != nil {
+		return nil, p.wrapError(err)
+	}
+	if p.current() != nil {
+		return nil, p.wrapError(fmt.Errorf("unexpected token after type: %s", p.currentTokenString()))
+	}
+	return columnType, nil
+}
+
+// NewDataType converts a parsed column type into its canonical form.
+func NewDataType(columnType ColumnType) (*DataType, error) {
+	switch t := columnType.(type) {
+	case *ScalarType:
+		return newScalarDataType(t.Name.Name)
+	case *PropertyType:
+		return newScalarDataType(t.Name.Name)
+	case *TypeWithParams:
+		return newDataTypeWithParams(t)
+	case *ComplexType:
+		return newComplexDataType(t)
+	case *NestedType:
+		return newNestedDataType(t)
+	case *EnumType:
+		return newEnumDataType(t)
+	case *JSONType:
+		dataType := &DataType{Name: "JSON"}
+		if t.Options != nil {
+			for _, item := range t.Options.Items {
+				formatter := NewFormatter()
+				item.FormatSQL(formatter)
+				dataType.Params = append(dataType.Params, formatter.String())
+			}
+			sort.Strings(dataType.Params)
+		}
+		return dataType, nil
+	case nil:
+		return nil, fmt.Errorf("missing column type")
+	default:
+		return nil, fmt.Errorf("unsupported column type %T", columnType)
+	}
+}
+
+// DataType returns the canonical type of the column, with a NULL modifier
+// applied as Nullable. It returns nil when the type is omitted and inferred
+// from the default expression.
+func (c *ColumnDef) DataType() (*DataType, error) {
+	if c.Type == nil {
+		return nil, nil
+	}
+	dataType, err := NewDataType(c.Type)
+	if err != nil {
+		return nil, err
+	}
+	if c.Nullable != nil && !dataType.IsNullable() {
+		dataType = &DataType{Name: "Nullable", Elem: dataType}
+	}
+	return dataType, nil
+}
+
+func newScalarDataType(name string) (*DataType, error) {
+	if _, ok := decimalPrecisions[strings.ToLower(name)]; ok {
+		return nil, fmt.Errorf("%s requires a scale", name)
+	}
+	name = canonicalTypeName(name)
+	switch name {
+	case "Decimal":
+		return &DataType{Name: name, Precision: 10}, nil
+	case "DateTime64":
+		return nil, fmt.Errorf("DateTime64 requires a precision")
+	case "FixedString":
+		return nil, fmt.Errorf("FixedString requires a length")
+	case "Nullable", "LowCardinality", "Array", "Map":
+		return nil, fmt.Errorf("%s requires a type argument", name)
+	}
+	return &DataType{Name: name}, nil
+}
+
+func newDataTypeWithParams(t *TypeWithParams) (*DataType, error) {
+	name := t.Name.Name
+	if precision, ok := decimalPrecisions[strings.ToLower(name)]; ok {
+		if len(t.Params) != 1 {
+			return nil, fmt.Errorf("%s takes 1 parameter, got %d", name, len(t.Params))
+		}
+		scale, err := typeParamInt(name, t.Params[0])
+		if err != nil {
+			return nil, err
+		}
+		return newDecimalDataType(precision, scale)
+	}
+
+	name = canonicalTypeName(name)
+	switch name {
+	case "String":
+		// the length of VARCHAR(255) and friends is ignored by ClickHouse
+		return &DataType{Name: name}, nil
+	case "Decimal":
+		if len(t.Params) > 2 {
+			return nil, fmt.Errorf("Decimal takes at most 2 parameters, got %d", len(t.Params))
+		}
+		precision, scale := 10, 0
+		var err error
+		if len(t.Params) > 0 {
+			if precision, err = typeParamInt(name, t.Params[0]); err != nil {
+				return nil, err
+			}
+		}
+		if len(t.Params) > 1 {
+			if scale, err = typeParamInt(name, t.Params[1]); err != nil {
+				return nil, err
+			}
+		}
+		return newDecimalDataType(precision, scale)
+	case "FixedString":
+		if len(t.Params) != 1 {
+			return nil, fmt.Errorf("FixedString takes 1 parameter, got %d", len(t.Params))
+		}
+		length, err := typeParamInt(name, t.Params[0])
+		if err != nil {
+			return nil, err
+		}
+		if length <= 0 {
+			return nil, fmt.Errorf("FixedString length must be positive, got %d", length)
+		}
+		return &DataType{Name: name, Length: length}, nil
+	case "DateTime":
+		if len(t.Params) > 1 {
+			return nil, fmt.Errorf("DateTime takes at most 1 parameter, got %d", len(t.Params))
+		}
+		dataType := &DataType{Name: name}
+		if len(t.Params) == 1 {
+			timezone, ok := t.Params[0].(*StringLiteral)
+			if !ok {
+				return nil, fmt.Errorf("DateTime timezone must be a string, got %s", Format(t.Params[0]))
+			}
+			dataType.Timezone = timezone.Value()
+		}
+		return dataType, nil
+	case "DateTime64":
+		if len(t.Params) == 0 || len(t.Params) > 2 {
+			return nil, fmt.Errorf("DateTime64 takes 1 or 2 parameters, got %d", len(t.Params))
+		}
+		precision, err := typeParamInt(name, t.Params[0])
+		if err != nil {
+			return nil, err
+		}
+		if precision < 0 || precision > 9 {
+			return nil, fmt.Errorf("DateTime64 precision must be between 0 and 9, got %d", precision)
+		}
+		dataType := &DataType{Name: name, Precision: precision}
+		if len(t.Params) == 2 {
+			timezone, ok := t.Params[1].(*StringLiteral)
+			if !ok {
+				return nil, fmt.Errorf("DateTime64 timezone must be a string, got %s", Format(t.Params[1]))
+			}
+			dataType.Timezone = timezone.Value()
+		}
+		return dataType, nil
+	}
+
+	dataType := &DataType{Name: name}
+	for _, param := range t.Params {
+		dataType.Params = append(dataType.Params, Format(param))
+	}
+	return dataType, nil
+}
+
+func newDecimalDataType(precision, scale int) (*DataType, error) {
+	if precision < 1 || precision > 76 {
+		return nil, fmt.Errorf("Decimal precision must be between 1 and 76, got %d", precision)
+	}
+	if scale < 0 || scale > precision {
+		return nil, fmt.Errorf("Decimal scale must be between 0 and %d, got %d", precision, scale)
+	}
+	return &DataType{Name: "Decimal", Precision: precision, Scale: scale}, nil
+}
+
+func typeParamInt(typ string, param Literal) (int, error) {
+	number, ok := param.(*NumberLiteral)
+	if !ok {
+		return 0, fmt.Errorf("%s parameter must be an integer, got %s", typ, Format(param))
+	}
+	value, ok := number.Value().(int64)
+	if !ok {
+		return 0, fmt.Errorf("%s parameter must be an integer, got %s", typ, number.Literal)
+	}
+	return int(value), nil
+}
+
+func newComplexDataType(t *ComplexType) (*DataType, error) {
+	name := canonicalTypeName(t.Name.Name)
+	args := make([]*DataType, len(t.Params))
+	for i, param := range t.Params {
+		arg, err := NewDataType(param)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = arg
+	}
+	switch name {
+	case "Nullable", "LowCardinality", "Array":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s takes 1 type argument, got %d", name, len(args))
+		}
+		return &DataType{Name: name, Elem: args[0]}, nil
+	case "Map":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("Map takes 2 type arguments, got %d", len(args))
+		}
+		return &DataType{Name: name, Key: args[0], Value: args[1]}, nil
+	case "Tuple":
+		dataType := &DataType{Name: name}
+		for _, arg := range args {
+			dataType.Fields = append(dataType.Fields, DataTypeField{Type: arg})
+		}
+		return dataType, nil
+	}
+	return &DataType{Name: name, Args: args}, nil
+}
+
+func newNestedDataType(t *NestedType) (*DataType, error) {
+	dataType := &DataType{Name: canonicalTypeName(t.Name.Name)}
+	for _, column := range t.Columns {
+		var field DataTypeField
+		var err error
+		switch c := column.(type) {
+		case *ColumnDef:
+			field.Name = c.Name.Ident.Name
+			field.Type, err = NewDataType(c.Type)
+		case ColumnType:
+			field.Type, err = NewDataType(c)
+		default:
+			err = fmt.Errorf("unexpected %s element %s", dataType.Name, Format(column))
+		}
+		if err != nil {
+			return nil, err
+		}
+		dataType.Fields = append(dataType.Fields, field)
+	}
+	return dataType, nil
+}
+
+func newEnumDataType(t *EnumType) (*DataType, error) {
+	dataType := &DataType{Name: canonicalTypeName(t.Name.Name)}
+	minValue, maxValue := int64(0), int64(0)
+	for _, value := range t.Values {
+		number, ok := value.Value.Value().(int64)
+		if !ok {
+			return nil, fmt.Errorf("enum value %s must be an integer", value.Value.Literal)
+		}
+		minValue, maxValue = min(minValue, number), max(maxValue, number)
+		dataType.EnumValues = append(dataType.EnumValues, DataTypeEnumValue{Name: value.Name.Value(), Value: number})
+	}
+	if strings.EqualFold(dataType.Name, "Enum") {
+		// a bare Enum is the narrowest width that fits its values
+		dataType.Name = "Enum8"
+		if minValue < -128 || maxValue > 127 {
+			dataType.Name = "Enum16"
+		}
+	}
+	return dataType, nil
+}
+
+// String returns the canonical SQL spelling of the type.
+func (t *DataType) String() string {
+	var b strings.Builder
+	t.writeTo(&b)
+	return b.String()
+}
+
+func (t *DataType) writeTo(b *strings.Builder) {
+	b.WriteString(t.Name)
+	switch {
+	case t.Elem != nil:
+		b.WriteByte('(')
+		t.Elem.writeTo(b)
+		b.WriteByte(')')
+	case t.Key != nil:
+		b.WriteByte('(')
+		t.Key.writeTo(b)
+		b.WriteString(", ")
+		t.Value.writeTo(b)
+		b.WriteByte(')')
+	case t.Name == "Tuple" || t.Name == "Nested":
+		b.WriteByte('(')
+		for i, field := range t.Fields {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if field.Name != "" {
+				b.WriteString(field.Name)
+				b.WriteByte(' ')
+			}
+			field.Type.writeTo(b)
+		}
+		b.WriteByte(')')
+	case t.Name == "Decimal":
+		fmt.Fprintf(b, "(%d, %d)", t.Precision, t.Scale)
+	case t.Name == "DateTime64":
+		fmt.Fprintf(b, "(%d", t.Precision)
+		if t.Timezone != "" {
+			b.WriteString(", " + QuoteString(t.Timezone))
+		}
+		b.WriteByte(')')
+	case t.Name == "DateTime" && t.Timezone != "":
+		b.WriteString("(" + QuoteString(t.Timezone) + ")")
+	case t.Name == "FixedString":
+		fmt.Fprintf(b, "(%d)", t.Length)
+	case len(t.EnumValues) > 0:
+		b.WriteByte('(')
+		for i, value := range t.EnumValues {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(b, "%s = %d", QuoteString(value.Name), value.Value)
+		}
+		b.WriteByte(')')
+	case len(t.Args) > 0:
+		b.WriteByte('(')
+		for i, arg := range t.Args {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			arg.writeTo(b)
+		}
+		b.WriteByte(')')
+	case len(t.Params) > 0:
+		b.WriteString("(" + strings.Join(t.Params, ", ") + ")")
+	}
+}
+
+// Equal reports whether two types are identical, including element names,
+// enum members and timezones.
+func (t *DataType) Equal(other *DataType) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+	return t.String() == other.String()
+}
+
+// IsNullable reports whether the type admits NULL, looking through
+// LowCardinality.
+func (t *DataType) IsNullable() bool {
+	switch t.Name {
+	case "Nullable", "Nothing":
+		return true
+	case "LowCardinality":
+		return t.Elem.IsNullable()
+	}
+	return false
+}
+
+// Unwrap returns the type without its Nullable and LowCardinality wrappers.
+func (t *DataType) Unwrap() *DataType {
+	for t.Name == "Nullable" || t.Name == "LowCardinality" {
+		t = t.Elem
+	}
+	return t
+}
+
+// IsInteger reports whether the type is a signed or unsigned integer.
+func (t *DataType) IsInteger() bool {
+	_, ok := integerTypes[t.Name]
+	return ok
+}
+
+// IsFloat reports whether the type is a floating-point number.
+func (t *DataType) IsFloat() bool {
+	return t.Name == "Float32" || t.Name == "Float64" || t.Name == "BFloat16"
+}
+
+// IsNumeric reports whether the type is an integer, float or decimal.
+func (t *DataType) IsNumeric() bool {
+	return t.IsInteger() || t.IsFloat() || t.Name == "Decimal"
+}
+
+// IsString reports whether the type is String or FixedString.
+func (t *DataType) IsString() bool {
+	return t.Name == "String" || t.Name == "FixedString"
+}
+
+// IsDateOrTime reports whether the type is a date or a date and time.
+func (t *DataType) IsDateOrTime() bool {
+	switch t.Name {
+	case "Date", "Date32", "DateTime", "DateTime64":
+		return true
+	}
+	return false
+}
+
+// AssignableTo reports whether every value of t can be stored in a column
+// of type target without loss, such as UInt8 into Int16, Date into
+// DateTime, or String into LowCardinality(Nullable(String)).
+func (t *DataType) AssignableTo(target *DataType) bool {
+	if t.Equal(target) {
+		return true
+	}
+	if t.Name == "LowCardinality" {
+		return t.Elem.AssignableTo(target)
+	}
+	if target.Name == "LowCardinality" {
+		return t.AssignableTo(target.Elem)
+	}
+	if target.Name == "Nullable" {
+		if t.Name == "Nothing" {
+			return true
+		}
+		if t.Name == "Nullable" {
+			return t.Elem.Name == "Nothing" || t.Elem.AssignableTo(target.Elem)
+		}
+		return t.AssignableTo(target.Elem)
+	}
+	if t.Name == "Nullable" || t.Name == "Nothing" {
+		return false
+	}
+
+	switch {
+	case t.Name == "Array" && target.Name == "Array":
+		return t.Elem.AssignableTo(target.Elem)
+	case t.Name == "Map" && target.Name == "Map":
+		return t.Key.AssignableTo(target.Key) && t.Value.AssignableTo(target.Value)
+	case t.Name == "Tuple" && target.Name == "Tuple":
+		if len(t.Fields) != len(target.Fields) {
+			return false
+		}
+		for i := range t.Fields {
+			if !t.Fields[i].Type.AssignableTo(target.Fields[i].Type) {
+				return false
+			}
+		}
+		return true
+	case t.IsInteger() || t.Name == "Bool":
+		return integerAssignableTo(t, target)
+	case t.Name == "Float32":
+		return target.Name == "Float64"
+	case t.Name == "Decimal":
+		return target.Name == "Decimal" && target.Scale >= t.Scale && target.Precision-target.Scale >= t.Precision-t.Scale
+	case t.Name == "FixedString":
+		return target.Name == "String" || (target.Name == "FixedString" && target.Length >= t.Length)
+	case t.Name == "Enum8" || t.Name == "Enum16":
+		return target.Name == "String" || enumAssignableTo(t, target)
+	case t.IsDateOrTime():
+		return dateAssignableTo(t, target)
+	}
+	return false
+}
+
+func integerAssignableTo(t, target *DataType) bool {
+	source, ok := integerTypes[t.Name]
+	if t.Name == "Bool" {
+		source, ok = integerInfo{bits: 1}, true
+	}
+	if !ok {
+		return false
+	}
+	if to, ok := integerTypes[target.Name]; ok {
+		if to.signed == source.signed {
+			return to.bits >= source.bits
+		}
+		// an unsigned value fits a signed type with more bits
+		return to.signed && to.bits > source.bits
+	}
+	switch target.Name {
+	case "Float32":
+		return source.bits <= 16
+	case "Float64":
+		return source.bits <= 32
+	case "Decimal":
+		return target.Precision-target.Scale >= integerDigits(source)
+	}
+	return false
+}
+
+// integerDigits returns the number of decimal digits of the largest value
+// of an integer type.
+func integerDigits(info integerInfo) int {
+	bits := info.bits
+	if info.signed {
+		bits--
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	return len(limit.Sub(limit, big.NewInt(1)).String())
+}
+
+func enumAssignableTo(t, target *DataType) bool {
+	if target.Name != "Enum8" && target.Name != "Enum16" {
+		return false
+	}
+	members := make(map[DataTypeEnumValue]bool, len(target.EnumValues))
+	for _, value := range target.EnumValues {
+		members[value] = true
+	}
+	for _, value := range t.EnumValues {
+		if !members[value] {
+			return false
+		}
+	}
+	return true
+}
+
+func dateAssignableTo(t, target *DataType) bool {
+	switch t.Name {
+	case "Date":
+		return target.Name == "Date32" || target.Name == "DateTime" || target.Name == "DateTime64"
+	case "Date32":
+		return target.Name == "DateTime64"
+	case "DateTime":
+		// the timezone only changes how the value is displayed
+		return target.Name == "DateTime" || target.Name == "DateTime64"
+	case "DateTime64":
+		return target.Name == "DateTime64" && target.Precision >= t.Precision
+	}
+	return false
+}
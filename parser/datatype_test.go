@@ -0,0 +1,169 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDataType(t *testing.T) {
+	tests := []struct {
+		typ      string
+		expected string
+	}{
+		{"UInt64", "UInt64"},
+		{"INT", "Int32"},
+		{"BIGINT", "Int64"},
+		{"TEXT", "String"},
+		{"VARCHAR(255)", "String"},
+		{"DOUBLE", "Float64"},
+		{"BOOLEAN", "Bool"},
+		{"datetime", "DateTime"},
+		{"TIMESTAMP", "DateTime"},
+		{"Decimal(10, 2)", "Decimal(10, 2)"},
+		{"NUMERIC(5)", "Decimal(5, 0)"},
+		{"Decimal64(4)", "Decimal(18, 4)"},
+		{"DateTime('UTC')", "DateTime('UTC')"},
+		{"DateTime64(6, 'Asia/Shanghai')", "DateTime64(6, 'Asia/Shanghai')"},
+		{"FixedString(16)", "FixedString(16)"},
+		{"LowCardinality(Nullable(TEXT))", "LowCardinality(Nullable(String))"},
+		{"Array(Array(INT))", "Array(Array(Int32))"},
+		{"Map(String, Array(UInt8))", "Map(String, Array(UInt8))"},
+		{"Tuple(String, UInt64)", "Tuple(String, UInt64)"},
+		{"Tuple(name String, age INT)", "Tuple(name String, age Int32)"},
+		{"Nested(id UInt64, tags Array(String))", "Nested(id UInt64, tags Array(String))"},
+		{"Enum8('a' = 1, 'b' = 2)", "Enum8('a' = 1, 'b' = 2)"},
+		{"Enum('a' = 1, 'b' = 1000)", "Enum16('a' = 1, 'b' = 1000)"},
+		{"AggregateFunction(sum, UInt64)", "AggregateFunction(sum, UInt64)"},
+		{"JSON(max_dynamic_paths = 10, a.b UInt64)", "JSON(a.b UInt64, max_dynamic_paths=10)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			dataType, err := ParseDataType(tt.typ)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, dataType.String())
+		})
+	}
+}
+
+func TestParseDataTypeFields(t *testing.T) {
+	dataType, err := ParseDataType("Decimal128(3)")
+	require.NoError(t, err)
+	require.Equal(t, 38, dataType.Precision)
+	require.Equal(t, 3, dataType.Scale)
+
+	dataType, err = ParseDataType("DateTime64(9, 'UTC')")
+	require.NoError(t, err)
+	require.Equal(t, 9, dataType.Precision)
+	require.Equal(t, "UTC", dataType.Timezone)
+
+	dataType, err = ParseDataType("LowCardinality(Nullable(String))")
+	require.NoError(t, err)
+	require.True(t, dataType.IsNullable())
+	require.Equal(t, "String", dataType.Unwrap().Name)
+
+	dataType, err = ParseDataType("Tuple(a String, b Int8)")
+	require.NoError(t, err)
+	require.Equal(t, []DataTypeField{
+		{Name: "a", Type: &DataType{Name: "String"}},
+		{Name: "b", Type: &DataType{Name: "Int8"}},
+	}, dataType.Fields)
+}
+
+func TestParseDataTypeErrors(t *testing.T) {
+	for _, typ := range []string{
+		"Decimal(80, 2)",
+		"Decimal(5, 6)",
+		"Decimal32",
+		"FixedString",
+		"FixedString(0)",
+		"DateTime64(12)",
+		"DateTime(3)",
+		"Nullable",
+		"Map(String)",
+		"String String",
+	} {
+		t.Run(typ, func(t *testing.T) {
+			_, err := ParseDataType(typ)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDataTypeEqual(t *testing.T) {
+	mustParse := func(typ string) *DataType {
+		dataType, err := ParseDataType(typ)
+		require.NoError(t, err)
+		return dataType
+	}
+	require.True(t, mustParse("INTEGER").Equal(mustParse("Int32")))
+	require.True(t, mustParse("Nullable(TEXT)").Equal(mustParse("Nullable(String)")))
+	require.True(t, mustParse("DEC(10, 2)").Equal(mustParse("Decimal(10, 2)")))
+	require.False(t, mustParse("Decimal(10, 2)").Equal(mustParse("Decimal(10, 3)")))
+	require.False(t, mustParse("DateTime('UTC')").Equal(mustParse("DateTime")))
+	require.False(t, mustParse("Tuple(a String)").Equal(mustParse("Tuple(b String)")))
+}
+
+func TestDataTypeAssignableTo(t *testing.T) {
+	tests := []struct {
+		from       string
+		to         string
+		assignable bool
+	}{
+		{"UInt8", "UInt16", true},
+		{"UInt8", "Int16", true},
+		{"UInt16", "Int16", false},
+		{"Int32", "Int16", false},
+		{"Int8", "UInt64", false},
+		{"Int32", "Float64", true},
+		{"Int64", "Float64", false},
+		{"Float32", "Float64", true},
+		{"Int32", "Decimal(10, 0)", true},
+		{"Int32", "Decimal(10, 2)", false},
+		{"Decimal(10, 2)", "Decimal(12, 4)", true},
+		{"Decimal(10, 2)", "Decimal(10, 4)", false},
+		{"String", "Nullable(String)", true},
+		{"Nullable(String)", "String", false},
+		{"Nullable(Nothing)", "Nullable(UInt8)", true},
+		{"String", "LowCardinality(Nullable(String))", true},
+		{"LowCardinality(String)", "String", true},
+		{"FixedString(4)", "String", true},
+		{"String", "FixedString(4)", false},
+		{"Date", "DateTime64(3)", true},
+		{"DateTime", "DateTime('UTC')", true},
+		{"DateTime64(6)", "DateTime64(3)", false},
+		{"Array(UInt8)", "Array(Nullable(UInt32))", true},
+		{"Map(String, UInt8)", "Map(String, UInt64)", true},
+		{"Tuple(UInt8, String)", "Tuple(Int16, Nullable(String))", true},
+		{"Tuple(UInt8, String)", "Tuple(Int16)", false},
+		{"Enum8('a' = 1)", "Enum8('a' = 1, 'b' = 2)", true},
+		{"Enum8('a' = 1, 'b' = 2)", "Enum8('a' = 1)", false},
+		{"Enum8('a' = 1)", "String", true},
+		{"String", "UInt8", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			from, err := ParseDataType(tt.from)
+			require.NoError(t, err)
+			to, err := ParseDataType(tt.to)
+			require.NoError(t, err)
+			require.Equal(t, tt.assignable, from.AssignableTo(to))
+		})
+	}
+}
+
+func TestColumnDefDataType(t *testing.T) {
+	p := NewParser("CREATE TABLE t (a INT, b String NULL, c EPHEMERAL) ENGINE = Memory")
+	stmts, err := p.ParseStmts()
+	require.NoError(t, err)
+	columns := stmts[0].(*CreateTable).TableSchema.Columns
+	dataType, err := columns[0].(*ColumnDef).DataType()
+	require.NoError(t, err)
+	require.Equal(t, "Int32", dataType.String())
+	dataType, err = columns[1].(*ColumnDef).DataType()
+	require.NoError(t, err)
+	require.Equal(t, "Nullable(String)", dataType.String())
+	dataType, err = columns[2].(*ColumnDef).DataType()
+	require.NoError(t, err)
+	require.Nil(t, dataType)
+}
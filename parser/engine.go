@@ -0,0 +1,476 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EngineModel is a typed view of the parameters of a table engine, returned
+// by EngineExpr.Model. The concrete types are *MergeTreeEngine,
+// *DistributedEngine, *KafkaEngine, *S3Engine, *BufferEngine and
+// *DictionaryEngine.
+type EngineModel interface {
+	EngineName() string
+}
+
+// MergeTreeVariant is the merge behaviour of a MergeTree-family engine.
+type MergeTreeVariant string
+
+const (
+	MergeTreeVariantPlain               MergeTreeVariant = ""
+	MergeTreeVariantReplacing           MergeTreeVariant = "Replacing"
+	MergeTreeVariantCollapsing          MergeTreeVariant = "Collapsing"
+	MergeTreeVariantVersionedCollapsing MergeTreeVariant = "VersionedCollapsing"
+	MergeTreeVariantSumming             MergeTreeVariant = "Summing"
+	MergeTreeVariantAggregating         MergeTreeVariant = "Aggregating"
+	MergeTreeVariantGraphite            MergeTreeVariant = "Graphite"
+)
+
+var mergeTreeVariants = []MergeTreeVariant{
+	MergeTreeVariantReplacing,
+	MergeTreeVariantVersionedCollapsing,
+	MergeTreeVariantCollapsing,
+	MergeTreeVariantSumming,
+	MergeTreeVariantAggregating,
+	MergeTreeVariantGraphite,
+	MergeTreeVariantPlain,
+}
+
+// MergeTreeEngine is any engine of the MergeTree family, replicated or not.
+// Only the fields of its variant are set.
+type MergeTreeEngine struct {
+	Name    string
+	Variant MergeTreeVariant
+	// Replication is set for the Replicated* engines.
+	Replication *ReplicationParams
+
+	Version        string   // Replacing: optional ver; VersionedCollapsing: version
+	IsDeleted      string   // Replacing: optional is_deleted
+	Sign           string   // Collapsing and VersionedCollapsing
+	SumColumns     []string // Summing: the columns to sum, all numeric columns when empty
+	GraphiteConfig string   // Graphite: the rollup config section
+}
+
+func (m *MergeTreeEngine) EngineName() string {
+	return m.Name
+}
+
+// ReplicationParams are the coordination path and replica name of a
+// Replicated* engine. Both are empty when the engine relies on the server's
+// default_replica_path and default_replica_name.
+type ReplicationParams struct {
+	ZooKeeperPath string
+	ReplicaName   string
+}
+
+var macroPattern = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// Macros returns the names of the server macros, such as shard and replica,
+// that the path and replica name expand, in order of first use.
+func (r *ReplicationParams) Macros() []string {
+	var macros []string
+	seen := make(map[string]bool)
+	for _, value := range []string{r.ZooKeeperPath, r.ReplicaName} {
+		for _, match := range macroPattern.FindAllStringSubmatch(value, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				macros = append(macros, match[1])
+			}
+		}
+	}
+	return macros
+}
+
+// UsesDefaults reports whether the engine was declared without a path and
+// replica name.
+func (r *ReplicationParams) UsesDefaults() bool {
+	return r.ZooKeeperPath == "" && r.ReplicaName == ""
+}
+
+// DistributedEngine is Distributed(cluster, database, table[, sharding_key[, policy_name]]).
+type DistributedEngine struct {
+	Cluster     string
+	Database    string
+	Table       string
+	ShardingKey Expr // nil when omitted
+	Policy      string
+}
+
+func (d *DistributedEngine) EngineName() string {
+	return "Distributed"
+}
+
+// KafkaEngine holds the core Kafka parameters, given either positionally as
+// Kafka(broker_list, topic_list, group_name, format) or through the
+// kafka_broker_list, kafka_topic_list, kafka_group_name and kafka_format
+// settings. Settings win over positional parameters.
+type KafkaEngine struct {
+	BrokerList string
+	TopicList  string
+	GroupName  string
+	Format     string
+}
+
+func (k *KafkaEngine) EngineName() string {
+	return "Kafka"
+}
+
+// Topics splits the comma-separated topic list.
+func (k *KafkaEngine) Topics() []string {
+	return splitList(k.TopicList)
+}
+
+// Brokers splits the comma-separated broker list.
+func (k *KafkaEngine) Brokers() []string {
+	return splitList(k.BrokerList)
+}
+
+// S3Engine is S3(path [, NOSIGN | access_key_id, secret_access_key]
+// [, format] [, compression]), or the same parameters passed by name as
+// overrides of a named collection.
+type S3Engine struct {
+	Path            string
+	NamedCollection string
+	NoSign          bool
+	AccessKeyID     string
+	SecretAccessKey string
+	Format          string
+	Compression     string
+}
+
+func (s *S3Engine) EngineName() string {
+	return "S3"
+}
+
+// BufferEngine is Buffer(database, table, num_layers, min_time, max_time,
+// min_rows, max_rows, min_bytes, max_bytes[, flush_time[, flush_rows[,
+// flush_bytes]]]). The flush thresholds are zero when omitted.
+type BufferEngine struct {
+	Database   string
+	Table      string
+	NumLayers  int64
+	MinTime    int64
+	MaxTime    int64
+	MinRows    int64
+	MaxRows    int64
+	MinBytes   int64
+	MaxBytes   int64
+	FlushTime  int64
+	FlushRows  int64
+	FlushBytes int64
+}
+
+func (b *BufferEngine) EngineName() string {
+	return "Buffer"
+}
+
+// DictionaryEngine is Dictionary(dictionary_name).
+type DictionaryEngine struct {
+	Dictionary string
+}
+
+func (d *DictionaryEngine) EngineName() string {
+	return "Dictionary"
+}
+
+// Model returns a typed view of the engine's parameters. It returns nil and
+// no error for engines without a model, such as Memory or Log, and an error
+// when the parameters do not fit the engine's signature.
+func (e *EngineExpr) Model() (EngineModel, error) {
+	args := e.args()
+	switch e.Name {
+	case "Distributed":
+		return newDistributedEngine(args)
+	case "Kafka":
+		return newKafkaEngine(args, e.Settings)
+	case "S3":
+		return newS3Engine(args)
+	case "Buffer":
+		return newBufferEngine(args)
+	case "Dictionary":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("Dictionary takes 1 parameter, got %d", len(args))
+		}
+		return &DictionaryEngine{Dictionary: engineArgString(args[0])}, nil
+	}
+	if strings.HasSuffix(e.Name, "MergeTree") {
+		return newMergeTreeEngine(e.Name, args)
+	}
+	return nil, nil
+}
+
+// args returns the engine parameters with their ColumnExpr wrappers removed.
+func (e *EngineExpr) args() []Expr {
+	if e.Params == nil || e.Params.Items == nil {
+		return nil
+	}
+	args := make([]Expr, len(e.Params.Items.Items))
+	for i, item := range e.Params.Items.Items {
+		args[i] = unwrapColumnExpr(item)
+	}
+	return args
+}
+
+func newMergeTreeEngine(name string, args []Expr) (*MergeTreeEngine, error) {
+	engine := &MergeTreeEngine{Name: name}
+	family := strings.TrimSuffix(name, "MergeTree")
+	if rest, ok := strings.CutPrefix(family, "Replicated"); ok {
+		family = rest
+		engine.Replication = &ReplicationParams{}
+		// the path and replica name are optional, but come as a pair of strings
+		if len(args) >= 2 && isStringArg(args[0]) && isStringArg(args[1]) {
+			engine.Replication.ZooKeeperPath = engineArgString(args[0])
+			engine.Replication.ReplicaName = engineArgString(args[1])
+			args = args[2:]
+		}
+	}
+	for _, variant := range mergeTreeVariants {
+		if family == string(variant) {
+			engine.Variant = variant
+			break
+		}
+		if variant == MergeTreeVariantPlain {
+			return nil, fmt.Errorf("unknown MergeTree engine %s", name)
+		}
+	}
+
+	switch engine.Variant {
+	case MergeTreeVariantPlain, MergeTreeVariantAggregating:
+		if len(args) != 0 {
+			return nil, fmt.Errorf("%s takes no parameters, got %d", name, len(args))
+		}
+	case MergeTreeVariantReplacing:
+		if len(args) > 2 {
+			return nil, fmt.Errorf("%s takes at most 2 parameters, got %d", name, len(args))
+		}
+		if len(args) > 0 {
+			engine.Version = engineArgString(args[0])
+		}
+		if len(args) > 1 {
+			engine.IsDeleted = engineArgString(args[1])
+		}
+	case MergeTreeVariantCollapsing:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s takes 1 parameter, got %d", name, len(args))
+		}
+		engine.Sign = engineArgString(args[0])
+	case MergeTreeVariantVersionedCollapsing:
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s takes 2 parameters, got %d", name, len(args))
+		}
+		engine.Sign = engineArgString(args[0])
+		engine.Version = engineArgString(args[1])
+	case MergeTreeVariantSumming:
+		if len(args) > 1 {
+			return nil, fmt.Errorf("%s takes at most 1 parameter, got %d", name, len(args))
+		}
+		if len(args) == 1 {
+			engine.SumColumns = engineArgList(args[0])
+		}
+	case MergeTreeVariantGraphite:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s takes 1 parameter, got %d", name, len(args))
+		}
+		engine.GraphiteConfig = engineArgString(args[0])
+	}
+	return engine, nil
+}
+
+func newDistributedEngine(args []Expr) (*DistributedEngine, error) {
+	if len(args) < 3 || len(args) > 5 {
+		return nil, fmt.Errorf("Distributed takes 3 to 5 parameters, got %d", len(args))
+	}
+	engine := &DistributedEngine{
+		Cluster:  engineArgString(args[0]),
+		Database: engineArgString(args[1]),
+		Table:    engineArgString(args[2]),
+	}
+	if len(args) > 3 {
+		engine.ShardingKey = args[3]
+	}
+	if len(args) > 4 {
+		engine.Policy = engineArgString(args[4])
+	}
+	return engine, nil
+}
+
+func newKafkaEngine(args []Expr, settings *SettingsClause) (*KafkaEngine, error) {
+	if len(args) > 0 && len(args) < 4 {
+		return nil, fmt.Errorf("Kafka takes at least 4 parameters, got %d", len(args))
+	}
+	engine := &KafkaEngine{}
+	if len(args) > 0 {
+		engine.BrokerList = engineArgString(args[0])
+		engine.TopicList = engineArgString(args[1])
+		engine.GroupName = engineArgString(args[2])
+		engine.Format = engineArgString(args[3])
+	}
+	if settings != nil {
+		for _, setting := range settings.Items {
+			value := engineArgString(setting.Expr)
+			switch strings.ToLower(setting.Name.Name) {
+			case "kafka_broker_list":
+				engine.BrokerList = value
+			case "kafka_topic_list":
+				engine.TopicList = value
+			case "kafka_group_name":
+				engine.GroupName = value
+			case "kafka_format":
+				engine.Format = value
+			}
+		}
+	}
+	return engine, nil
+}
+
+// s3Formats are the formats that ClickHouse tells apart from an access key
+// when S3 has three or four positional parameters.
+var s3Formats = NewSet("auto", "Arrow", "ArrowStream", "Avro", "CSV", "CSVWithNames", "CSVWithNamesAndTypes",
+	"CustomSeparated", "JSON", "JSONCompactEachRow", "JSONEachRow", "JSONLines", "LineAsString", "MsgPack",
+	"Native", "NDJSON", "ORC", "Parquet", "Protobuf", "RawBLOB", "RowBinary", "TabSeparated",
+	"TabSeparatedWithNames", "TabSeparatedWithNamesAndTypes", "Template", "TSKV", "TSV", "TSVWithNames",
+	"TSVWithNamesAndTypes", "Values")
+
+func newS3Engine(args []Expr) (*S3Engine, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("S3 takes at least 1 parameter")
+	}
+	engine := &S3Engine{}
+	var positional []Expr
+	for _, arg := range args {
+		name, value, ok := namedEngineArg(arg)
+		if !ok {
+			positional = append(positional, arg)
+			continue
+		}
+		switch strings.ToLower(name) {
+		case "url", "filename":
+			engine.Path = value
+		case "format":
+			engine.Format = value
+		case "compression", "compression_method":
+			engine.Compression = value
+		case "access_key_id":
+			engine.AccessKeyID = value
+		case "secret_access_key":
+			engine.SecretAccessKey = value
+		case "no_sign_request":
+			engine.NoSign = value == "1" || strings.EqualFold(value, "true")
+		default:
+			return nil, fmt.Errorf("unknown S3 parameter %s", name)
+		}
+	}
+	if len(positional) < len(args) {
+		// key = value overrides of a named collection
+		if len(positional) > 1 {
+			return nil, fmt.Errorf("S3 with named parameters takes at most 1 positional parameter, got %d", len(positional))
+		}
+		if len(positional) == 1 {
+			engine.NamedCollection = engineArgString(positional[0])
+		}
+		return engine, nil
+	}
+
+	engine.Path = engineArgString(args[0])
+	rest := args[1:]
+	if len(rest) > 0 && strings.EqualFold(engineArgString(rest[0]), "NOSIGN") {
+		engine.NoSign = true
+		rest = rest[1:]
+	} else if len(rest) >= 2 && !s3Formats.Contains(engineArgString(rest[0])) {
+		engine.AccessKeyID = engineArgString(rest[0])
+		engine.SecretAccessKey = engineArgString(rest[1])
+		rest = rest[2:]
+	}
+	if len(rest) > 2 {
+		return nil, fmt.Errorf("S3 takes at most 5 parameters, got %d", len(args))
+	}
+	if len(rest) > 0 {
+		engine.Format = engineArgString(rest[0])
+	}
+	if len(rest) > 1 {
+		engine.Compression = engineArgString(rest[1])
+	}
+	return engine, nil
+}
+
+func newBufferEngine(args []Expr) (*BufferEngine, error) {
+	if len(args) < 9 || len(args) > 12 {
+		return nil, fmt.Errorf("Buffer takes 9 to 12 parameters, got %d", len(args))
+	}
+	engine := &BufferEngine{
+		Database: engineArgString(args[0]),
+		Table:    engineArgString(args[1]),
+	}
+	thresholds := []*int64{
+		&engine.NumLayers, &engine.MinTime, &engine.MaxTime, &engine.MinRows, &engine.MaxRows,
+		&engine.MinBytes, &engine.MaxBytes, &engine.FlushTime, &engine.FlushRows, &engine.FlushBytes,
+	}
+	for i, arg := range args[2:] {
+		number, ok := arg.(*NumberLiteral)
+		if !ok {
+			return nil, fmt.Errorf("Buffer parameter %d must be a number, got %q", i+3, Format(arg))
+		}
+		value, ok := number.Value().(int64)
+		if !ok {
+			return nil, fmt.Errorf("Buffer parameter %d must be an integer, got %s", i+3, number.Literal)
+		}
+		*thresholds[i] = value
+	}
+	return engine, nil
+}
+
+// engineArgString returns the text of an engine parameter: the value of a
+// string literal, the name of an identifier, and the SQL of anything else,
+// such as currentDatabase().
+func engineArgString(expr Expr) string {
+	switch e := expr.(type) {
+	case *StringLiteral:
+		return e.Value()
+	case *Ident:
+		return e.Name
+	}
+	return Format(expr)
+}
+
+// engineArgList returns the names in a parenthesized column list, or the
+// single name when the parameter is not a list.
+func engineArgList(expr Expr) []string {
+	list, ok := expr.(*ParamExprList)
+	if !ok || list.Items == nil {
+		return []string{engineArgString(expr)}
+	}
+	names := make([]string, len(list.Items.Items))
+	for i, item := range list.Items.Items {
+		names[i] = engineArgString(unwrapColumnExpr(item))
+	}
+	return names
+}
+
+// namedEngineArg unpacks a `name = value` engine parameter.
+func namedEngineArg(expr Expr) (name, value string, ok bool) {
+	binary, ok := expr.(*BinaryOperation)
+	if !ok || binary.Operator() != OperatorEquals {
+		return "", "", false
+	}
+	ident, ok := binary.LeftExpr.(*Ident)
+	if !ok {
+		return "", "", false
+	}
+	return ident.Name, engineArgString(binary.RightExpr), true
+}
+
+func isStringArg(expr Expr) bool {
+	_, ok := expr.(*StringLiteral)
+	return ok
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
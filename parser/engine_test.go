@@ -0,0 +1,166 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseEngine(t *testing.T, engine string) *EngineExpr {
+	t.Helper()
+	p := NewParser("CREATE TABLE t (a UInt64) ENGINE = " + engine)
+	stmts, err := p.ParseStmts()
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+	create, ok := stmts[0].(*CreateTable)
+	require.True(t, ok, "expected *CreateTable, got %T", stmts[0])
+	require.NotNil(t, create.Engine)
+	return create.Engine
+}
+
+func parseEngineModel(t *testing.T, engine string) EngineModel {
+	t.Helper()
+	model, err := parseEngine(t, engine).Model()
+	require.NoError(t, err)
+	return model
+}
+
+func TestMergeTreeEngineModel(t *testing.T) {
+	tests := []struct {
+		engine   string
+		expected *MergeTreeEngine
+	}{
+		{"MergeTree ORDER BY a", &MergeTreeEngine{Name: "MergeTree"}},
+		{"MergeTree() ORDER BY a", &MergeTreeEngine{Name: "MergeTree"}},
+		{"ReplacingMergeTree ORDER BY a", &MergeTreeEngine{Name: "ReplacingMergeTree", Variant: MergeTreeVariantReplacing}},
+		{
+			"ReplacingMergeTree(ver, is_deleted) ORDER BY a",
+			&MergeTreeEngine{Name: "ReplacingMergeTree", Variant: MergeTreeVariantReplacing, Version: "ver", IsDeleted: "is_deleted"},
+		},
+		{"CollapsingMergeTree(sign) ORDER BY a", &MergeTreeEngine{Name: "CollapsingMergeTree", Variant: MergeTreeVariantCollapsing, Sign: "sign"}},
+		{
+			"VersionedCollapsingMergeTree(sign, ver) ORDER BY a",
+			&MergeTreeEngine{Name: "VersionedCollapsingMergeTree", Variant: MergeTreeVariantVersionedCollapsing, Sign: "sign", Version: "ver"},
+		},
+		{"SummingMergeTree ORDER BY a", &MergeTreeEngine{Name: "SummingMergeTree", Variant: MergeTreeVariantSumming}},
+		{"SummingMergeTree(total) ORDER BY a", &MergeTreeEngine{Name: "SummingMergeTree", Variant: MergeTreeVariantSumming, SumColumns: []string{"total"}}},
+		{
+			"SummingMergeTree((total, hits)) ORDER BY a",
+			&MergeTreeEngine{Name: "SummingMergeTree", Variant: MergeTreeVariantSumming, SumColumns: []string{"total", "hits"}},
+		},
+		{"GraphiteMergeTree('graphite_rollup') ORDER BY a", &MergeTreeEngine{Name: "GraphiteMergeTree", Variant: MergeTreeVariantGraphite, GraphiteConfig: "graphite_rollup"}},
+		{
+			"ReplicatedReplacingMergeTree('/clickhouse/tables/{shard}/t', '{replica}', ver) ORDER BY a",
+			&MergeTreeEngine{
+				Name:        "ReplicatedReplacingMergeTree",
+				Variant:     MergeTreeVariantReplacing,
+				Replication: &ReplicationParams{ZooKeeperPath: "/clickhouse/tables/{shard}/t", ReplicaName: "{replica}"},
+				Version:     "ver",
+			},
+		},
+		{
+			"ReplicatedCollapsingMergeTree(sign) ORDER BY a",
+			&MergeTreeEngine{Name: "ReplicatedCollapsingMergeTree", Variant: MergeTreeVariantCollapsing, Replication: &ReplicationParams{}, Sign: "sign"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.engine, func(t *testing.T) {
+			require.Equal(t, tt.expected, parseEngineModel(t, tt.engine))
+		})
+	}
+}
+
+func TestReplicationParams(t *testing.T) {
+	model := parseEngineModel(t, "ReplicatedMergeTree('/clickhouse/tables/{layer}-{shard}/{database}/t', '{replica}') ORDER BY a")
+	replication := model.(*MergeTreeEngine).Replication
+	require.Equal(t, []string{"layer", "shard", "database", "replica"}, replication.Macros())
+	require.False(t, replication.UsesDefaults())
+
+	model = parseEngineModel(t, "ReplicatedMergeTree ORDER BY a")
+	replication = model.(*MergeTreeEngine).Replication
+	require.True(t, replication.UsesDefaults())
+	require.Empty(t, replication.Macros())
+}
+
+func TestDistributedEngineModel(t *testing.T) {
+	model := parseEngineModel(t, "Distributed('cluster', 'db', 'events_local', rand(), 'hot')")
+	distributed, ok := model.(*DistributedEngine)
+	require.True(t, ok)
+	require.Equal(t, "cluster", distributed.Cluster)
+	require.Equal(t, "db", distributed.Database)
+	require.Equal(t, "events_local", distributed.Table)
+	require.Equal(t, "rand()", Format(distributed.ShardingKey))
+	require.Equal(t, "hot", distributed.Policy)
+
+	model = parseEngineModel(t, "Distributed(cluster, currentDatabase(), events_local)")
+	distributed = model.(*DistributedEngine)
+	require.Equal(t, "cluster", distributed.Cluster)
+	require.Equal(t, "currentDatabase()", distributed.Database)
+	require.Nil(t, distributed.ShardingKey)
+}
+
+func TestKafkaEngineModel(t *testing.T) {
+	model := parseEngineModel(t, "Kafka('host1:9092,host2:9092', 'a,b', 'group', 'JSONEachRow')")
+	require.Equal(t, &KafkaEngine{BrokerList: "host1:9092,host2:9092", TopicList: "a,b", GroupName: "group", Format: "JSONEachRow"}, model)
+	kafka := model.(*KafkaEngine)
+	require.Equal(t, []string{"host1:9092", "host2:9092"}, kafka.Brokers())
+	require.Equal(t, []string{"a", "b"}, kafka.Topics())
+
+	model = parseEngineModel(t, "Kafka SETTINGS kafka_broker_list = 'host:9092', kafka_topic_list = 'events', kafka_group_name = 'g', kafka_format = 'CSV'")
+	require.Equal(t, &KafkaEngine{BrokerList: "host:9092", TopicList: "events", GroupName: "g", Format: "CSV"}, model)
+}
+
+func TestS3EngineModel(t *testing.T) {
+	tests := []struct {
+		engine   string
+		expected *S3Engine
+	}{
+		{"S3('https://bucket/data.csv')", &S3Engine{Path: "https://bucket/data.csv"}},
+		{"S3('https://bucket/data.csv', 'CSV')", &S3Engine{Path: "https://bucket/data.csv", Format: "CSV"}},
+		{"S3('https://bucket/data.csv', 'CSV', 'gzip')", &S3Engine{Path: "https://bucket/data.csv", Format: "CSV", Compression: "gzip"}},
+		{"S3('https://bucket/data.csv', 'key', 'secret')", &S3Engine{Path: "https://bucket/data.csv", AccessKeyID: "key", SecretAccessKey: "secret"}},
+		{
+			"S3('https://bucket/data.csv', 'key', 'secret', 'Parquet', 'zstd')",
+			&S3Engine{Path: "https://bucket/data.csv", AccessKeyID: "key", SecretAccessKey: "secret", Format: "Parquet", Compression: "zstd"},
+		},
+		{"S3('https://bucket/data.csv', NOSIGN, 'CSV')", &S3Engine{Path: "https://bucket/data.csv", NoSign: true, Format: "CSV"}},
+		{"S3(s3_conn, filename = 'data.csv', format = 'CSV')", &S3Engine{NamedCollection: "s3_conn", Path: "data.csv", Format: "CSV"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.engine, func(t *testing.T) {
+			require.Equal(t, tt.expected, parseEngineModel(t, tt.engine))
+		})
+	}
+}
+
+func TestBufferAndDictionaryEngineModels(t *testing.T) {
+	model := parseEngineModel(t, "Buffer(db, events, 16, 10, 100, 10000, 1000000, 10000000, 100000000)")
+	require.Equal(t, &BufferEngine{
+		Database: "db", Table: "events", NumLayers: 16, MinTime: 10, MaxTime: 100,
+		MinRows: 10000, MaxRows: 1000000, MinBytes: 10000000, MaxBytes: 100000000,
+	}, model)
+
+	model = parseEngineModel(t, "Dictionary(dict)")
+	require.Equal(t, &DictionaryEngine{Dictionary: "dict"}, model)
+
+	require.Nil(t, parseEngineModel(t, "Memory"))
+}
+
+func TestEngineModelErrors(t *testing.T) {
+	for _, engine := range []string{
+		"CollapsingMergeTree ORDER BY a",
+		"MergeTree(a) ORDER BY a",
+		"ReplacingMergeTree(a, b, c) ORDER BY a",
+		"Distributed(cluster, db)",
+		"Kafka('host:9092', 'events')",
+		"Buffer(db, events, 16)",
+		"Buffer(db, events, 16, 10, 100, 10000, 1000000, 10000000, x)",
+		"S3(conn, unknown = 1)",
+		"FooMergeTree ORDER BY a",
+	} {
+		t.Run(engine, func(t *testing.T) {
+			_, err := parseEngine(t, engine).Model()
+			require.Error(t, err)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 )
 
 // ParseError is a structured parse error. It carries the byte offset and the
@@ -13,7 +14,7 @@ import (
 type ParseError struct {
 	Pos      Pos         // byte offset where parsing stopped
 	Line     int         // 1-based line number
-	Column   int         // 1-based column number
+	Column   int         // 1-based column number, counted in runes
 	Got      *Token      // the token we choked on; nil at end of input
 	Expected []TokenKind // token kinds the grammar wanted here, if known
 	Keyword  string      // a specific keyword that was expected, if any
@@ -64,8 +65,8 @@ func (e *ParseError) renderCaret(b *strings.Builder) {
 		b.WriteByte(' ')
 	}
 	width := 1
-	if e.Got != nil && len(e.Got.String) > width {
-		width = len(e.Got.String)
+	if e.Got != nil && utf8.RuneCountInString(e.Got.String) > width {
+		width = utf8.RuneCountInString(e.Got.String)
 	}
 	b.WriteString(strings.Repeat("^", width))
 	b.WriteByte('\n')
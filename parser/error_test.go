@@ -47,3 +47,17 @@ func TestParseError_ExpectedTokenKind(t *testing.T) {
 	require.Equal(t, []TokenKind{TokenKindRParen}, pe.Expected)
 	require.True(t, strings.HasPrefix(pe.Error(), "line "))
 }
+
+func TestParseError_ColumnCountsRunes(t *testing.T) {
+	// "SELECT 名前 FROM " is 15 runes but 19 bytes; the error column must be the
+	// rune column an editor shows, not the byte offset.
+	_, err := NewParser("SELECT 名前 FROM 123").ParseStmts()
+	require.Error(t, err)
+
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe))
+	require.Equal(t, 1, pe.Line)
+	require.Equal(t, 16, pe.Column)
+	require.Equal(t, Pos(19), pe.Pos)
+	require.Contains(t, pe.Error(), "\n"+strings.Repeat(" ", 15)+"^^^\n")
+}
@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"strings"
+)
+
+// StringKind tells how a string literal was spelled in the source.
+type StringKind int
+
+const (
+	StringKindQuoted  StringKind = iota // 'text' with backslash and '' escapes
+	StringKindHeredoc                   // $tag$text$tag$, taken verbatim
+	StringKindHex                       // x'4142'
+	StringKindBinary                    // b'01000001'
+)
+
+// escapeSequences maps the character after a backslash to the byte it stands
+// for. Any other escaped character keeps its backslash, as in ClickHouse, so
+// LIKE patterns and regular expressions such as '\d+' reach the server intact.
+var escapeSequences = map[byte]byte{
+	'a':  '\a',
+	'b':  '\b',
+	'e':  0x1b,
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'v':  '\v',
+	'0':  0,
+	'\\': '\\',
+	'\'': '\'',
+	'"':  '"',
+	'`':  '`',
+	'/':  '/',
+	'=':  '=',
+}
+
+// unescape decodes the text between the quotes of a string literal or quoted
+// identifier: backslash escapes, \xHH bytes and a doubled quote character.
+func unescape(raw string, quote byte) string {
+	if strings.IndexByte(raw, '\\') < 0 && strings.IndexByte(raw, quote) < 0 {
+		return raw
+	}
+	var b strings.Builder
+	b.Grow(len(raw))
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == quote && i+1 < len(raw) && raw[i+1] == quote:
+			b.WriteByte(quote)
+			i++
+		case c == '\\' && i+1 < len(raw):
+			i++
+			next := raw[i]
+			if (next == 'x' || next == 'X') && i+2 < len(raw) && IsHexDigit(raw[i+1]) && IsHexDigit(raw[i+2]) {
+				b.WriteByte(hexValue(raw[i+1])<<4 | hexValue(raw[i+2]))
+				i += 2
+			} else if decoded, ok := escapeSequences[next]; ok {
+				b.WriteByte(decoded)
+			} else {
+				b.WriteByte('\\')
+				b.WriteByte(next)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// escape is the inverse of unescape. It escapes the quote character,
+// backslashes and control characters the way ClickHouse formats literals.
+func escape(value string, quote byte) string {
+	var b strings.Builder
+	b.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == quote, c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c == 0:
+			b.WriteString(`\0`)
+		case c < 0x20 || c == 0x7f:
+			const digits = "0123456789ABCDEF"
+			b.WriteString(`\x`)
+			b.WriteByte(digits[c>>4])
+			b.WriteByte(digits[c&0xf])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// QuoteString returns value as a single-quoted ClickHouse string literal.
+func QuoteString(value string) string {
+	return "'" + escape(value, '\'') + "'"
+}
+
+// decodeString returns the value of a string literal body of the given kind.
+// Hex and binary bodies have already been validated by the lexer; a body whose
+// length is not a whole number of bytes is padded with leading zeros.
+func decodeString(kind StringKind, literal string) string {
+	switch kind {
+	case StringKindHeredoc:
+		return literal
+	case StringKindHex:
+		if len(literal)%2 != 0 {
+			literal = "0" + literal
+		}
+		buf := make([]byte, len(literal)/2)
+		for i := range buf {
+			buf[i] = hexValue(literal[2*i])<<4 | hexValue(literal[2*i+1])
+		}
+		return string(buf)
+	case StringKindBinary:
+		if pad := len(literal) % 8; pad != 0 {
+			literal = strings.Repeat("0", 8-pad) + literal
+		}
+		buf := make([]byte, len(literal)/8)
+		for i := range buf {
+			for _, bit := range []byte(literal[8*i : 8*i+8]) {
+				buf[i] = buf[i]<<1 | (bit - '0')
+			}
+		}
+		return string(buf)
+	default:
+		return unescape(literal, '\'')
+	}
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
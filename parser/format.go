@@ -677,21 +677,47 @@ func (c *ColumnDef) FormatSQL(formatter *Formatter) {
 		formatter.WriteString(" MATERIALIZED ")
 		formatter.WriteExpr(c.MaterializedExpr)
 	}
+	if c.Ephemeral {
+		formatter.WriteString(" EPHEMERAL")
+		if c.EphemeralExpr != nil {
+			formatter.WriteByte(whitespace)
+			formatter.WriteExpr(c.EphemeralExpr)
+		}
+	}
 	if c.AliasExpr != nil {
 		formatter.WriteString(" ALIAS ")
 		formatter.WriteExpr(c.AliasExpr)
 	}
+	// COMMENT precedes CODEC, the order the parser (and SHOW CREATE TABLE)
+	// uses, so the output parses back.
+	if c.Comment != nil {
+		formatter.WriteString(" COMMENT ")
+		formatter.WriteExpr(c.Comment)
+	}
 	if c.Codec != nil {
 		formatter.WriteByte(whitespace)
 		formatter.WriteExpr(c.Codec)
 	}
+	if c.Statistics != nil {
+		formatter.WriteByte(whitespace)
+		formatter.WriteExpr(c.Statistics)
+	}
 	if c.TTL != nil {
 		formatter.WriteByte(whitespace)
 		formatter.WriteExpr(c.TTL)
 	}
-	if c.Comment != nil {
-		formatter.WriteString(" COMMENT ")
-		formatter.WriteExpr(c.Comment)
+	if c.PrimaryKey {
+		formatter.WriteString(" PRIMARY KEY")
+	}
+	if c.Settings != nil {
+		formatter.WriteString(" SETTINGS (")
+		for i, item := range c.Settings.Items {
+			if i > 0 {
+				formatter.WriteString(", ")
+			}
+			formatter.WriteExpr(item)
+		}
+		formatter.WriteByte(')')
 	}
 }
 
@@ -793,7 +819,11 @@ func (c *CreateDatabase) FormatSQL(formatter *Formatter) {
 }
 
 func (c *CreateDictionary) FormatSQL(formatter *Formatter) {
-	formatter.WriteString("CREATE ")
+	if c.IsAttach {
+		formatter.WriteString("ATTACH ")
+	} else {
+		formatter.WriteString("CREATE ")
+	}
 	if c.OrReplace {
 		formatter.WriteString("OR REPLACE ")
 	}
@@ -1028,7 +1058,14 @@ func (c *CreateRole) FormatSQL(formatter *Formatter) {
 }
 
 func (c *CreateTable) FormatSQL(formatter *Formatter) {
-	formatter.WriteString("CREATE")
+	switch {
+	case c.IsAttach:
+		formatter.WriteString("ATTACH")
+	case c.IsReplace:
+		formatter.WriteString("REPLACE")
+	default:
+		formatter.WriteString("CREATE")
+	}
 	if c.OrReplace {
 		formatter.WriteString(" OR REPLACE")
 	}
@@ -1040,6 +1077,10 @@ func (c *CreateTable) FormatSQL(formatter *Formatter) {
 		formatter.WriteString("IF NOT EXISTS ")
 	}
 	formatter.WriteExpr(c.Name)
+	if c.FromPath != nil {
+		formatter.WriteString(" FROM ")
+		formatter.WriteExpr(c.FromPath)
+	}
 	if c.UUID != nil {
 		formatter.WriteByte(whitespace)
 		formatter.WriteExpr(c.UUID)
@@ -1048,6 +1089,14 @@ func (c *CreateTable) FormatSQL(formatter *Formatter) {
 		formatter.WriteByte(whitespace)
 		formatter.WriteExpr(c.OnCluster)
 	}
+	if c.SourceTable != nil {
+		formatter.Break()
+		if c.HasClone {
+			formatter.WriteString("CLONE ")
+		}
+		formatter.WriteString("AS ")
+		formatter.WriteExpr(c.SourceTable)
+	}
 
 	if c.TableSchema != nil {
 		formatter.Break()
@@ -1059,6 +1108,9 @@ func (c *CreateTable) FormatSQL(formatter *Formatter) {
 	}
 	if c.SubQuery != nil {
 		formatter.Break()
+		if c.HasEmpty {
+			formatter.WriteString("EMPTY ")
+		}
 		formatter.WriteString("AS ")
 		formatter.WriteExpr(c.SubQuery)
 	}
@@ -1219,6 +1271,10 @@ func (d *DescribeStmt) FormatSQL(formatter *Formatter) {
 		formatter.WriteByte(whitespace)
 	}
 	formatter.WriteExpr(d.Target)
+	if d.Settings != nil {
+		formatter.Break()
+		formatter.WriteExpr(d.Settings)
+	}
 }
 
 func (d *DestinationClause) FormatSQL(formatter *Formatter) {
@@ -1398,7 +1454,11 @@ func (s *DistinctOn) FormatSQL(formatter *Formatter) {
 }
 
 func (d *DropDatabase) FormatSQL(formatter *Formatter) {
-	formatter.WriteString("DROP DATABASE ")
+	if d.IsDetach {
+		formatter.WriteString("DETACH DATABASE ")
+	} else {
+		formatter.WriteString("DROP DATABASE ")
+	}
 	if d.IfExists {
 		formatter.WriteString("IF EXISTS ")
 	}
@@ -1407,13 +1467,20 @@ func (d *DropDatabase) FormatSQL(formatter *Formatter) {
 		formatter.WriteByte(whitespace)
 		formatter.WriteExpr(d.OnCluster)
 	}
+	if d.Permanently {
+		formatter.WriteString(" PERMANENTLY")
+	}
 	if len(d.Modifier) != 0 {
 		formatter.WriteString(" " + d.Modifier)
 	}
 }
 
 func (d *DropStmt) FormatSQL(formatter *Formatter) {
-	formatter.WriteString("DROP ")
+	if d.IsDetach {
+		formatter.WriteString("DETACH ")
+	} else {
+		formatter.WriteString("DROP ")
+	}
 	if d.IsTemporary {
 		formatter.WriteString("TEMPORARY ")
 	}
@@ -1426,6 +1493,9 @@ func (d *DropStmt) FormatSQL(formatter *Formatter) {
 		formatter.WriteByte(whitespace)
 		formatter.WriteExpr(d.OnCluster)
 	}
+	if d.Permanently {
+		formatter.WriteString(" PERMANENTLY")
+	}
 	if len(d.Modifier) != 0 {
 		formatter.WriteString(" " + d.Modifier)
 	}
@@ -1504,8 +1574,19 @@ func (e *EnumValue) FormatSQL(formatter *Formatter) {
 
 func (e *ExplainStmt) FormatSQL(formatter *Formatter) {
 	formatter.WriteString("EXPLAIN ")
-	formatter.WriteString(e.Type)
-	formatter.WriteByte(whitespace)
+	if e.Type != "" {
+		formatter.WriteString(e.Type)
+		formatter.WriteByte(whitespace)
+	}
+	for i, setting := range e.Settings {
+		if i > 0 {
+			formatter.WriteString(", ")
+		}
+		formatter.WriteExpr(setting)
+	}
+	if len(e.Settings) > 0 {
+		formatter.WriteByte(whitespace)
+	}
 	formatter.WriteExpr(e.Statement)
 }
 
@@ -1540,6 +1621,26 @@ func (f *Fill) FormatSQL(formatter *Formatter) {
 	}
 }
 
+func (i *IntoOutfileClause) FormatSQL(formatter *Formatter) {
+	formatter.WriteString("INTO OUTFILE ")
+	formatter.WriteExpr(i.FileName)
+	if i.AndStdout {
+		formatter.WriteString(" AND STDOUT")
+	}
+	if i.Mode != OutfileModeNone {
+		formatter.WriteByte(whitespace)
+		formatter.WriteString(string(i.Mode))
+	}
+	if i.Compression != nil {
+		formatter.WriteString(" COMPRESSION ")
+		formatter.WriteExpr(i.Compression)
+		if i.CompressionLevel != nil {
+			formatter.WriteString(" LEVEL ")
+			formatter.WriteExpr(i.CompressionLevel)
+		}
+	}
+}
+
 func (f *FormatClause) FormatSQL(formatter *Formatter) {
 	formatter.WriteString("FORMAT ")
 	formatter.WriteExpr(f.Format)
@@ -1663,16 +1764,14 @@ func (i *Ident) FormatSQL(formatter *Formatter) {
 	switch i.QuoteType {
 	case BackTicks:
 		formatter.WriteByte('`')
-		formatter.WriteString(i.Name)
+		formatter.WriteString(escape(i.Name, '`'))
 		formatter.WriteByte('`')
 	case DoubleQuote:
 		formatter.WriteByte('"')
-		formatter.WriteString(i.Name)
+		formatter.WriteString(escape(i.Name, '"'))
 		formatter.WriteByte('"')
 	case SingleQuote:
-		formatter.WriteByte('\'')
-		formatter.WriteString(i.Name)
-		formatter.WriteByte('\'')
+		formatter.WriteString(QuoteString(i.Name))
 	default:
 		formatter.WriteString(i.Name)
 	}
@@ -1750,6 +1849,11 @@ func (i *IntervalExpr) FormatSQL(formatter *Formatter) {
 	formatter.WriteExpr(i.Unit)
 }
 
+func (i *IntervalLiteral) FormatSQL(formatter *Formatter) {
+	formatter.WriteString("INTERVAL ")
+	formatter.WriteExpr(i.Value)
+}
+
 func (i *IntervalFrom) FormatSQL(formatter *Formatter) {
 	formatter.WriteExpr(i.Interval)
 	formatter.WriteString(" FROM ")
@@ -1884,12 +1988,27 @@ func writeJoinSQL(formatter *Formatter, expr Expr) {
 		return
 	}
 
-	if len(joinExpr.Modifiers) == 0 {
+	if joinExpr.Kind == JoinKindNone {
 		formatter.WriteByte(',')
 		formatter.WriteExpr(joinExpr.Left)
 	} else {
 		formatter.Break()
-		formatter.WriteString(strings.Join(joinExpr.Modifiers, " "))
+		if joinExpr.Locality != JoinLocalityNone {
+			formatter.WriteString(string(joinExpr.Locality))
+			formatter.WriteByte(whitespace)
+		}
+		if joinExpr.Strictness != JoinStrictnessNone {
+			formatter.WriteString(string(joinExpr.Strictness))
+			formatter.WriteByte(whitespace)
+		}
+		if !joinExpr.ImplicitKind {
+			formatter.WriteString(string(joinExpr.Kind))
+			if joinExpr.HasOuter {
+				formatter.WriteString(" OUTER")
+			}
+			formatter.WriteByte(whitespace)
+		}
+		formatter.WriteString("JOIN")
 		formatter.Indent()
 		formatter.Break()
 		formatter.WriteExpr(joinExpr.Left)
@@ -1915,6 +2034,46 @@ func (j *JoinTableExpr) FormatSQL(formatter *Formatter) {
 	}
 }
 
+func (q *QuantifiedComparisonExpr) FormatSQL(formatter *Formatter) {
+	formatter.WriteExpr(q.LeftExpr)
+	formatter.WriteByte(whitespace)
+	formatter.WriteString(string(q.Operation))
+	formatter.WriteByte(whitespace)
+	formatter.WriteString(string(q.Quantifier))
+	formatter.WriteByte(whitespace)
+	formatter.WriteExpr(q.SubQuery)
+}
+
+func (d *DistinctFromExpr) FormatSQL(formatter *Formatter) {
+	formatter.WriteExpr(d.LeftExpr)
+	switch {
+	case d.Shorthand:
+		formatter.WriteString(" <=> ")
+	case d.HasNot:
+		formatter.WriteString(" IS NOT DISTINCT FROM ")
+	default:
+		formatter.WriteString(" IS DISTINCT FROM ")
+	}
+	formatter.WriteExpr(d.RightExpr)
+}
+
+func (l *LambdaExpr) FormatSQL(formatter *Formatter) {
+	if len(l.Params) == 1 {
+		formatter.WriteExpr(l.Params[0])
+	} else {
+		formatter.WriteByte('(')
+		for i, param := range l.Params {
+			if i > 0 {
+				formatter.WriteString(", ")
+			}
+			formatter.WriteExpr(param)
+		}
+		formatter.WriteByte(')')
+	}
+	formatter.WriteString(" -> ")
+	formatter.WriteExpr(l.Body)
+}
+
 func (l *LimitByClause) FormatSQL(formatter *Formatter) {
 	if l.Limit != nil {
 		formatter.WriteExpr(l.Limit)
@@ -1926,6 +2085,21 @@ func (l *LimitByClause) FormatSQL(formatter *Formatter) {
 }
 
 func (l *LimitClause) FormatSQL(formatter *Formatter) {
+	if l.Fetch {
+		if l.Offset != nil {
+			formatter.WriteString("OFFSET ")
+			formatter.WriteExpr(l.Offset)
+			formatter.WriteString(" ROWS ")
+		}
+		formatter.WriteString("FETCH FIRST ")
+		formatter.WriteExpr(l.Limit)
+		if l.WithTies {
+			formatter.WriteString(" ROWS WITH TIES")
+		} else {
+			formatter.WriteString(" ROWS ONLY")
+		}
+		return
+	}
 	if l.Limit != nil {
 		formatter.WriteString("LIMIT ")
 		formatter.WriteExpr(l.Limit)
@@ -1937,6 +2111,9 @@ func (l *LimitClause) FormatSQL(formatter *Formatter) {
 		formatter.WriteString("OFFSET ")
 		formatter.WriteExpr(l.Offset)
 	}
+	if l.WithTies {
+		formatter.WriteString(" WITH TIES")
+	}
 }
 
 func (m *MapLiteral) FormatSQL(formatter *Formatter) {
@@ -2298,6 +2475,10 @@ func (s *SelectQuery) FormatSQL(formatter *Formatter) {
 			formatter.Break()
 			formatter.WriteExpr(s.Settings)
 		}
+		if s.IntoOutfile != nil {
+			formatter.Break()
+			formatter.WriteExpr(s.IntoOutfile)
+		}
 		if s.Format != nil {
 			formatter.Break()
 			formatter.WriteExpr(s.Format)
@@ -2383,6 +2564,10 @@ func (s *SelectQuery) FormatSQL(formatter *Formatter) {
 		formatter.Break()
 		formatter.WriteExpr(s.Settings)
 	}
+	if s.IntoOutfile != nil {
+		formatter.Break()
+		formatter.WriteExpr(s.IntoOutfile)
+	}
 	if s.Format != nil {
 		formatter.Break()
 		formatter.WriteExpr(s.Format)
@@ -2494,10 +2679,36 @@ func (s *ShowStmt) FormatSQL(formatter *Formatter) {
 
 }
 
+func (s *StatisticsClause) FormatSQL(formatter *Formatter) {
+	formatter.WriteString("STATISTICS(")
+	for i, t := range s.Types {
+		if i > 0 {
+			formatter.WriteString(", ")
+		}
+		formatter.WriteExpr(t)
+	}
+	formatter.WriteByte(')')
+}
+
+func (t *TypedLiteral) FormatSQL(formatter *Formatter) {
+	formatter.WriteString(strings.ToUpper(t.Type.Name))
+	formatter.WriteByte(whitespace)
+	formatter.WriteExpr(t.Value)
+}
+
 func (s *StringLiteral) FormatSQL(formatter *Formatter) {
-	formatter.WriteByte('\'')
-	formatter.WriteString(s.Literal)
-	formatter.WriteByte('\'')
+	switch s.Kind {
+	case StringKindHeredoc:
+		formatter.WriteString("$" + s.HeredocTag + "$")
+		formatter.WriteString(s.Literal)
+		formatter.WriteString("$" + s.HeredocTag + "$")
+	case StringKindHex:
+		formatter.WriteString("x'" + s.Literal + "'")
+	case StringKindBinary:
+		formatter.WriteString("b'" + s.Literal + "'")
+	default:
+		formatter.WriteString(QuoteString(s.Value()))
+	}
 }
 
 func (s *SubQuery) FormatSQL(formatter *Formatter) {
@@ -0,0 +1,873 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FunctionKind tells where a function may be used.
+type FunctionKind string
+
+const (
+	FunctionKindScalar    FunctionKind = "SCALAR"
+	FunctionKindAggregate FunctionKind = "AGGREGATE"
+	// FunctionKindWindow is a function that is only valid with OVER, such
+	// as row_number. Aggregates may be used with OVER too.
+	FunctionKindWindow FunctionKind = "WINDOW"
+	FunctionKindTable  FunctionKind = "TABLE"
+)
+
+// VariadicArgs is the MaxArgs of functions that take any number of
+// arguments.
+const VariadicArgs = -1
+
+// FunctionInfo describes a function of a FunctionRegistry.
+type FunctionInfo struct {
+	Name string
+	Kind FunctionKind
+	// MinArgs and MaxArgs bound the number of arguments. MaxArgs is
+	// VariadicArgs when there is no upper bound.
+	MinArgs int
+	MaxArgs int
+	// MinParams and MaxParams bound the number of parameters of a
+	// parametric aggregate, such as the 0.9 of quantile(0.9)(x). Both are 0
+	// for functions that take no parameters.
+	MinParams int
+	MaxParams int
+	// CaseInsensitive is set for the functions ClickHouse matches in any
+	// case, such as COUNT or SUBSTRING.
+	CaseInsensitive bool
+}
+
+// AcceptsArgs reports whether the function can be called with n arguments.
+func (f *FunctionInfo) AcceptsArgs(n int) bool {
+	return n >= f.MinArgs && (f.MaxArgs == VariadicArgs || n <= f.MaxArgs)
+}
+
+// AcceptsParams reports whether the function can be given n parameters.
+func (f *FunctionInfo) AcceptsParams(n int) bool {
+	return n >= f.MinParams && (f.MaxParams == VariadicArgs || n <= f.MaxParams)
+}
+
+// FunctionRegistry holds the functions known to a validator. Table
+// functions have their own namespace, as format or file name both a table
+// function and a scalar function.
+type FunctionRegistry struct {
+	functions map[string]*FunctionInfo
+	// lower indexes the case-insensitive functions by lower-cased name.
+	lower  map[string]*FunctionInfo
+	tables map[string]*FunctionInfo
+}
+
+// NewFunctionRegistry returns a registry holding the common ClickHouse
+// built-in functions. Register adds user-defined ones.
+func NewFunctionRegistry() *FunctionRegistry {
+	r := &FunctionRegistry{
+		functions: make(map[string]*FunctionInfo, len(builtinFunctions)),
+		lower:     make(map[string]*FunctionInfo),
+		tables:    make(map[string]*FunctionInfo),
+	}
+	for _, info := range builtinFunctions {
+		r.Register(info)
+	}
+	return r
+}
+
+// Register adds a function, replacing any function of the same name.
+func (r *FunctionRegistry) Register(info FunctionInfo) {
+	if info.Kind == FunctionKindTable {
+		r.tables[info.Name] = &info
+		return
+	}
+	r.functions[info.Name] = &info
+	if info.CaseInsensitive {
+		r.lower[strings.ToLower(info.Name)] = &info
+	}
+}
+
+// Functions returns the registered functions sorted by name.
+func (r *FunctionRegistry) Functions() []*FunctionInfo {
+	functions := make([]*FunctionInfo, 0, len(r.functions)+len(r.tables))
+	for _, info := range r.functions {
+		functions = append(functions, info)
+	}
+	for _, info := range r.tables {
+		functions = append(functions, info)
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		if functions[i].Name != functions[j].Name {
+			return functions[i].Name < functions[j].Name
+		}
+		return functions[i].Kind < functions[j].Kind
+	})
+	return functions
+}
+
+// LookupTable finds a table function by name.
+func (r *FunctionRegistry) LookupTable(name string) (*FunctionInfo, bool) {
+	info, ok := r.tables[name]
+	return info, ok
+}
+
+// Lookup finds a function other than a table function by name. Aggregate
+// functions with combinator suffixes, such as sumIf, uniqState,
+// avgMergeState or groupArrayOrNull, are derived from the aggregate they
+// adapt.
+func (r *FunctionRegistry) Lookup(name string) (*FunctionInfo, bool) {
+	if info, ok := r.functions[name]; ok {
+		return info, true
+	}
+	if info, ok := r.lower[strings.ToLower(name)]; ok {
+		return info, true
+	}
+	for _, combinator := range aggregateCombinators {
+		base, ok := strings.CutSuffix(name, combinator)
+		if !ok || base == "" {
+			continue
+		}
+		info, ok := r.Lookup(base)
+		if !ok || info.Kind != FunctionKindAggregate {
+			continue
+		}
+		combined := *info
+		combined.Name = name
+		switch combinator {
+		case "If":
+			combined.MinArgs++
+			if combined.MaxArgs != VariadicArgs {
+				combined.MaxArgs++
+			}
+		case "Merge":
+			// the argument is the intermediate state
+			combined.MinArgs, combined.MaxArgs = 1, 1
+		}
+		return &combined, true
+	}
+	return nil, false
+}
+
+// ValidateFunctions checks the function calls of a statement against a
+// registry. It reports unknown functions and table functions, calls with
+// the wrong number of arguments or parameters, aggregate and window
+// functions in WHERE, PREWHERE and GROUP BY, window functions without OVER,
+// and OVER after a scalar function. registry nil means
+// NewFunctionRegistry().
+func ValidateFunctions(stmt Expr, registry *FunctionRegistry) []Diagnostic {
+	if stmt == nil {
+		return nil
+	}
+	if registry == nil {
+		registry = NewFunctionRegistry()
+	}
+	v := &functionValidator{
+		validator: &validator{reported: make(map[Diagnostic]bool)},
+		registry:  registry,
+		windowed:  make(map[*FunctionExpr]bool),
+		skipped:   make(map[*FunctionExpr]bool),
+		nested:    make(map[*TableFunctionExpr]bool),
+	}
+	Walk(stmt, v.visit)
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		return v.diagnostics[i].Pos < v.diagnostics[j].Pos
+	})
+	return v.diagnostics
+}
+
+type functionValidator struct {
+	*validator
+	registry *FunctionRegistry
+	// windowed holds the functions called with OVER.
+	windowed map[*FunctionExpr]bool
+	// skipped holds the FunctionExprs that are not function calls, such as
+	// the EXCEPT and REPLACE modifiers of *.
+	skipped map[*FunctionExpr]bool
+	// nested holds the calls in the arguments of table functions.
+	nested map[*TableFunctionExpr]bool
+}
+
+func (v *functionValidator) visit(node Expr) bool {
+	switch n := node.(type) {
+	case *SelectItem:
+		for _, modifier := range n.Modifiers {
+			v.skipped[modifier] = true
+		}
+	case *WindowFunctionExpr:
+		v.windowed[n.Function] = true
+	case *SelectQuery:
+		if n.Prewhere != nil {
+			v.checkNoAggregates(n.Prewhere.Expr, "PREWHERE")
+		}
+		if n.Where != nil {
+			v.checkNoAggregates(n.Where.Expr, "WHERE")
+		}
+		if n.GroupBy != nil {
+			v.checkNoAggregates(n.GroupBy.Expr, "GROUP BY")
+		}
+	case *InsertStmt:
+		// INSERT INTO FUNCTION file(...)
+		if function, ok := n.Table.(*FunctionExpr); ok {
+			v.skipped[function] = true
+			_, args := functionArity(function)
+			v.checkTableFunction(function, function.Name, args)
+		}
+	case *TableFunctionExpr:
+		name, ok := n.Name.(*Ident)
+		if !ok {
+			break
+		}
+		var args int
+		if n.Args != nil {
+			args = len(n.Args.Args)
+			// the calls in the arguments of a table function are parsed as
+			// table functions too, as in cluster('c', numbers(now()))
+			for _, arg := range n.Args.Args {
+				Walk(arg, func(node Expr) bool {
+					if call, ok := node.(*TableFunctionExpr); ok {
+						v.nested[call] = true
+					}
+					return true
+				})
+			}
+		}
+		if _, ok := v.registry.LookupTable(name.Name); !ok && v.nested[n] {
+			v.checkCall(n, name, 0, args, false)
+			break
+		}
+		v.checkTableFunction(n, name, args)
+	case *FunctionExpr:
+		if !v.skipped[n] {
+			v.checkFunction(n)
+		}
+	}
+	return true
+}
+
+func (v *functionValidator) checkFunction(f *FunctionExpr) {
+	params, args := functionArity(f)
+	v.checkCall(f, f.Name, params, args, v.windowed[f])
+}
+
+func (v *functionValidator) checkCall(node Expr, ident *Ident, params, args int, windowed bool) {
+	name := ident.Name
+	info, ok := v.registry.Lookup(name)
+	if !ok {
+		v.report(DiagnosticUnknownFunction, ident, "unknown function %s", name)
+		return
+	}
+	if !info.AcceptsParams(params) {
+		if info.MaxParams == 0 {
+			v.report(DiagnosticArgumentCountMismatch, node, "function %s takes no parameters", name)
+		} else {
+			v.report(DiagnosticArgumentCountMismatch, node, "function %s takes %s parameters, got %d", name, arityRange(info.MinParams, info.MaxParams), params)
+		}
+	}
+	if !info.AcceptsArgs(args) {
+		v.report(DiagnosticArgumentCountMismatch, node, "function %s takes %s arguments, got %d", name, arityRange(info.MinArgs, info.MaxArgs), args)
+	}
+	switch {
+	case info.Kind == FunctionKindWindow && !windowed:
+		v.report(DiagnosticIllegalWindowFunction, node, "window function %s requires OVER", name)
+	case info.Kind == FunctionKindScalar && windowed:
+		v.report(DiagnosticIllegalWindowFunction, node, "function %s is not an aggregate or window function and cannot be used with OVER", name)
+	}
+}
+
+func (v *functionValidator) checkTableFunction(node Expr, ident *Ident, args int) {
+	name := ident.Name
+	info, ok := v.registry.LookupTable(name)
+	if !ok {
+		v.report(DiagnosticUnknownTableFunction, ident, "unknown table function %s", name)
+		return
+	}
+	if !info.AcceptsArgs(args) {
+		v.report(DiagnosticArgumentCountMismatch, node, "table function %s takes %s arguments, got %d", name, arityRange(info.MinArgs, info.MaxArgs), args)
+	}
+}
+
+// checkNoAggregates reports the aggregate and window functions of a clause,
+// outside of its subqueries.
+func (v *functionValidator) checkNoAggregates(expr Expr, clause string) {
+	collector := &aggregateCollector{registry: v.registry}
+	_ = expr.Accept(collector)
+	for _, call := range collector.calls {
+		switch c := call.(type) {
+		case *WindowFunctionExpr:
+			v.report(DiagnosticIllegalAggregation, c, "window function %s is not allowed in %s", c.Function.Name.Name, clause)
+		case *FunctionExpr:
+			v.report(DiagnosticIllegalAggregation, c, "aggregate function %s is not allowed in %s", c.Name.Name, clause)
+		}
+	}
+}
+
+// aggregateCollector gathers the aggregate and window function calls of an
+// expression, leaving out subqueries and the arguments of the calls found.
+type aggregateCollector struct {
+	DefaultASTVisitor
+	registry *FunctionRegistry
+	calls    []Expr
+	// depth counts the enclosing subtrees that are left out.
+	depth int
+}
+
+func (c *aggregateCollector) Enter(expr Expr) {
+	if c.depth > 0 {
+		c.depth++
+		return
+	}
+	switch e := expr.(type) {
+	case *SelectQuery:
+		c.depth++
+	case *WindowFunctionExpr:
+		c.calls = append(c.calls, e)
+		c.depth++
+	case *FunctionExpr:
+		if info, ok := c.registry.Lookup(e.Name.Name); ok && info.Kind == FunctionKindAggregate {
+			c.calls = append(c.calls, e)
+			c.depth++
+		}
+	}
+}
+
+func (c *aggregateCollector) Leave(expr Expr) {
+	if c.depth > 0 {
+		c.depth--
+	}
+}
+
+// functionArity returns the number of parameters and arguments of a call.
+func functionArity(f *FunctionExpr) (params, args int) {
+	if f.Params == nil {
+		return 0, 0
+	}
+	if f.Params.ColumnArgList != nil {
+		if f.Params.Items != nil {
+			params = len(f.Params.Items.Items)
+		}
+		return params, len(f.Params.ColumnArgList.Items)
+	}
+	if f.Params.Items == nil {
+		return 0, 0
+	}
+	for _, item := range f.Params.Items.Items {
+		args += keywordArgCount(item)
+	}
+	// position(needle IN haystack)
+	switch strings.ToLower(f.Name.Name) {
+	case "position", "locate":
+		if in, ok := unwrapColumnExpr(f.Params.Items.Items[0]).(*BinaryOperation); ok && args == 1 && strings.EqualFold(string(in.Operation), KeywordIn) {
+			args = 2
+		}
+	}
+	return 0, args
+}
+
+// keywordArgCount counts the arguments of an item written with separator
+// keywords, such as substring(s FROM 2 FOR 3) or trim(BOTH ' ' FROM s).
+func keywordArgCount(item Expr) int {
+	switch e := unwrapColumnExpr(item).(type) {
+	case *BinaryOperation:
+		switch strings.ToUpper(string(e.Operation)) {
+		case KeywordFrom, KeywordFor, KeywordPlacing:
+			return keywordArgCount(e.LeftExpr) + 1
+		}
+	case *UnaryExpr:
+		switch strings.ToUpper(string(e.Kind)) {
+		case KeywordBoth, KeywordLeading, KeywordTrailing:
+			return keywordArgCount(e.Expr)
+		}
+	}
+	return 1
+}
+
+func arityRange(minimum, maximum int) string {
+	switch {
+	case maximum == VariadicArgs:
+		return fmt.Sprintf("at least %d", minimum)
+	case minimum == maximum:
+		return strconv.Itoa(minimum)
+	}
+	return fmt.Sprintf("%d to %d", minimum, maximum)
+}
+
+func scalarFunction(name string, minArgs, maxArgs int) FunctionInfo {
+	return FunctionInfo{Name: name, Kind: FunctionKindScalar, MinArgs: minArgs, MaxArgs: maxArgs}
+}
+
+func aggregateFunction(name string, minArgs, maxArgs int) FunctionInfo {
+	return FunctionInfo{Name: name, Kind: FunctionKindAggregate, MinArgs: minArgs, MaxArgs: maxArgs}
+}
+
+func parametricFunction(name string, minParams, maxParams, minArgs, maxArgs int) FunctionInfo {
+	info := aggregateFunction(name, minArgs, maxArgs)
+	info.MinParams, info.MaxParams = minParams, maxParams
+	return info
+}
+
+func windowFunction(name string, minArgs, maxArgs int) FunctionInfo {
+	return FunctionInfo{Name: name, Kind: FunctionKindWindow, MinArgs: minArgs, MaxArgs: maxArgs}
+}
+
+func tableFunction(name string, minArgs, maxArgs int) FunctionInfo {
+	return FunctionInfo{Name: name, Kind: FunctionKindTable, MinArgs: minArgs, MaxArgs: maxArgs}
+}
+
+func caseInsensitive(info FunctionInfo) FunctionInfo {
+	info.CaseInsensitive = true
+	return info
+}
+
+var builtinFunctions = func() []FunctionInfo {
+	const n = VariadicArgs
+	functions := []FunctionInfo{
+		// arithmetic and operators
+		scalarFunction("plus", 2, 2),
+		scalarFunction("minus", 2, 2),
+		scalarFunction("multiply", 2, 2),
+		scalarFunction("divide", 2, 2),
+		scalarFunction("intDiv", 2, 2),
+		scalarFunction("intDivOrZero", 2, 2),
+		scalarFunction("modulo", 2, 2),
+		caseInsensitive(scalarFunction("mod", 2, 2)),
+		scalarFunction("negate", 1, 1),
+		caseInsensitive(scalarFunction("abs", 1, 1)),
+		scalarFunction("gcd", 2, 2),
+		scalarFunction("lcm", 2, 2),
+		caseInsensitive(scalarFunction("round", 1, 2)),
+		scalarFunction("roundBankers", 1, 2),
+		caseInsensitive(scalarFunction("floor", 1, 2)),
+		caseInsensitive(scalarFunction("ceil", 1, 2)),
+		caseInsensitive(scalarFunction("ceiling", 1, 2)),
+		caseInsensitive(scalarFunction("truncate", 1, 2)),
+		caseInsensitive(scalarFunction("sqrt", 1, 1)),
+		scalarFunction("cbrt", 1, 1),
+		caseInsensitive(scalarFunction("exp", 1, 1)),
+		caseInsensitive(scalarFunction("log", 1, 1)),
+		caseInsensitive(scalarFunction("ln", 1, 1)),
+		caseInsensitive(scalarFunction("log2", 1, 1)),
+		caseInsensitive(scalarFunction("log10", 1, 1)),
+		caseInsensitive(scalarFunction("pow", 2, 2)),
+		caseInsensitive(scalarFunction("power", 2, 2)),
+		caseInsensitive(scalarFunction("sign", 1, 1)),
+		caseInsensitive(scalarFunction("pi", 0, 0)),
+		scalarFunction("e", 0, 0),
+		scalarFunction("equals", 2, 2),
+		scalarFunction("notEquals", 2, 2),
+		scalarFunction("less", 2, 2),
+		scalarFunction("lessOrEquals", 2, 2),
+		scalarFunction("greater", 2, 2),
+		scalarFunction("greaterOrEquals", 2, 2),
+		scalarFunction("isDistinctFrom", 2, 2),
+		scalarFunction("isNotDistinctFrom", 2, 2),
+		scalarFunction("and", 2, n),
+		scalarFunction("or", 2, n),
+		scalarFunction("xor", 2, n),
+		scalarFunction("not", 1, 1),
+		scalarFunction("in", 2, 2),
+		scalarFunction("notIn", 2, 2),
+		scalarFunction("globalIn", 2, 2),
+		scalarFunction("globalNotIn", 2, 2),
+		scalarFunction("like", 2, 2),
+		scalarFunction("notLike", 2, 2),
+		scalarFunction("ilike", 2, 2),
+		scalarFunction("notILike", 2, 2),
+		scalarFunction("match", 2, 2),
+		scalarFunction("arrayElement", 2, 2),
+		scalarFunction("tupleElement", 2, 3),
+
+		// conditionals and NULL handling
+		caseInsensitive(scalarFunction("if", 3, 3)),
+		scalarFunction("multiIf", 3, n),
+		caseInsensitive(scalarFunction("coalesce", 1, n)),
+		caseInsensitive(scalarFunction("ifNull", 2, 2)),
+		caseInsensitive(scalarFunction("nullIf", 2, 2)),
+		scalarFunction("assumeNotNull", 1, 1),
+		scalarFunction("toNullable", 1, 1),
+		caseInsensitive(scalarFunction("isNull", 1, 1)),
+		scalarFunction("isNotNull", 1, 1),
+		caseInsensitive(scalarFunction("greatest", 1, n)),
+		caseInsensitive(scalarFunction("least", 1, n)),
+
+		// strings
+		caseInsensitive(scalarFunction("concat", 1, n)),
+		scalarFunction("concatWithSeparator", 1, n),
+		caseInsensitive(scalarFunction("length", 1, 1)),
+		scalarFunction("lengthUTF8", 1, 1),
+		caseInsensitive(scalarFunction("lower", 1, 1)),
+		caseInsensitive(scalarFunction("upper", 1, 1)),
+		caseInsensitive(scalarFunction("lcase", 1, 1)),
+		caseInsensitive(scalarFunction("ucase", 1, 1)),
+		scalarFunction("lowerUTF8", 1, 1),
+		scalarFunction("upperUTF8", 1, 1),
+		caseInsensitive(scalarFunction("trim", 1, 2)),
+		caseInsensitive(scalarFunction("ltrim", 1, 1)),
+		caseInsensitive(scalarFunction("rtrim", 1, 1)),
+		scalarFunction("trimLeft", 1, 2),
+		scalarFunction("trimRight", 1, 2),
+		scalarFunction("trimBoth", 1, 2),
+		caseInsensitive(scalarFunction("substring", 2, 3)),
+		caseInsensitive(scalarFunction("substr", 2, 3)),
+		caseInsensitive(scalarFunction("mid", 2, 3)),
+		scalarFunction("byteSlice", 2, 3),
+		scalarFunction("substringUTF8", 2, 3),
+		caseInsensitive(scalarFunction("left", 2, 2)),
+		caseInsensitive(scalarFunction("right", 2, 2)),
+		caseInsensitive(scalarFunction("position", 2, 3)),
+		caseInsensitive(scalarFunction("locate", 2, 3)),
+		scalarFunction("positionCaseInsensitive", 2, 3),
+		caseInsensitive(scalarFunction("replace", 3, 3)),
+		scalarFunction("replaceAll", 3, 3),
+		scalarFunction("replaceOne", 3, 3),
+		scalarFunction("replaceRegexpAll", 3, 3),
+		scalarFunction("replaceRegexpOne", 3, 3),
+		caseInsensitive(scalarFunction("reverse", 1, 1)),
+		caseInsensitive(scalarFunction("repeat", 2, 2)),
+		caseInsensitive(scalarFunction("leftPad", 2, 3)),
+		caseInsensitive(scalarFunction("rightPad", 2, 3)),
+		caseInsensitive(scalarFunction("lpad", 2, 3)),
+		caseInsensitive(scalarFunction("rpad", 2, 3)),
+		scalarFunction("startsWith", 2, 2),
+		scalarFunction("endsWith", 2, 2),
+		scalarFunction("empty", 1, 1),
+		scalarFunction("notEmpty", 1, 1),
+		scalarFunction("splitByChar", 2, 3),
+		scalarFunction("splitByString", 2, 3),
+		scalarFunction("splitByRegexp", 2, 3),
+		scalarFunction("splitByWhitespace", 1, 2),
+		scalarFunction("arrayStringConcat", 1, 2),
+		scalarFunction("extract", 2, 2),
+		scalarFunction("extractAll", 2, 2),
+		scalarFunction("format", 1, n),
+		scalarFunction("base64Encode", 1, 1),
+		scalarFunction("base64Decode", 1, 1),
+		scalarFunction("toStringCutToZero", 1, 1),
+		scalarFunction("hex", 1, 1),
+		scalarFunction("unhex", 1, 1),
+		scalarFunction("toTypeName", 1, 1),
+		scalarFunction("toColumnTypeName", 1, 1),
+		caseInsensitive(scalarFunction("currentDatabase", 0, 0)),
+		caseInsensitive(scalarFunction("currentUser", 0, 0)),
+		scalarFunction("hostName", 0, 0),
+		caseInsensitive(scalarFunction("version", 0, 0)),
+		scalarFunction("uptime", 0, 0),
+		scalarFunction("COLUMNS", 1, n),
+		caseInsensitive(scalarFunction("exists", 1, 1)),
+		caseInsensitive(scalarFunction("overlay", 3, 4)),
+		scalarFunction("overlayUTF8", 3, 4),
+		scalarFunction("isZeroOrNull", 1, 1),
+
+		// dates and times
+		caseInsensitive(scalarFunction("now", 0, 1)),
+		scalarFunction("now64", 0, 2),
+		caseInsensitive(scalarFunction("today", 0, 0)),
+		scalarFunction("yesterday", 0, 0),
+		scalarFunction("toYear", 1, 2),
+		scalarFunction("toQuarter", 1, 2),
+		scalarFunction("toMonth", 1, 2),
+		scalarFunction("toDayOfMonth", 1, 2),
+		scalarFunction("toDayOfWeek", 1, 3),
+		scalarFunction("toDayOfYear", 1, 2),
+		scalarFunction("toHour", 1, 2),
+		scalarFunction("toMinute", 1, 2),
+		scalarFunction("toSecond", 1, 2),
+		scalarFunction("toYYYYMM", 1, 2),
+		scalarFunction("toYYYYMMDD", 1, 2),
+		scalarFunction("toYYYYMMDDhhmmss", 1, 2),
+		scalarFunction("toUnixTimestamp", 1, 2),
+		scalarFunction("toStartOfYear", 1, 2),
+		scalarFunction("toStartOfQuarter", 1, 2),
+		scalarFunction("toStartOfMonth", 1, 2),
+		scalarFunction("toStartOfWeek", 1, 3),
+		scalarFunction("toMonday", 1, 2),
+		scalarFunction("toStartOfDay", 1, 2),
+		scalarFunction("toStartOfHour", 1, 2),
+		scalarFunction("toStartOfMinute", 1, 2),
+		scalarFunction("toStartOfFiveMinutes", 1, 2),
+		scalarFunction("toStartOfTenMinutes", 1, 2),
+		scalarFunction("toStartOfFifteenMinutes", 1, 2),
+		scalarFunction("toStartOfInterval", 2, 4),
+		scalarFunction("toTimeZone", 2, 2),
+		caseInsensitive(scalarFunction("dateDiff", 3, 4)),
+		caseInsensitive(scalarFunction("date_diff", 3, 4)),
+		caseInsensitive(scalarFunction("dateTrunc", 2, 3)),
+		caseInsensitive(scalarFunction("date_trunc", 2, 3)),
+		caseInsensitive(scalarFunction("dateAdd", 2, 3)),
+		caseInsensitive(scalarFunction("date_add", 2, 3)),
+		caseInsensitive(scalarFunction("dateSub", 2, 3)),
+		caseInsensitive(scalarFunction("date_sub", 2, 3)),
+		caseInsensitive(scalarFunction("formatDateTime", 2, 3)),
+		scalarFunction("parseDateTimeBestEffort", 1, 2),
+		scalarFunction("parseDateTimeBestEffortOrNull", 1, 2),
+		scalarFunction("parseDateTimeBestEffortOrZero", 1, 2),
+		caseInsensitive(scalarFunction("fromUnixTimestamp", 1, 3)),
+		scalarFunction("toDateTime", 1, 2),
+		scalarFunction("toDateTime64", 2, 3),
+		scalarFunction("toFixedString", 2, 2),
+		scalarFunction("toDecimal32", 2, 2),
+		scalarFunction("toDecimal64", 2, 2),
+		scalarFunction("toDecimal128", 2, 2),
+		scalarFunction("toDecimal256", 2, 2),
+		scalarFunction("reinterpretAsString", 1, 1),
+		scalarFunction("accurateCast", 2, 2),
+		scalarFunction("accurateCastOrNull", 2, 2),
+		caseInsensitive(scalarFunction("CAST", 2, 2)),
+
+		// hashes, encoding and random values
+		scalarFunction("cityHash64", 1, n),
+		scalarFunction("sipHash64", 1, n),
+		scalarFunction("sipHash128", 1, n),
+		scalarFunction("xxHash32", 1, 1),
+		scalarFunction("xxHash64", 1, 1),
+		scalarFunction("xxh3", 1, n),
+		scalarFunction("farmHash64", 1, n),
+		scalarFunction("murmurHash3_32", 1, n),
+		scalarFunction("murmurHash3_64", 1, n),
+		scalarFunction("murmurHash3_128", 1, n),
+		caseInsensitive(scalarFunction("MD5", 1, 1)),
+		caseInsensitive(scalarFunction("SHA1", 1, 1)),
+		caseInsensitive(scalarFunction("SHA224", 1, 1)),
+		caseInsensitive(scalarFunction("SHA256", 1, 1)),
+		scalarFunction("rand", 0, 1),
+		scalarFunction("rand32", 0, 1),
+		scalarFunction("rand64", 0, 1),
+		scalarFunction("randCanonical", 0, 1),
+		scalarFunction("generateUUIDv4", 0, 1),
+		scalarFunction("generateUUIDv7", 0, 1),
+		scalarFunction("generateSnowflakeID", 0, 2),
+		scalarFunction("nowInBlock", 0, 1),
+		scalarFunction("rowNumberInBlock", 0, 0),
+		scalarFunction("rowNumberInAllBlocks", 0, 0),
+		scalarFunction("blockNumber", 0, 0),
+
+		// arrays, tuples and maps
+		scalarFunction("array", 0, n),
+		scalarFunction("arrayJoin", 1, 1),
+		scalarFunction("has", 2, 2),
+		scalarFunction("hasAll", 2, 2),
+		scalarFunction("hasAny", 2, 2),
+		scalarFunction("indexOf", 2, 2),
+		scalarFunction("countEqual", 2, 2),
+		scalarFunction("arrayConcat", 1, n),
+		scalarFunction("arrayDistinct", 1, 1),
+		scalarFunction("arrayReverse", 1, 1),
+		scalarFunction("arraySlice", 2, 3),
+		scalarFunction("arrayUniq", 1, n),
+		scalarFunction("arrayEnumerate", 1, 1),
+		scalarFunction("arrayCompact", 1, 1),
+		scalarFunction("arrayFlatten", 1, 1),
+		scalarFunction("arrayPushBack", 2, 2),
+		scalarFunction("arrayPushFront", 2, 2),
+		scalarFunction("arrayPopBack", 1, 1),
+		scalarFunction("arrayPopFront", 1, 1),
+		scalarFunction("arrayZip", 1, n),
+		scalarFunction("arrayIntersect", 1, n),
+		scalarFunction("arrayMap", 2, n),
+		scalarFunction("arrayFilter", 2, n),
+		scalarFunction("arraySort", 1, n),
+		scalarFunction("arrayReverseSort", 1, n),
+		scalarFunction("arrayExists", 1, n),
+		scalarFunction("arrayAll", 1, n),
+		scalarFunction("arrayCount", 1, n),
+		scalarFunction("arraySum", 1, n),
+		scalarFunction("arrayAvg", 1, n),
+		scalarFunction("arrayMin", 1, n),
+		scalarFunction("arrayMax", 1, n),
+		scalarFunction("arrayFirst", 2, n),
+		scalarFunction("arrayLast", 2, n),
+		scalarFunction("arrayFirstIndex", 2, n),
+		scalarFunction("arrayReduce", 2, n),
+		scalarFunction("range", 1, 3),
+		scalarFunction("emptyArrayString", 0, 0),
+		scalarFunction("tuple", 0, n),
+		scalarFunction("map", 0, n),
+		scalarFunction("mapKeys", 1, 1),
+		scalarFunction("mapValues", 1, 1),
+		scalarFunction("mapContains", 2, 2),
+		scalarFunction("materialize", 1, 1),
+		scalarFunction("identity", 1, 1),
+		scalarFunction("ignore", 0, n),
+		scalarFunction("throwIf", 1, 3),
+		scalarFunction("sleep", 1, 1),
+		scalarFunction("bar", 3, 4),
+		scalarFunction("formatReadableSize", 1, 1),
+		scalarFunction("formatReadableQuantity", 1, 1),
+
+		// dictionaries, JSON and URLs
+		scalarFunction("dictGet", 3, 4),
+		scalarFunction("dictGetOrDefault", 4, 4),
+		scalarFunction("dictGetOrNull", 3, 3),
+		scalarFunction("dictHas", 2, 2),
+		scalarFunction("JSONHas", 1, n),
+		scalarFunction("JSONLength", 1, n),
+		scalarFunction("JSONType", 1, n),
+		scalarFunction("JSONExtract", 2, n),
+		scalarFunction("JSONExtractString", 1, n),
+		scalarFunction("JSONExtractRaw", 1, n),
+		scalarFunction("JSONExtractInt", 1, n),
+		scalarFunction("JSONExtractUInt", 1, n),
+		scalarFunction("JSONExtractFloat", 1, n),
+		scalarFunction("JSONExtractBool", 1, n),
+		scalarFunction("JSONExtractKeys", 1, n),
+		scalarFunction("JSONExtractArrayRaw", 1, n),
+		scalarFunction("visitParamHas", 2, 2),
+		scalarFunction("visitParamExtractInt", 2, 2),
+		scalarFunction("visitParamExtractUInt", 2, 2),
+		scalarFunction("visitParamExtractFloat", 2, 2),
+		scalarFunction("visitParamExtractBool", 2, 2),
+		scalarFunction("visitParamExtractString", 2, 2),
+		scalarFunction("visitParamExtractRaw", 2, 2),
+		scalarFunction("JSON_VALUE", 2, 2),
+		scalarFunction("JSON_QUERY", 2, 2),
+		scalarFunction("domain", 1, 1),
+		scalarFunction("domainWithoutWWW", 1, 1),
+		scalarFunction("path", 1, 1),
+		scalarFunction("protocol", 1, 1),
+		scalarFunction("extractURLParameter", 2, 2),
+		scalarFunction("IPv4NumToString", 1, 1),
+		scalarFunction("IPv4StringToNum", 1, 1),
+		scalarFunction("toIPv4", 1, 1),
+		scalarFunction("toIPv6", 1, 1),
+
+		// aggregates
+		caseInsensitive(aggregateFunction("count", 0, n)),
+		caseInsensitive(aggregateFunction("sum", 1, 1)),
+		caseInsensitive(aggregateFunction("avg", 1, 1)),
+		caseInsensitive(aggregateFunction("min", 1, 1)),
+		caseInsensitive(aggregateFunction("max", 1, 1)),
+		caseInsensitive(aggregateFunction("any", 1, 1)),
+		aggregateFunction("anyLast", 1, 1),
+		aggregateFunction("anyHeavy", 1, 1),
+		aggregateFunction("argMin", 2, 2),
+		aggregateFunction("argMax", 2, 2),
+		aggregateFunction("avgWeighted", 2, 2),
+		aggregateFunction("sumWithOverflow", 1, 1),
+		aggregateFunction("sumMap", 1, n),
+		aggregateFunction("minMap", 1, n),
+		aggregateFunction("maxMap", 1, n),
+		aggregateFunction("groupBitAnd", 1, 1),
+		aggregateFunction("groupBitOr", 1, 1),
+		aggregateFunction("groupBitXor", 1, 1),
+		aggregateFunction("groupBitmap", 1, 1),
+		aggregateFunction("uniq", 1, n),
+		aggregateFunction("uniqExact", 1, n),
+		aggregateFunction("uniqCombined", 1, n),
+		aggregateFunction("uniqCombined64", 1, n),
+		aggregateFunction("uniqHLL12", 1, n),
+		aggregateFunction("uniqTheta", 1, n),
+		parametricFunction("groupArray", 0, 1, 1, 1),
+		parametricFunction("groupUniqArray", 0, 1, 1, 1),
+		aggregateFunction("groupArrayInsertAt", 2, 2),
+		parametricFunction("groupArrayMovingSum", 0, 1, 1, 1),
+		parametricFunction("groupArraySample", 1, 2, 1, 1),
+		parametricFunction("topK", 0, 3, 1, 1),
+		parametricFunction("topKWeighted", 0, 3, 2, 2),
+		parametricFunction("quantile", 0, 1, 1, 1),
+		parametricFunction("quantileExact", 0, 1, 1, 1),
+		parametricFunction("quantileExactWeighted", 0, 1, 2, 2),
+		parametricFunction("quantileTiming", 0, 1, 1, 1),
+		parametricFunction("quantileTDigest", 0, 1, 1, 1),
+		parametricFunction("quantileDeterministic", 0, 1, 2, 2),
+		parametricFunction("quantiles", 1, n, 1, 1),
+		parametricFunction("quantilesExact", 1, n, 1, 1),
+		parametricFunction("quantilesTDigest", 1, n, 1, 1),
+		parametricFunction("quantilesTiming", 1, n, 1, 1),
+		caseInsensitive(aggregateFunction("median", 1, 1)),
+		aggregateFunction("medianExact", 1, 1),
+		parametricFunction("histogram", 1, 1, 1, 1),
+		parametricFunction("windowFunnel", 1, n, 2, n),
+		parametricFunction("retention", 0, 0, 1, 32),
+		parametricFunction("sequenceMatch", 1, 1, 2, n),
+		parametricFunction("sequenceCount", 1, 1, 2, n),
+		caseInsensitive(aggregateFunction("stddevPop", 1, 1)),
+		caseInsensitive(aggregateFunction("stddevSamp", 1, 1)),
+		caseInsensitive(aggregateFunction("varPop", 1, 1)),
+		caseInsensitive(aggregateFunction("varSamp", 1, 1)),
+		caseInsensitive(aggregateFunction("covarPop", 2, 2)),
+		caseInsensitive(aggregateFunction("covarSamp", 2, 2)),
+		caseInsensitive(aggregateFunction("corr", 2, 2)),
+		aggregateFunction("simpleLinearRegression", 2, 2),
+		aggregateFunction("entropy", 1, n),
+		aggregateFunction("skewPop", 1, 1),
+		aggregateFunction("kurtPop", 1, 1),
+
+		// window functions
+		caseInsensitive(windowFunction("row_number", 0, 0)),
+		caseInsensitive(windowFunction("rank", 0, 0)),
+		caseInsensitive(windowFunction("dense_rank", 0, 0)),
+		caseInsensitive(windowFunction("percent_rank", 0, 0)),
+		caseInsensitive(windowFunction("cume_dist", 0, 0)),
+		caseInsensitive(windowFunction("ntile", 1, 1)),
+		caseInsensitive(windowFunction("lag", 1, 3)),
+		caseInsensitive(windowFunction("lead", 1, 3)),
+		windowFunction("lagInFrame", 1, 3),
+		windowFunction("leadInFrame", 1, 3),
+		caseInsensitive(windowFunction("first_value", 1, 1)),
+		caseInsensitive(windowFunction("last_value", 1, 1)),
+		caseInsensitive(windowFunction("nth_value", 2, 2)),
+
+		// table functions
+		tableFunction("numbers", 1, 3),
+		tableFunction("numbers_mt", 1, 3),
+		tableFunction("zeros", 1, 1),
+		tableFunction("zeros_mt", 1, 1),
+		tableFunction("generate_series", 2, 3),
+		tableFunction("generateRandom", 0, 4),
+		tableFunction("values", 1, n),
+		tableFunction("null", 1, 1),
+		tableFunction("view", 1, 1),
+		tableFunction("input", 1, 1),
+		tableFunction("merge", 1, 2),
+		tableFunction("dictionary", 1, 1),
+		tableFunction("loop", 1, 2),
+		tableFunction("remote", 2, 6),
+		tableFunction("remoteSecure", 2, 6),
+		tableFunction("cluster", 2, 4),
+		tableFunction("clusterAllReplicas", 2, 4),
+		tableFunction("file", 1, 5),
+		tableFunction("url", 1, 4),
+		tableFunction("s3", 1, 8),
+		tableFunction("s3Cluster", 2, 9),
+		tableFunction("gcs", 1, 8),
+		tableFunction("azureBlobStorage", 3, 8),
+		tableFunction("hdfs", 1, 4),
+		tableFunction("iceberg", 1, 6),
+		tableFunction("deltaLake", 1, 6),
+		tableFunction("mysql", 5, 7),
+		tableFunction("postgresql", 5, 7),
+		tableFunction("mongodb", 6, 8),
+		tableFunction("redis", 3, 6),
+		tableFunction("sqlite", 2, 2),
+		tableFunction("jdbc", 2, 3),
+		tableFunction("odbc", 2, 3),
+		tableFunction("executable", 3, 5),
+		tableFunction("format", 2, 3),
+		tableFunction("mergeTreeIndex", 2, 3),
+	}
+
+	// conversions, with their OrZero, OrNull and OrDefault variants
+	for _, typ := range []string{
+		"Int8", "Int16", "Int32", "Int64", "Int128", "Int256",
+		"UInt8", "UInt16", "UInt32", "UInt64", "UInt128", "UInt256",
+		"Float32", "Float64", "Date", "Date32", "UUID", "Bool", "String",
+	} {
+		functions = append(functions, scalarFunction("to"+typ, 1, 2))
+		for _, suffix := range []string{"OrZero", "OrNull", "OrDefault"} {
+			functions = append(functions, scalarFunction("to"+typ+suffix, 1, 2))
+		}
+	}
+	for _, unit := range []string{"Second", "Minute", "Hour", "Day", "Week", "Month", "Quarter", "Year"} {
+		functions = append(functions,
+			scalarFunction("add"+unit+"s", 2, 2),
+			scalarFunction("subtract"+unit+"s", 2, 2),
+			scalarFunction("toInterval"+unit, 1, 1),
+		)
+	}
+	for _, unit := range []string{"Milli", "Micro", "Nano"} {
+		functions = append(functions,
+			scalarFunction("toUnixTimestamp64"+unit, 1, 1),
+			scalarFunction("fromUnixTimestamp64"+unit, 1, 2),
+		)
+	}
+	return functions
+}()
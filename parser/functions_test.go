@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctionRegistryLookup(t *testing.T) {
+	registry := NewFunctionRegistry()
+
+	info, ok := registry.Lookup("COUNT")
+	require.True(t, ok)
+	require.Equal(t, FunctionKindAggregate, info.Kind)
+	_, ok = registry.Lookup("TOSTRING")
+	require.False(t, ok, "toString is case-sensitive")
+
+	info, ok = registry.Lookup("sumIf")
+	require.True(t, ok)
+	require.Equal(t, FunctionKindAggregate, info.Kind)
+	require.Equal(t, 2, info.MinArgs)
+	require.Equal(t, 2, info.MaxArgs)
+
+	info, ok = registry.Lookup("quantilesTDigestMergeState")
+	require.True(t, ok)
+	require.Equal(t, 1, info.MaxArgs)
+	require.True(t, info.AcceptsParams(2))
+
+	info, ok = registry.Lookup("uniqOrNull")
+	require.True(t, ok)
+	require.Equal(t, VariadicArgs, info.MaxArgs)
+
+	_, ok = registry.Lookup("lowerIf")
+	require.False(t, ok, "combinators only apply to aggregates")
+	_, ok = registry.Lookup("numbers")
+	require.False(t, ok)
+	info, ok = registry.LookupTable("numbers")
+	require.True(t, ok)
+	require.Equal(t, FunctionKindTable, info.Kind)
+
+	registry.Register(FunctionInfo{Name: "myUDF", Kind: FunctionKindScalar, MinArgs: 1, MaxArgs: 1})
+	_, ok = registry.Lookup("myUDF")
+	require.True(t, ok)
+	_, ok = NewFunctionRegistry().Lookup("myUDF")
+	require.False(t, ok)
+}
+
+func TestFunctionRegistryCoversFunctionTypes(t *testing.T) {
+	registry := NewFunctionRegistry()
+	for name := range DefaultFunctionTypes() {
+		_, ok := registry.Lookup(name)
+		require.True(t, ok, name)
+	}
+}
+
+func TestValidateFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected []string
+	}{
+		{
+			name: "valid calls",
+			sql: "SELECT count(), COUNT(DISTINCT a), sumIf(x, y > 1), quantile(0.9)(x), quantiles(0.5, 0.9)(x), " +
+				"uniqMerge(s), row_number() OVER (ORDER BY a), sum(x) OVER w, arrayMap(v -> v + 1, arr), " +
+				"substring(s FROM 2 FOR 3), trim(BOTH ' ' FROM s), position('a' IN s), * EXCEPT (b) " +
+				"FROM numbers(10) WHERE a > (SELECT max(a) FROM t) AND toDate(ts) = today() GROUP BY a HAVING count() > 1 " +
+				"WINDOW w AS (PARTITION BY a)",
+		},
+		{
+			name:     "unknown functions",
+			sql:      "SELECT nonexistentFunc(), lowerIf(a, 1), COUNTIF(a) FROM t",
+			expected: []string{"UNKNOWN_FUNCTION nonexistentFunc", "UNKNOWN_FUNCTION lowerIf", "UNKNOWN_FUNCTION COUNTIF"},
+		},
+		{
+			name: "wrong argument counts",
+			sql:  "SELECT sum(a, b, c, d), toDate(), sumIf(a), lower(a)(b), quantiles(x), uniqMerge(a, b) FROM t",
+			expected: []string{
+				"NUMBER_OF_ARGUMENTS_DOESNT_MATCH sum(a, b, c, d",
+				"NUMBER_OF_ARGUMENTS_DOESNT_MATCH toDate(",
+				"NUMBER_OF_ARGUMENTS_DOESNT_MATCH sumIf(a",
+				"NUMBER_OF_ARGUMENTS_DOESNT_MATCH lower(a",
+				"NUMBER_OF_ARGUMENTS_DOESNT_MATCH quantiles(x",
+				"NUMBER_OF_ARGUMENTS_DOESNT_MATCH uniqMerge(a, b",
+			},
+		},
+		{
+			name: "aggregates in WHERE and GROUP BY",
+			sql:  "SELECT a FROM t PREWHERE max(b) > 1 WHERE sum(a) > 1 AND rank() OVER () = 1 GROUP BY countIf(b)",
+			expected: []string{
+				"ILLEGAL_AGGREGATION max(b",
+				"ILLEGAL_AGGREGATION sum(a",
+				"ILLEGAL_AGGREGATION rank() OVER (",
+				"ILLEGAL_AGGREGATION countIf(b",
+			},
+		},
+		{
+			name: "window functions",
+			sql:  "SELECT row_number(), lower(a) OVER () FROM t",
+			expected: []string{
+				"ILLEGAL_WINDOW_FUNCTION row_number(",
+				"ILLEGAL_WINDOW_FUNCTION lower(a",
+			},
+		},
+		{
+			name: "table functions",
+			sql:  "SELECT * FROM nosuchtable(1), numbers(), cluster('c', numbers(greatest(1, 2)), nosuch(1))",
+			expected: []string{
+				"UNKNOWN_TABLE_FUNCTION nosuchtable",
+				"NUMBER_OF_ARGUMENTS_DOESNT_MATCH numbers(",
+				"UNKNOWN_FUNCTION nosuch",
+			},
+		},
+		{
+			name:     "insert into a table function",
+			sql:      "INSERT INTO FUNCTION nosuch('a.csv') SELECT lower()",
+			expected: []string{"UNKNOWN_TABLE_FUNCTION nosuch", "NUMBER_OF_ARGUMENTS_DOESNT_MATCH lower("},
+		},
+		{
+			name:     "DDL expressions",
+			sql:      "CREATE TABLE t (a DateTime DEFAULT now(), b String DEFAULT nope(a)) ENGINE = MergeTree ORDER BY toYYYYMM(a, b, c)",
+			expected: []string{"UNKNOWN_FUNCTION nope", "NUMBER_OF_ARGUMENTS_DOESNT_MATCH toYYYYMM(a, b, c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			// the End of a call is its closing parenthesis
+			for _, diagnostic := range ValidateFunctions(parseOneStmt(t, tt.sql), nil) {
+				got = append(got, string(diagnostic.Kind)+" "+tt.sql[diagnostic.Pos:diagnostic.End])
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestValidateFunctionsMessages(t *testing.T) {
+	diagnostics := ValidateFunctions(parseOneStmt(t, "SELECT sum(a, b), quantile(1, 2)(x), count(a) FROM t WHERE count() > 1"), nil)
+	var messages []string
+	for _, diagnostic := range diagnostics {
+		messages = append(messages, diagnostic.Msg)
+	}
+	require.Equal(t, []string{
+		"function sum takes 1 arguments, got 2",
+		"function quantile takes 0 to 1 parameters, got 2",
+		"aggregate function count is not allowed in WHERE",
+	}, messages)
+}
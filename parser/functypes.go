@@ -0,0 +1,699 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FunctionArg is an argument passed to a ReturnTypeFunc.
+type FunctionArg struct {
+	Expr Expr
+	// Type is nil when it is unknown. The type of a lambda argument is the
+	// type of its body.
+	Type *DataType
+}
+
+// ReturnTypeFunc computes the result type of a function from its
+// arguments. It returns nil when the type cannot be determined. The
+// arguments of a parametric aggregate such as quantile(0.9)(x) exclude its
+// parameters.
+type ReturnTypeFunc func(args []FunctionArg) *DataType
+
+// FunctionTypes maps function names to their result types. Names are
+// matched exactly first and then case-insensitively, and aggregate
+// combinators such as sumIf, uniqState or avgMerge are derived from the
+// function they adapt.
+type FunctionTypes map[string]ReturnTypeFunc
+
+// DefaultFunctionTypes returns a new table covering the common built-in
+// functions, which callers may extend with their own.
+func DefaultFunctionTypes() FunctionTypes {
+	functions := make(FunctionTypes, len(defaultFunctionTypes))
+	for name, fn := range defaultFunctionTypes {
+		functions[name] = fn
+	}
+	return functions
+}
+
+var defaultFunctionTypes = func() FunctionTypes {
+	functions := FunctionTypes{
+		// arithmetic
+		"plus":     propagateNull(arithmeticType(OperatorPlus)),
+		"minus":    propagateNull(arithmeticType(OperatorMinus)),
+		"multiply": propagateNull(arithmeticType(OperatorMultiply)),
+		"divide":   propagateNull(divideType),
+		"intDiv":   propagateNull(integerDivisionType),
+		"modulo":   propagateNull(integerDivisionType),
+		"negate":   propagateNull(negateType),
+		"abs":      propagateNull(absType),
+		"round":    propagateNull(argType(0)),
+		"floor":    propagateNull(argType(0)),
+		"ceil":     propagateNull(argType(0)),
+		"sqrt":     propagateNull(fixedType("Float64")),
+		"exp":      propagateNull(fixedType("Float64")),
+		"log":      propagateNull(fixedType("Float64")),
+		"pow":      propagateNull(fixedType("Float64")),
+
+		// conditionals and NULL handling
+		"if":            ifType,
+		"multiIf":       multiIfType,
+		"coalesce":      coalesceType,
+		"ifNull":        coalesceType,
+		"nullIf":        nullIfType,
+		"assumeNotNull": assumeNotNullType,
+		"toNullable":    nullIfType,
+		"isNull":        fixedType("UInt8"),
+		"isNotNull":     fixedType("UInt8"),
+		"greatest":      propagateNull(commonArgType),
+		"least":         propagateNull(commonArgType),
+
+		// strings
+		"concat":          propagateNull(fixedType("String")),
+		"length":          propagateNull(fixedType("UInt64")),
+		"lengthUTF8":      propagateNull(fixedType("UInt64")),
+		"position":        propagateNull(fixedType("UInt64")),
+		"splitByChar":     propagateNull(fixedType("Array(String)")),
+		"splitByString":   propagateNull(fixedType("Array(String)")),
+		"splitByRegexp":   propagateNull(fixedType("Array(String)")),
+		"extractAll":      propagateNull(fixedType("Array(String)")),
+		"format":          propagateNull(fixedType("String")),
+		"toTypeName":      fixedType("String"),
+		"currentDatabase": fixedType("String"),
+		"currentUser":     fixedType("String"),
+		"hostName":        fixedType("String"),
+		"version":         fixedType("String"),
+
+		// dates
+		"now":                     fixedType("DateTime"),
+		"now64":                   fixedType("DateTime64(3)"),
+		"today":                   fixedType("Date"),
+		"yesterday":               fixedType("Date"),
+		"toYear":                  propagateNull(fixedType("UInt16")),
+		"toQuarter":               propagateNull(fixedType("UInt8")),
+		"toMonth":                 propagateNull(fixedType("UInt8")),
+		"toDayOfMonth":            propagateNull(fixedType("UInt8")),
+		"toDayOfWeek":             propagateNull(fixedType("UInt8")),
+		"toDayOfYear":             propagateNull(fixedType("UInt16")),
+		"toHour":                  propagateNull(fixedType("UInt8")),
+		"toMinute":                propagateNull(fixedType("UInt8")),
+		"toSecond":                propagateNull(fixedType("UInt8")),
+		"toYYYYMM":                propagateNull(fixedType("UInt32")),
+		"toYYYYMMDD":              propagateNull(fixedType("UInt32")),
+		"toUnixTimestamp":         propagateNull(fixedType("UInt32")),
+		"toStartOfYear":           propagateNull(fixedType("Date")),
+		"toStartOfQuarter":        propagateNull(fixedType("Date")),
+		"toStartOfMonth":          propagateNull(fixedType("Date")),
+		"toStartOfWeek":           propagateNull(fixedType("Date")),
+		"toMonday":                propagateNull(fixedType("Date")),
+		"toStartOfDay":            propagateNull(fixedType("DateTime")),
+		"toStartOfHour":           propagateNull(fixedType("DateTime")),
+		"toStartOfMinute":         propagateNull(fixedType("DateTime")),
+		"toStartOfFiveMinutes":    propagateNull(fixedType("DateTime")),
+		"toStartOfInterval":       propagateNull(fixedType("DateTime")),
+		"dateDiff":                propagateNull(fixedType("Int64")),
+		"formatDateTime":          propagateNull(fixedType("String")),
+		"parseDateTimeBestEffort": propagateNull(fixedType("DateTime")),
+
+		// hashes and random values
+		"cityHash64":     fixedType("UInt64"),
+		"sipHash64":      fixedType("UInt64"),
+		"xxHash64":       fixedType("UInt64"),
+		"farmHash64":     fixedType("UInt64"),
+		"murmurHash3_64": fixedType("UInt64"),
+		"xxHash32":       fixedType("UInt32"),
+		"rand":           fixedType("UInt32"),
+		"rand64":         fixedType("UInt64"),
+		"generateUUIDv4": fixedType("UUID"),
+		"MD5":            propagateNull(fixedType("FixedString(16)")),
+		"SHA256":         propagateNull(fixedType("FixedString(32)")),
+		"hex":            propagateNull(fixedType("String")),
+
+		// arrays, tuples and maps
+		"array":         arrayType,
+		"arrayJoin":     elementType(0),
+		"arrayElement":  elementType(0),
+		"has":           fixedType("UInt8"),
+		"hasAll":        fixedType("UInt8"),
+		"hasAny":        fixedType("UInt8"),
+		"indexOf":       fixedType("UInt64"),
+		"empty":         propagateNull(fixedType("UInt8")),
+		"notEmpty":      propagateNull(fixedType("UInt8")),
+		"arrayConcat":   commonArgType,
+		"arrayDistinct": argType(0),
+		"arrayReverse":  argType(0),
+		"arraySlice":    argType(0),
+		"arrayUniq":     fixedType("UInt64"),
+		"arrayMap":      arrayMapType,
+		"arrayFilter":   argType(1),
+		"arraySort":     lastArgType,
+		"arrayExists":   fixedType("UInt8"),
+		"arrayAll":      fixedType("UInt8"),
+		"arrayCount":    fixedType("UInt32"),
+		"arrayFirst":    elementType(1),
+		"range":         arrayOfArg(0),
+		"tuple":         tupleType,
+		"tupleElement":  tupleElementType,
+		"map":           mapType,
+		"mapKeys":       mapPartType(true),
+		"mapValues":     mapPartType(false),
+		"materialize":   argType(0),
+		"identity":      argType(0),
+
+		// JSON
+		"JSONHas":           propagateNull(fixedType("UInt8")),
+		"JSONLength":        propagateNull(fixedType("UInt64")),
+		"JSONExtractString": propagateNull(fixedType("String")),
+		"JSONExtractRaw":    propagateNull(fixedType("String")),
+		"JSONExtractInt":    propagateNull(fixedType("Int64")),
+		"JSONExtractUInt":   propagateNull(fixedType("UInt64")),
+		"JSONExtractFloat":  propagateNull(fixedType("Float64")),
+		"JSONExtractBool":   propagateNull(fixedType("UInt8")),
+
+		// aggregates
+		"count":           fixedType("UInt64"),
+		"sum":             propagateNull(sumType),
+		"avg":             propagateNull(fixedType("Float64")),
+		"min":             argType(0),
+		"max":             argType(0),
+		"any":             argType(0),
+		"anyLast":         argType(0),
+		"anyHeavy":        argType(0),
+		"argMin":          argType(0),
+		"argMax":          argType(0),
+		"groupBitAnd":     argType(0),
+		"groupBitOr":      argType(0),
+		"groupBitXor":     argType(0),
+		"uniq":            fixedType("UInt64"),
+		"uniqExact":       fixedType("UInt64"),
+		"uniqCombined":    fixedType("UInt64"),
+		"uniqHLL12":       fixedType("UInt64"),
+		"groupArray":      arrayOfArg(0),
+		"groupUniqArray":  arrayOfArg(0),
+		"topK":            arrayOfArg(0),
+		"quantile":        propagateNull(fixedType("Float64")),
+		"quantileExact":   propagateNull(fixedType("Float64")),
+		"quantileTDigest": propagateNull(fixedType("Float64")),
+		"quantileTiming":  propagateNull(fixedType("Float32")),
+		"median":          propagateNull(fixedType("Float64")),
+		"quantiles":       fixedType("Array(Float64)"),
+		"stddevPop":       propagateNull(fixedType("Float64")),
+		"stddevSamp":      propagateNull(fixedType("Float64")),
+		"varPop":          propagateNull(fixedType("Float64")),
+		"varSamp":         propagateNull(fixedType("Float64")),
+		"corr":            propagateNull(fixedType("Float64")),
+
+		// window functions
+		"row_number":   fixedType("UInt64"),
+		"rank":         fixedType("UInt64"),
+		"dense_rank":   fixedType("UInt64"),
+		"ntile":        fixedType("UInt64"),
+		"percent_rank": fixedType("Float64"),
+		"cume_dist":    fixedType("Float64"),
+		"lagInFrame":   argType(0),
+		"leadInFrame":  argType(0),
+		"first_value":  argType(0),
+		"last_value":   argType(0),
+		"nth_value":    argType(0),
+	}
+
+	// comparisons and logical functions return UInt8
+	for _, name := range []string{
+		"equals", "notEquals", "less", "lessOrEquals", "greater", "greaterOrEquals",
+		"like", "notLike", "ilike", "notILike", "match", "startsWith", "endsWith",
+		"and", "or", "not", "xor",
+	} {
+		functions[name] = propagateNull(fixedType("UInt8"))
+	}
+	for _, name := range []string{
+		"in", "notIn", "globalIn", "globalNotIn", "isDistinctFrom", "isNotDistinctFrom",
+	} {
+		functions[name] = fixedType("UInt8")
+	}
+	// string functions that return a String
+	for _, name := range []string{
+		"lower", "upper", "lowerUTF8", "upperUTF8", "trim", "trimLeft", "trimRight",
+		"substring", "substringUTF8", "replace", "replaceAll", "replaceOne",
+		"replaceRegexpAll", "replaceRegexpOne", "reverse", "leftPad", "rightPad",
+		"repeat", "extract", "base64Encode", "base64Decode", "toStringCutToZero",
+	} {
+		functions[name] = propagateNull(fixedType("String"))
+	}
+	// date arithmetic keeps the type of the date
+	for _, unit := range []string{"Seconds", "Minutes", "Hours", "Days", "Weeks", "Months", "Quarters", "Years"} {
+		functions["add"+unit] = propagateNull(argType(0))
+		functions["subtract"+unit] = propagateNull(argType(0))
+	}
+	// type conversions, with their OrZero and OrNull variants
+	for _, typ := range []string{
+		"Int8", "Int16", "Int32", "Int64", "Int128", "Int256",
+		"UInt8", "UInt16", "UInt32", "UInt64", "UInt128", "UInt256",
+		"Float32", "Float64", "Date", "Date32", "UUID", "Bool", "String",
+	} {
+		conversion := propagateNull(fixedType(typ))
+		functions["to"+typ] = conversion
+		functions["to"+typ+"OrZero"] = conversion
+		functions["to"+typ+"OrDefault"] = conversion
+		functions["to"+typ+"OrNull"] = func(args []FunctionArg) *DataType {
+			return nullableType(conversion(args))
+		}
+	}
+	functions["toDateTime"] = propagateNull(dateTimeConversionType)
+	functions["toDateTime64"] = propagateNull(dateTime64ConversionType)
+	functions["toFixedString"] = propagateNull(fixedStringConversionType)
+	for name, precision := range map[string]int{"toDecimal32": 9, "toDecimal64": 18, "toDecimal128": 38, "toDecimal256": 76} {
+		functions[name] = propagateNull(decimalConversionType(precision))
+	}
+	return functions
+}()
+
+// fixedType returns a ReturnTypeFunc that always returns typ.
+func fixedType(typ string) ReturnTypeFunc {
+	t, err := ParseDataType(typ)
+	if err != nil {
+		panic(err)
+	}
+	return func([]FunctionArg) *DataType {
+		return t
+	}
+}
+
+// argType returns the type of the i-th argument.
+func argType(i int) ReturnTypeFunc {
+	return func(args []FunctionArg) *DataType {
+		if i >= len(args) {
+			return nil
+		}
+		return args[i].Type
+	}
+}
+
+func lastArgType(args []FunctionArg) *DataType {
+	if len(args) == 0 {
+		return nil
+	}
+	return args[len(args)-1].Type
+}
+
+// elementType returns the element type of the i-th argument, an array.
+func elementType(i int) ReturnTypeFunc {
+	return func(args []FunctionArg) *DataType {
+		if i >= len(args) || args[i].Type == nil {
+			return nil
+		}
+		if t := args[i].Type.Unwrap(); t.Name == "Array" {
+			return t.Elem
+		}
+		return nil
+	}
+}
+
+// arrayOfArg returns an array of the type of the i-th argument.
+func arrayOfArg(i int) ReturnTypeFunc {
+	return func(args []FunctionArg) *DataType {
+		if i >= len(args) || args[i].Type == nil {
+			return nil
+		}
+		return &DataType{Name: "Array", Elem: args[i].Type}
+	}
+}
+
+// propagateNull makes the result Nullable when an argument is, as most
+// functions return NULL for NULL arguments.
+func propagateNull(fn ReturnTypeFunc) ReturnTypeFunc {
+	return func(args []FunctionArg) *DataType {
+		nullable := false
+		bare := make([]FunctionArg, len(args))
+		for i, arg := range args {
+			bare[i] = arg
+			if arg.Type == nil || !arg.Type.IsNullable() {
+				continue
+			}
+			nullable = true
+			if arg.Type.Name == "Nullable" {
+				bare[i].Type = arg.Type.Elem
+			} else {
+				bare[i].Type = arg.Type.Unwrap()
+			}
+		}
+		t := fn(bare)
+		if nullable {
+			return nullableType(t)
+		}
+		return t
+	}
+}
+
+func commonArgType(args []FunctionArg) *DataType {
+	types := make([]*DataType, len(args))
+	for i, arg := range args {
+		types[i] = arg.Type
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	return commonType(types...)
+}
+
+func ifType(args []FunctionArg) *DataType {
+	if len(args) != 3 {
+		return nil
+	}
+	return commonType(args[1].Type, args[2].Type)
+}
+
+func multiIfType(args []FunctionArg) *DataType {
+	if len(args) < 3 || len(args)%2 == 0 {
+		return nil
+	}
+	var types []*DataType
+	for i := 1; i < len(args); i += 2 {
+		types = append(types, args[i].Type)
+	}
+	return commonType(append(types, args[len(args)-1].Type)...)
+}
+
+// coalesceType is Nullable only if every argument is.
+func coalesceType(args []FunctionArg) *DataType {
+	t := commonArgType(args)
+	if t == nil || len(args) == 0 {
+		return nil
+	}
+	for _, arg := range args {
+		if !arg.Type.IsNullable() {
+			return assumeNotNullType([]FunctionArg{{Type: t}})
+		}
+	}
+	return t
+}
+
+func nullIfType(args []FunctionArg) *DataType {
+	if len(args) == 0 {
+		return nil
+	}
+	return nullableType(args[0].Type)
+}
+
+func assumeNotNullType(args []FunctionArg) *DataType {
+	if len(args) != 1 || args[0].Type == nil {
+		return nil
+	}
+	switch t := args[0].Type; t.Name {
+	case "Nullable":
+		return t.Elem
+	case "LowCardinality":
+		if t.Elem.Name == "Nullable" {
+			return &DataType{Name: "LowCardinality", Elem: t.Elem.Elem}
+		}
+	}
+	return args[0].Type
+}
+
+// arithmeticType follows the ClickHouse rules for plus, minus and
+// multiply: integers widen to the next size, so UInt8 + UInt8 is UInt16,
+// and subtraction makes them signed. Decimals follow decimalArithmeticType.
+func arithmeticType(op Operator) ReturnTypeFunc {
+	subtract := op == OperatorMinus
+	return func(args []FunctionArg) *DataType {
+		if len(args) != 2 || args[0].Type == nil || args[1].Type == nil {
+			return nil
+		}
+		a, b := args[0].Type.Unwrap(), args[1].Type.Unwrap()
+		switch {
+		case a.Name == "Decimal" || b.Name == "Decimal":
+			return decimalArithmeticType(op, a, b)
+		case a.IsInteger() && b.IsInteger():
+			ai, bi := integerTypes[a.Name], integerTypes[b.Name]
+			bits := max(ai.bits, bi.bits)
+			if bits < 64 {
+				bits *= 2
+			}
+			return &DataType{Name: integerTypeName(bits, ai.signed || bi.signed || subtract)}
+		case a.IsDateOrTime() && (b.IsInteger() || strings.HasPrefix(b.Name, "Interval")):
+			return a
+		case subtract && a.IsDateOrTime() && b.IsDateOrTime():
+			return &DataType{Name: "Int32"}
+		case !subtract && b.IsDateOrTime() && a.IsInteger():
+			return b
+		case (a.IsFloat() || a.IsInteger()) && (b.IsFloat() || b.IsInteger()):
+			return &DataType{Name: "Float64"}
+		}
+		return nil
+	}
+}
+
+// divideType returns Float64, or a Decimal when an operand is one.
+func divideType(args []FunctionArg) *DataType {
+	if len(args) == 2 && args[0].Type != nil && args[1].Type != nil {
+		if a, b := args[0].Type.Unwrap(), args[1].Type.Unwrap(); a.Name == "Decimal" || b.Name == "Decimal" {
+			return decimalArithmeticType(OperatorDivide, a, b)
+		}
+	}
+	return &DataType{Name: "Float64"}
+}
+
+// decimalArithmeticType follows the ClickHouse rules for arithmetic on
+// Decimals. Two Decimals give a Decimal of the wider one's size at its
+// maximum precision, with the larger scale for plus and minus, the sum of
+// the scales for multiply and the dividend's scale for divide. A Decimal
+// and an integer give a Decimal of the Decimal's size and scale, and a
+// Decimal and a float give Float64.
+func decimalArithmeticType(op Operator, a, b *DataType) *DataType {
+	switch {
+	case a.Name == "Decimal" && b.Name == "Decimal":
+		var scale int
+		switch op {
+		case OperatorMultiply:
+			scale = a.Scale + b.Scale
+		case OperatorDivide:
+			scale = a.Scale
+		default:
+			scale = max(a.Scale, b.Scale)
+		}
+		precision := decimalStoragePrecision(max(a.Precision, b.Precision))
+		if scale > precision {
+			// ClickHouse rejects the operation
+			return nil
+		}
+		return &DataType{Name: "Decimal", Precision: precision, Scale: scale}
+	case a.Name == "Decimal" && b.IsInteger():
+		return &DataType{Name: "Decimal", Precision: decimalStoragePrecision(a.Precision), Scale: a.Scale}
+	case a.IsInteger() && b.Name == "Decimal":
+		return &DataType{Name: "Decimal", Precision: decimalStoragePrecision(b.Precision), Scale: b.Scale}
+	case a.Name == "Decimal" && b.IsFloat(), a.IsFloat() && b.Name == "Decimal":
+		return &DataType{Name: "Float64"}
+	}
+	return nil
+}
+
+// decimalStoragePrecision returns the maximum precision of the Decimal32,
+// Decimal64, Decimal128 or Decimal256 that stores a Decimal of the given
+// precision.
+func decimalStoragePrecision(precision int) int {
+	for _, storage := range []int{9, 18, 38} {
+		if precision <= storage {
+			return storage
+		}
+	}
+	return 76
+}
+
+// integerDivisionType returns the wider integer type of the operands, as
+// intDiv and modulo do not grow their result.
+func integerDivisionType(args []FunctionArg) *DataType {
+	if len(args) != 2 || args[0].Type == nil || args[1].Type == nil {
+		return nil
+	}
+	a, b := args[0].Type.Unwrap(), args[1].Type.Unwrap()
+	if !a.IsInteger() || !b.IsInteger() {
+		if a.IsNumeric() && b.IsNumeric() {
+			return &DataType{Name: "Float64"}
+		}
+		return nil
+	}
+	ai, bi := integerTypes[a.Name], integerTypes[b.Name]
+	return &DataType{Name: integerTypeName(max(ai.bits, bi.bits), ai.signed || bi.signed)}
+}
+
+func negateType(args []FunctionArg) *DataType {
+	if len(args) != 1 || args[0].Type == nil {
+		return nil
+	}
+	t := args[0].Type.Unwrap()
+	if !t.IsInteger() {
+		if t.IsNumeric() {
+			return t
+		}
+		return nil
+	}
+	info := integerTypes[t.Name]
+	if info.signed {
+		return t
+	}
+	bits := info.bits
+	if bits < 64 {
+		bits *= 2
+	}
+	return &DataType{Name: integerTypeName(bits, true)}
+}
+
+// absType returns the unsigned type of the same width for signed integers,
+// so abs(Int32) is UInt32, and the argument type otherwise.
+func absType(args []FunctionArg) *DataType {
+	if len(args) != 1 || args[0].Type == nil {
+		return nil
+	}
+	t := args[0].Type.Unwrap()
+	if info, ok := integerTypes[t.Name]; ok && info.signed {
+		return &DataType{Name: integerTypeName(info.bits, false)}
+	}
+	return args[0].Type
+}
+
+// sumType widens integers to 64 bits and floats to Float64.
+func sumType(args []FunctionArg) *DataType {
+	if len(args) != 1 || args[0].Type == nil {
+		return nil
+	}
+	t := args[0].Type.Unwrap()
+	switch {
+	case t.IsInteger():
+		info := integerTypes[t.Name]
+		return &DataType{Name: integerTypeName(max(info.bits, 64), info.signed)}
+	case t.IsFloat():
+		return &DataType{Name: "Float64"}
+	case t.Name == "Decimal":
+		return &DataType{Name: "Decimal", Precision: 38, Scale: t.Scale}
+	}
+	return nil
+}
+
+func arrayType(args []FunctionArg) *DataType {
+	if len(args) == 0 {
+		return &DataType{Name: "Array", Elem: &DataType{Name: "Nothing"}}
+	}
+	if elem := commonArgType(args); elem != nil {
+		return &DataType{Name: "Array", Elem: elem}
+	}
+	return nil
+}
+
+// arrayMapType returns an array of the type of the lambda body.
+func arrayMapType(args []FunctionArg) *DataType {
+	if len(args) < 2 || args[0].Type == nil {
+		return nil
+	}
+	return &DataType{Name: "Array", Elem: args[0].Type}
+}
+
+func tupleType(args []FunctionArg) *DataType {
+	tuple := &DataType{Name: "Tuple"}
+	for _, arg := range args {
+		if arg.Type == nil {
+			return nil
+		}
+		tuple.Fields = append(tuple.Fields, DataTypeField{Type: arg.Type})
+	}
+	return tuple
+}
+
+func tupleElementType(args []FunctionArg) *DataType {
+	if len(args) != 2 || args[0].Type == nil {
+		return nil
+	}
+	switch index := unwrapColumnExpr(args[1].Expr).(type) {
+	case *NumberLiteral:
+		n, ok := index.Value().(int64)
+		if fields := args[0].Type.Fields; ok && args[0].Type.Name == "Tuple" && n >= 1 && int(n) <= len(fields) {
+			return fields[n-1].Type
+		}
+	case *StringLiteral:
+		return subcolumnType(args[0].Type, index.Value())
+	}
+	return nil
+}
+
+func mapType(args []FunctionArg) *DataType {
+	if len(args)%2 != 0 {
+		return nil
+	}
+	if len(args) == 0 {
+		return &DataType{Name: "Map", Key: &DataType{Name: "Nothing"}, Value: &DataType{Name: "Nothing"}}
+	}
+	var keys, values []*DataType
+	for i := 0; i < len(args); i += 2 {
+		keys = append(keys, args[i].Type)
+		values = append(values, args[i+1].Type)
+	}
+	key, value := commonType(keys...), commonType(values...)
+	if key == nil || value == nil {
+		return nil
+	}
+	return &DataType{Name: "Map", Key: key, Value: value}
+}
+
+func mapPartType(keys bool) ReturnTypeFunc {
+	return func(args []FunctionArg) *DataType {
+		if len(args) != 1 || args[0].Type == nil {
+			return nil
+		}
+		if keys {
+			return subcolumnType(args[0].Type, "keys")
+		}
+		return subcolumnType(args[0].Type, "values")
+	}
+}
+
+// literalArg returns the formatted value of a constant argument, such as
+// the scale of toDecimal32(x, 2).
+func literalArg(args []FunctionArg, i int) (string, bool) {
+	if i >= len(args) {
+		return "", false
+	}
+	switch literal := unwrapColumnExpr(args[i].Expr).(type) {
+	case *NumberLiteral:
+		return literal.Literal, true
+	case *StringLiteral:
+		return Format(literal), true
+	}
+	return "", false
+}
+
+func dateTimeConversionType(args []FunctionArg) *DataType {
+	if timezone, ok := literalArg(args, 1); ok {
+		t, _ := ParseDataType("DateTime(" + timezone + ")")
+		return t
+	}
+	return &DataType{Name: "DateTime"}
+}
+
+func dateTime64ConversionType(args []FunctionArg) *DataType {
+	params, ok := literalArg(args, 1)
+	if !ok {
+		return nil
+	}
+	if timezone, ok := literalArg(args, 2); ok {
+		params += ", " + timezone
+	}
+	t, _ := ParseDataType("DateTime64(" + params + ")")
+	return t
+}
+
+func fixedStringConversionType(args []FunctionArg) *DataType {
+	length, ok := literalArg(args, 1)
+	if !ok {
+		return nil
+	}
+	t, _ := ParseDataType("FixedString(" + length + ")")
+	return t
+}
+
+func decimalConversionType(precision int) ReturnTypeFunc {
+	return func(args []FunctionArg) *DataType {
+		scale, ok := literalArg(args, 1)
+		if !ok {
+			return nil
+		}
+		t, _ := ParseDataType(fmt.Sprintf("Decimal(%d, %s)", precision, scale))
+		return t
+	}
+}
@@ -1,5 +1,10 @@
 package parser
 
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
 func IsDigit(c byte) bool {
 	return '0' <= c && c <= '9'
 }
@@ -15,3 +20,21 @@ func IsIdentStart(c byte) bool {
 func IsIdentPart(c byte) bool {
 	return '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c == '$'
 }
+
+// IsIdentStartRune is IsIdentStart extended to UTF-8: like ClickHouse, bare
+// identifiers may also start with a letter from any script.
+func IsIdentStartRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return IsIdentStart(byte(r))
+	}
+	return unicode.IsLetter(r)
+}
+
+// IsIdentPartRune is IsIdentPart extended to UTF-8 letters, digits and
+// combining marks.
+func IsIdentPartRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return IsIdentPart(byte(r))
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc)
+}
@@ -28,6 +28,7 @@ const (
 	KeywordCast         = "CAST"
 	KeywordCheck        = "CHECK"
 	KeywordClear        = "CLEAR"
+	KeywordClone        = "CLONE"
 	KeywordCluster      = "CLUSTER"
 	KeywordCodec        = "CODEC"
 	KeywordCollate      = "COLLATE"
@@ -36,6 +37,7 @@ const (
 	KeywordColumns      = "COLUMNS"
 	KeywordComment      = "COMMENT"
 	KeywordCompiled     = "COMPILED"
+	KeywordCompression  = "COMPRESSION"
 	KeywordConfig       = "CONFIG"
 	KeywordConstraint   = "CONSTRAINT"
 	KeywordCreate       = "CREATE"
@@ -68,6 +70,7 @@ const (
 	KeywordEmpty        = "EMPTY"
 	KeywordEnd          = "END"
 	KeywordEngine       = "ENGINE"
+	KeywordEphemeral    = "EPHEMERAL"
 	KeywordEstimate     = "ESTIMATE"
 	KeywordEvents       = "EVENTS"
 	KeywordEvery        = "EVERY"
@@ -77,6 +80,7 @@ const (
 	KeywordExpression   = "EXPRESSION"
 	KeywordExtract      = "EXTRACT"
 	KeywordFalse        = "FALSE"
+	KeywordFetch        = "FETCH"
 	KeywordFetches      = "FETCHES"
 	KeywordFileSystem   = "FILESYSTEM"
 	KeywordFill         = "FILL"
@@ -128,6 +132,7 @@ const (
 	KeywordLdap         = "LDAP"
 	KeywordLeading      = "LEADING"
 	KeywordLeft         = "LEFT"
+	KeywordLevel        = "LEVEL"
 	KeywordLifetime     = "LIFETIME"
 	KeywordLike         = "LIKE"
 	KeywordLimit        = "LIMIT"
@@ -149,6 +154,7 @@ const (
 	KeywordName         = "NAME"
 	KeywordNamed        = "NAMED"
 	KeywordNan_sql      = "NAN_SQL"
+	KeywordNext         = "NEXT"
 	KeywordNo           = "NO"
 	KeywordNone         = "NONE"
 	KeywordNot          = "NOT"
@@ -156,6 +162,7 @@ const (
 	KeywordNulls        = "NULLS"
 	KeywordOffset       = "OFFSET"
 	KeywordOn           = "ON"
+	KeywordOnly         = "ONLY"
 	KeywordOptimize     = "OPTIMIZE"
 	KeywordOption       = "OPTION"
 	KeywordOr           = "OR"
@@ -167,6 +174,7 @@ const (
 	KeywordOverlay      = "OVERLAY"
 	KeywordOverlayUTF8  = "OVERLAYUTF8"
 	KeywordPartition    = "PARTITION"
+	KeywordPermanently  = "PERMANENTLY"
 	KeywordPlacing      = "PLACING"
 	KeywordPipeline     = "PIPELINE"
 	KeywordPolicy       = "POLICY"
@@ -215,7 +223,9 @@ const (
 	KeywordSource       = "SOURCE"
 	KeywordStart        = "START"
 	KeywordStaleness    = "STALENESS"
+	KeywordStatistics   = "STATISTICS"
 	KeywordStep         = "STEP"
+	KeywordStdout       = "STDOUT"
 	KeywordStop         = "STOP"
 	KeywordSubstring    = "SUBSTRING"
 	KeywordSync         = "SYNC"
@@ -356,6 +366,7 @@ var keywords = NewSet(
 	KeywordCast,
 	KeywordCheck,
 	KeywordClear,
+	KeywordClone,
 	KeywordCluster,
 	KeywordCodec,
 	KeywordCollate,
@@ -364,6 +375,7 @@ var keywords = NewSet(
 	KeywordColumns,
 	KeywordComment,
 	KeywordCompiled,
+	KeywordCompression,
 	KeywordConfig,
 	KeywordConstraint,
 	KeywordCreate,
@@ -394,6 +406,7 @@ var keywords = NewSet(
 	KeywordElse,
 	KeywordEnd,
 	KeywordEngine,
+	KeywordEphemeral,
 	KeywordEstimate,
 	KeywordEmbedded,
 	KeywordEmpty,
@@ -405,6 +418,7 @@ var keywords = NewSet(
 	KeywordExpression,
 	KeywordExtract,
 	KeywordFalse,
+	KeywordFetch,
 	KeywordFetches,
 	KeywordFileSystem,
 	KeywordFill,
@@ -456,6 +470,7 @@ var keywords = NewSet(
 	KeywordLdap,
 	KeywordLeading,
 	KeywordLeft,
+	KeywordLevel,
 	KeywordLifetime,
 	KeywordLike,
 	KeywordLimit,
@@ -477,6 +492,7 @@ var keywords = NewSet(
 	KeywordName,
 	KeywordNamed,
 	KeywordNan_sql,
+	KeywordNext,
 	KeywordNo,
 	KeywordNone,
 	KeywordNot,
@@ -484,6 +500,7 @@ var keywords = NewSet(
 	KeywordNulls,
 	KeywordOffset,
 	KeywordOn,
+	KeywordOnly,
 	KeywordOptimize,
 	KeywordOption,
 	KeywordOr,
@@ -495,6 +512,7 @@ var keywords = NewSet(
 	KeywordOverlay,
 	KeywordOverlayUTF8,
 	KeywordPartition,
+	KeywordPermanently,
 	KeywordPipeline,
 	KeywordPlacing,
 	KeywordPolicy,
@@ -543,7 +561,9 @@ var keywords = NewSet(
 	KeywordSource,
 	KeywordStart,
 	KeywordStaleness,
+	KeywordStatistics,
 	KeywordStep,
+	KeywordStdout,
 	KeywordStop,
 	KeywordSubstring,
 	KeywordSync,
@@ -23,6 +23,7 @@ const (
 	TokenKindLE           TokenKind = "<="
 	TokenKindGT           TokenKind = ">"
 	TokenKindGE           TokenKind = ">="
+	TokenKindNullSafeEQ   TokenKind = "<=>"
 	TokenKindQuestionMark TokenKind = "?"
 
 	TokenKindPlus   TokenKind = "+"
@@ -61,10 +62,12 @@ type Token struct {
 	Pos Pos
 	End Pos
 
-	Kind      TokenKind
-	String    string
-	Base      int // 10 or 16 on TokenKindInt
-	QuoteType int
+	Kind       TokenKind
+	String     string
+	Base       int // 10, 16 or 2 on TokenKindInt and TokenKindFloat
+	QuoteType  int
+	StringKind StringKind // spelling of a TokenKindString
+	HeredocTag string     // tag of a StringKindHeredoc string
 }
 
 func (t *Token) ToString() string {
@@ -77,6 +80,9 @@ func (t *Token) ToString() string {
 type lexerState struct {
 	offset       int    // byte offset into input of the next unread character
 	currentToken *Token // current lookahead token; nil at end of input
+	// err is the error of the token that failed to lex. The lexer stops
+	// there: currentToken stays nil and consumeToken keeps returning err.
+	err error
 }
 
 type Lexer struct {
@@ -113,6 +119,26 @@ func (l *Lexer) peekOk(n int) bool {
 	return l.offset+n < len(l.input)
 }
 
+// identPartLen returns the byte length of the identifier character at offset
+// i from the current position, or 0 if there is none. Non-ASCII characters are
+// decoded as UTF-8 runes.
+func (l *Lexer) identPartLen(i int) int {
+	if !l.peekOk(i) {
+		return 0
+	}
+	if c := l.peekN(i); c < utf8.RuneSelf {
+		if IsIdentPart(c) {
+			return 1
+		}
+		return 0
+	}
+	r, size := utf8.DecodeRuneInString(l.input[l.offset+i:])
+	if IsIdentPartRune(r) {
+		return size
+	}
+	return 0
+}
+
 func (l *Lexer) isKeyword(ident string) bool {
 	return keywords.Contains(ident)
 }
@@ -124,9 +150,22 @@ func (l *Lexer) consumeNumber() error {
 		// skip sign
 		i++
 	}
-	if l.peekOk(i+1) && l.peekN(i) == '0' && l.peekN(i+1) == 'x' {
-		i += 2
-		base = 16
+	if l.peekOk(i+1) && l.peekN(i) == '0' {
+		switch l.peekN(i + 1) {
+		case 'x', 'X':
+			i += 2
+			base = 16
+		case 'b', 'B':
+			i += 2
+			base = 2
+		}
+	}
+	isDigit := IsDigit
+	switch base {
+	case 16:
+		isDigit = IsHexDigit
+	case 2:
+		isDigit = isBinaryDigit
 	}
 
 	hasExp := false
@@ -139,25 +178,29 @@ func (l *Lexer) consumeNumber() error {
 	for l.peekOk(i) {
 		c := l.peekN(i)
 		switch {
-		case base == 10 && IsDigit(c):
+		case isDigit(c):
 			hasNumberPart = true
 			i++
 			continue
-		case base == 16 && IsHexDigit(c):
-			hasNumberPart = true
+		case c == '_':
+			// a digit separator ("1_000_000") must sit between two digits
+			if !isDigit(l.peekN(i-1)) || !l.peekOk(i+1) || !isDigit(l.peekN(i+1)) {
+				return errors.New("invalid number")
+			}
 			i++
 			continue
 		case c == '.': // float
 			// a second dot ("1.2.3"), a dot after the exponent ("1e2.3") or
-			// a dot in a hex literal ("0x1.8") cannot start a valid number tail
-			if hasDot || hasExp || base == 16 {
+			// a dot in a binary literal ("0b1.1") cannot start a valid number tail
+			if hasDot || hasExp || base == 2 {
 				return errors.New("invalid number")
 			}
 			hasDot = true
 			tokenKind = TokenKindFloat
 			i++
 			continue
-		case base != 16 && (c == 'e' || c == 'E'):
+		case base == 10 && (c == 'e' || c == 'E'),
+			base == 16 && (c == 'p' || c == 'P'): // hex floats use a binary exponent: 0x1.8p3
 			if hasExp {
 				return errors.New("invalid number")
 			}
@@ -171,11 +214,13 @@ func (l *Lexer) consumeNumber() error {
 			hasExp = true
 			// scientific notation always denotes a floating-point value
 			tokenKind = TokenKindFloat
+			// the exponent is decimal even in a hex float
+			isDigit = IsDigit
 			continue
 		}
 		break
 	}
-	if (l.peekOk(i) && IsIdentPart(l.peekN(i))) || !hasNumberPart {
+	if l.identPartLen(i) > 0 || !hasNumberPart {
 		return errors.New("invalid number")
 	}
 	l.currentToken = &Token{
@@ -206,16 +251,31 @@ func (l *Lexer) consumeIdent(_ Pos) error {
 		if l.peekOk(i) && l.peekN(i) == '$' {
 			i++
 		}
-		for l.peekOk(i) && IsIdentPart(l.peekN(i)) {
-			i++
+		for size := l.identPartLen(i); size > 0; size = l.identPartLen(i) {
+			i += size
 		}
 	} else {
-		for l.peekOk(i) && (quoteType == BackTicks && l.peekN(i) != '`' ||
-			quoteType == DoubleQuote && l.peekN(i) != '"') {
+		quote := byte('`')
+		if quoteType == DoubleQuote {
+			quote = '"'
+		}
+		for l.peekOk(i) {
+			c := l.peekN(i)
+			if c == '\\' && l.peekOk(i+1) {
+				i += 2
+				continue
+			}
+			if c == quote {
+				// a doubled quote stands for the quote character itself
+				if l.peekOk(i+1) && l.peekN(i+1) == quote {
+					i += 2
+					continue
+				}
+				break
+			}
 			i++
 		}
-		if !l.peekOk(i) || (quoteType == BackTicks && l.peekN(i) != '`') ||
-			(quoteType == DoubleQuote && l.peekN(i) != '"') {
+		if !l.peekOk(i) {
 			return fmt.Errorf("unclosed quoted identifier: %s", l.slice(0, i))
 		}
 	}
@@ -225,6 +285,13 @@ func (l *Lexer) consumeIdent(_ Pos) error {
 	} else {
 		token.Kind = TokenKindIdent
 	}
+	// Quoted identifiers carry their decoded name; the formatter escapes it again.
+	switch quoteType {
+	case BackTicks:
+		slice = unescape(slice, '`')
+	case DoubleQuote:
+		slice = unescape(slice, '"')
+	}
 	token.Pos = Pos(l.offset)
 	token.End = Pos(l.offset + i)
 	token.String = slice
@@ -302,6 +369,70 @@ func (l *Lexer) consumeString() error {
 	return nil
 }
 
+// consumeHeredoc consumes a $tag$ ... $tag$ string whose opening delimiter is
+// delimLen bytes long. The body is taken verbatim, without escapes.
+func (l *Lexer) consumeHeredoc(delimLen int) error {
+	delim := l.slice(0, delimLen)
+	end := strings.Index(l.input[l.offset+delimLen:], delim)
+	if end < 0 {
+		return fmt.Errorf("unclosed heredoc string: %s", delim)
+	}
+	l.currentToken = &Token{
+		Kind:       TokenKindString,
+		String:     l.slice(delimLen, delimLen+end),
+		Pos:        Pos(l.offset + delimLen),
+		End:        Pos(l.offset + delimLen + end),
+		StringKind: StringKindHeredoc,
+		HeredocTag: delim[1 : delimLen-1],
+	}
+	l.skipN(delimLen + end + delimLen)
+	return nil
+}
+
+// peekHeredocDelim returns the length of the $tag$ delimiter at the current
+// offset, or 0 when the '$' does not open a heredoc.
+func (l *Lexer) peekHeredocDelim() int {
+	i := 1
+	for l.peekOk(i) && (IsIdentStart(l.peekN(i)) || IsDigit(l.peekN(i))) {
+		i++
+	}
+	if l.peekOk(i) && l.peekN(i) == '$' {
+		return i + 1
+	}
+	return 0
+}
+
+// consumeBitString consumes a hex x'..' or binary b'..' string literal.
+func (l *Lexer) consumeBitString() error {
+	kind, isDigit, name := StringKindHex, IsHexDigit, "hex"
+	if l.peekN(0) == 'b' || l.peekN(0) == 'B' {
+		kind, isDigit, name = StringKindBinary, isBinaryDigit, "binary"
+	}
+	i := 2
+	for l.peekOk(i) && l.peekN(i) != '\'' {
+		if !isDigit(l.peekN(i)) {
+			return fmt.Errorf("invalid digit %q in %s string literal", l.peekN(i), name)
+		}
+		i++
+	}
+	if !l.peekOk(i) {
+		return errors.New("invalid string")
+	}
+	l.currentToken = &Token{
+		Kind:       TokenKindString,
+		String:     l.slice(2, i),
+		Pos:        Pos(l.offset + 2),
+		End:        Pos(l.offset + i),
+		StringKind: kind,
+	}
+	l.skipN(i + 1)
+	return nil
+}
+
+func isBinaryDigit(c byte) bool {
+	return c == '0' || c == '1'
+}
+
 func (l *Lexer) skipComments() error {
 	for !l.isEOF() {
 		l.skipSpace()
@@ -335,13 +466,11 @@ func (l *Lexer) skipComments() error {
 
 func (l *Lexer) peekToken() (*Token, error) {
 	savedState := l.saveState()
+	defer l.restoreState(savedState)
 	if err := l.consumeToken(); err != nil {
 		return nil, err
 	}
-	token := l.currentToken
-
-	l.restoreState(savedState)
-	return token, nil
+	return l.currentToken, nil
 }
 
 func (l *Lexer) hasPrecedenceToken(last *Token) bool {
@@ -357,6 +486,13 @@ func (l *Lexer) hasPrecedenceToken(last *Token) bool {
 }
 
 func (l *Lexer) consumeToken() error {
+	if l.err == nil {
+		l.err = l.nextToken()
+	}
+	return l.err
+}
+
+func (l *Lexer) nextToken() error {
 	// replace the current token; keep the previous one to disambiguate unary +/-
 	prevToken := l.currentToken
 	l.currentToken = nil
@@ -369,6 +505,16 @@ func (l *Lexer) consumeToken() error {
 	}
 	switch l.peekN(0) {
 	case '>', '<', '!', '=', '|':
+		if l.peekN(0) == '<' && l.peekOk(2) && l.peekN(1) == '=' && l.peekN(2) == '>' { // <=>
+			l.currentToken = &Token{
+				String: l.slice(0, 3),
+				Kind:   TokenKindNullSafeEQ,
+				Pos:    Pos(l.offset),
+				End:    Pos(l.offset + 3),
+			}
+			l.skipN(3)
+			return nil
+		}
 		if l.peekN(0) == '|' && l.peekOk(1) && l.peekN(1) == '|' || // ||
 			l.peekN(0) == '<' && l.peekOk(1) && l.peekN(1) == '>' || // <>
 			l.peekN(0) == '=' && l.peekOk(1) && l.peekN(1) == '=' || // ==
@@ -399,8 +545,17 @@ func (l *Lexer) consumeToken() error {
 		}
 	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
 		return l.consumeNumber()
-	case '`', '$', '"':
+	case '$':
+		if delimLen := l.peekHeredocDelim(); delimLen > 0 {
+			return l.consumeHeredoc(delimLen)
+		}
 		return l.consumeIdent(Pos(l.offset))
+	case '`', '"':
+		return l.consumeIdent(Pos(l.offset))
+	case 'x', 'X', 'b', 'B':
+		if l.peekOk(1) && l.peekN(1) == '\'' {
+			return l.consumeBitString()
+		}
 	case '\'':
 		return l.consumeString()
 	case ':':
@@ -429,11 +584,14 @@ func (l *Lexer) consumeToken() error {
 		return l.consumeIdent(Pos(l.offset))
 	}
 
-	// Non-ASCII bytes can only appear inside quoted identifiers or string
-	// literals, which are handled above. Report the whole rune instead of
-	// emitting a one-byte token that splits the UTF-8 sequence.
+	// Outside quotes, a non-ASCII character must be a letter starting an
+	// identifier. Report any other rune whole instead of emitting a one-byte
+	// token that splits the UTF-8 sequence.
 	if l.peekN(0) >= utf8.RuneSelf {
 		r, _ := utf8.DecodeRuneInString(l.input[l.offset:])
+		if IsIdentStartRune(r) {
+			return l.consumeIdent(Pos(l.offset))
+		}
 		return fmt.Errorf("unexpected character %q", r)
 	}
 
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"math"
+	"math/big"
 	"strings"
 	"testing"
 	"time"
@@ -171,6 +173,45 @@ func TestConsumeNumber(t *testing.T) {
 		}
 	})
 
+	t.Run("Uppercase hexadecimal prefix", func(t *testing.T) {
+		lexer := NewLexer("0X1F")
+		require.NoError(t, lexer.consumeToken())
+		require.Equal(t, TokenKindInt, lexer.currentToken.Kind)
+		require.Equal(t, 16, lexer.currentToken.Base)
+		require.Equal(t, "0X1F", lexer.currentToken.String)
+	})
+
+	t.Run("Binary number", func(t *testing.T) {
+		for _, n := range []string{"0b1010", "0B1", "-0b11", "0b1111_0000"} {
+			lexer := NewLexer(n)
+			require.NoError(t, lexer.consumeToken(), "input %q", n)
+			require.Equal(t, TokenKindInt, lexer.currentToken.Kind)
+			require.Equal(t, 2, lexer.currentToken.Base)
+			require.Equal(t, n, lexer.currentToken.String)
+			require.True(t, lexer.isEOF())
+		}
+	})
+
+	t.Run("Digit separators", func(t *testing.T) {
+		for _, n := range []string{"1_000_000", "0xFF_FF", "1_000.000_1", "1e1_0"} {
+			lexer := NewLexer(n)
+			require.NoError(t, lexer.consumeToken(), "input %q", n)
+			require.Equal(t, n, lexer.currentToken.String)
+			require.True(t, lexer.isEOF())
+		}
+	})
+
+	t.Run("Hexadecimal float", func(t *testing.T) {
+		for _, n := range []string{"0x1.8p3", "0x1p-2", "0X1.8P+3", "0x1.8"} {
+			lexer := NewLexer(n)
+			require.NoError(t, lexer.consumeToken(), "input %q", n)
+			require.Equal(t, TokenKindFloat, lexer.currentToken.Kind)
+			require.Equal(t, 16, lexer.currentToken.Base)
+			require.Equal(t, n, lexer.currentToken.String)
+			require.True(t, lexer.isEOF())
+		}
+	})
+
 	t.Run("Invalid number", func(t *testing.T) {
 		invalidNumbers := []string{
 			"123e",
@@ -187,8 +228,16 @@ func TestConsumeNumber(t *testing.T) {
 			"1.2.3",
 			"1..2",
 			"1e2.3",
-			"0x1.8",
 			"1p5",
+			"0b",
+			"0b2",
+			"0b1.1",
+			"1__000",
+			"1_",
+			"0x_1",
+			"1_.5",
+			"1e_5",
+			"0x1p",
 			"1P5",
 		}
 		for _, n := range invalidNumbers {
@@ -326,13 +375,13 @@ func TestSingleLineCommentAtEOF(t *testing.T) {
 	require.LessOrEqual(t, lexer.offset, len(lexer.input))
 }
 
-// TestNonASCIIByteIsAnError guards that a bare multi-byte character produces
-// a readable error naming the whole rune instead of a garbage one-byte token
-// that splits the UTF-8 sequence.
+// TestNonASCIIByteIsAnError guards that a bare multi-byte character that
+// cannot start an identifier produces a readable error naming the whole rune
+// instead of a garbage one-byte token that splits the UTF-8 sequence.
 func TestNonASCIIByteIsAnError(t *testing.T) {
-	err := lexAll("SELECT 中文")
+	err := lexAll("SELECT €5")
 	require.Error(t, err)
-	require.Contains(t, err.Error(), "'中'")
+	require.Contains(t, err.Error(), "'€'")
 
 	// quoted identifiers and string literals may still carry non-ASCII text
 	_, err = NewParser("SELECT `中文`, '中文'").ParseStmts()
@@ -346,3 +395,193 @@ func TestNegativeHexLiteral(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, stmts, 1)
 }
+
+func TestConsumeHeredocString(t *testing.T) {
+	testCases := []struct {
+		input string
+		body  string
+		tag   string
+	}{
+		{`$$hello 'world'$$`, `hello 'world'`, ""},
+		{`$sql$SELECT $$x$$$sql$`, `SELECT $$x$$`, "sql"},
+		{`$$a\nb$$`, `a\nb`, ""},
+	}
+	for _, tc := range testCases {
+		lexer := NewLexer(tc.input)
+		require.NoError(t, lexer.consumeToken(), tc.input)
+		require.Equal(t, TokenKindString, lexer.currentToken.Kind)
+		require.Equal(t, StringKindHeredoc, lexer.currentToken.StringKind)
+		require.Equal(t, tc.body, lexer.currentToken.String)
+		require.Equal(t, tc.tag, lexer.currentToken.HeredocTag)
+		require.True(t, lexer.isEOF())
+	}
+
+	require.Error(t, lexAll(`SELECT $tag$never closed$$`))
+}
+
+func TestConsumeBitString(t *testing.T) {
+	lexer := NewLexer(`x'4142'`)
+	require.NoError(t, lexer.consumeToken())
+	require.Equal(t, StringKindHex, lexer.currentToken.StringKind)
+	require.Equal(t, "4142", lexer.currentToken.String)
+
+	lexer = NewLexer(`B'01000001'`)
+	require.NoError(t, lexer.consumeToken())
+	require.Equal(t, StringKindBinary, lexer.currentToken.StringKind)
+	require.Equal(t, "01000001", lexer.currentToken.String)
+
+	// a bare x or b is still an identifier
+	lexer = NewLexer(`x + 1`)
+	require.NoError(t, lexer.consumeToken())
+	require.Equal(t, TokenKindIdent, lexer.currentToken.Kind)
+
+	require.Error(t, lexAll(`SELECT x'4G'`))
+	require.Error(t, lexAll(`SELECT b'0102'`))
+	require.Error(t, lexAll(`SELECT x'41`))
+}
+
+// TestLexerErrorReachesParser guards that the parser reports why a token
+// could not be lexed instead of an unexpected end of input.
+func TestLexerErrorReachesParser(t *testing.T) {
+	testCases := []struct {
+		input string
+		msg   string
+	}{
+		{`SELECT x'zz'`, `line 1:8 invalid digit 'z' in hex string literal`},
+		{`SELECT 1, b'102'`, `line 1:11 invalid digit '2' in binary string literal`},
+		{"SELECT 1 /* unterminated", `unclosed multi-line comment`},
+	}
+	for _, tc := range testCases {
+		_, err := NewParser(tc.input).ParseStmts()
+		require.Error(t, err, tc.input)
+		require.Contains(t, err.Error(), tc.msg, tc.input)
+	}
+}
+
+func TestQuotedIdentEscapes(t *testing.T) {
+	testCases := []struct {
+		input string
+		name  string
+	}{
+		{"`a\\`b`", "a`b"},
+		{"`a``b`", "a`b"},
+		{`"a\"b"`, `a"b`},
+		{`"a""b"`, `a"b`},
+		{"`a\\\\b`", `a\b`},
+		{"`tab\\there`", "tab\there"},
+	}
+	for _, tc := range testCases {
+		lexer := NewLexer(tc.input)
+		require.NoError(t, lexer.consumeToken(), tc.input)
+		require.Equal(t, TokenKindIdent, lexer.currentToken.Kind)
+		require.Equal(t, tc.name, lexer.currentToken.String)
+		require.True(t, lexer.isEOF())
+	}
+}
+
+func TestStringLiteralValue(t *testing.T) {
+	testCases := []struct {
+		sql   string
+		value string
+	}{
+		{`SELECT 'it''s'`, "it's"},
+		{`SELECT 'it\'s'`, "it's"},
+		{`SELECT 'a\nb\tc\0'`, "a\nb\tc\x00"},
+		{`SELECT 'a\\b'`, `a\b`},
+		{`SELECT '\d+\.\%'`, `\d+\.\%`},
+		{`SELECT '\x41\x4a'`, "AJ"},
+		{`SELECT $$raw \n 'text'$$`, `raw \n 'text'`},
+		{`SELECT x'4142'`, "AB"},
+		{`SELECT x'141'`, "\x01A"},
+		{`SELECT b'0100000101000010'`, "AB"},
+		{`SELECT b'1'`, "\x01"},
+	}
+	for _, tc := range testCases {
+		expr := parseSelectItemExpr(t, tc.sql)
+		str, ok := expr.(*StringLiteral)
+		require.True(t, ok, "expected *StringLiteral, got %T", expr)
+		require.Equal(t, tc.value, str.Value(), tc.sql)
+	}
+}
+
+func TestQuoteStringRoundTrip(t *testing.T) {
+	for _, value := range []string{"", "plain", "it's", `a\b`, "line\nbreak\ttab\r", "nul\x00", "bell\x07", `\d+`, "中文"} {
+		quoted := QuoteString(value)
+		expr := parseSelectItemExpr(t, "SELECT "+quoted)
+		str, ok := expr.(*StringLiteral)
+		require.True(t, ok)
+		require.Equal(t, value, str.Value(), quoted)
+	}
+}
+
+func TestNumberLiteralValue(t *testing.T) {
+	huge, _ := new(big.Int).SetString("-99999999999999999999", 10)
+	testCases := []struct {
+		literal string
+		value   any
+	}{
+		{"42", int64(42)},
+		{"-42", int64(-42)},
+		{"1_000_000", int64(1000000)},
+		{"0x1F", int64(31)},
+		{"0XfF", int64(255)},
+		{"-0x1F", int64(-31)},
+		{"0b1010", int64(10)},
+		{"010", int64(10)},
+		{"18446744073709551615", uint64(math.MaxUint64)},
+		{"-99999999999999999999", huge},
+		{"1.5", 1.5},
+		{".5", 0.5},
+		{"1e3", 1000.0},
+		{"0x1.8p3", 12.0},
+		{"0x1.8", 1.5},
+		{"1e400", math.Inf(1)},
+		{"-inf", math.Inf(-1)},
+		{"+Infinity", math.Inf(1)},
+		{"not a number", nil},
+	}
+	for _, tc := range testCases {
+		number := &NumberLiteral{Literal: tc.literal}
+		require.Equal(t, tc.value, number.Value(), tc.literal)
+	}
+}
+
+func TestInfAndNaNLiterals(t *testing.T) {
+	for sql, literal := range map[string]string{
+		"SELECT inf":      "inf",
+		"SELECT -inf":     "-inf",
+		"SELECT Infinity": "Infinity",
+		"SELECT nan":      "nan",
+		"SELECT NaN, 1":   "NaN",
+	} {
+		number, ok := parseSelectItemExpr(t, sql).(*NumberLiteral)
+		require.True(t, ok, sql)
+		require.Equal(t, literal, number.Literal)
+		_, isFloat := number.Value().(float64)
+		require.True(t, isFloat, sql)
+	}
+
+	// called or qualified, inf and nan are still names
+	_, ok := parseSelectItemExpr(t, "SELECT nan(x)").(*FunctionExpr)
+	require.True(t, ok)
+	_, ok = parseSelectItemExpr(t, "SELECT inf.x").(*NumberLiteral)
+	require.False(t, ok)
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	testCases := []string{"中文", "имя_столбца", "café", "naïve2", "x_日本"}
+	for _, name := range testCases {
+		lexer := NewLexer(name + " ")
+		require.NoError(t, lexer.consumeToken(), name)
+		require.Equal(t, TokenKindIdent, lexer.currentToken.Kind)
+		require.Equal(t, name, lexer.currentToken.String)
+		require.Equal(t, Pos(len(name)), lexer.currentToken.End)
+	}
+
+	// a number may not run into a letter, in any script
+	require.Error(t, lexAll("SELECT 1é"))
+
+	stmts, err := NewParser("SELECT имя, sum(数量) AS 合計 FROM таблица GROUP BY имя").ParseStmts()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT имя, sum(数量) AS 合計 FROM таблица GROUP BY имя", Format(stmts[0]))
+}
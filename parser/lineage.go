@@ -0,0 +1,366 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SchemaProvider tells lineage and other analyses which columns a table has.
+type SchemaProvider interface {
+	// TableColumns returns the columns of a table in definition order, or
+	// nil if the table is unknown. database is empty for unqualified tables.
+	TableColumns(database, table string) []string
+}
+
+// MapSchema is a SchemaProvider backed by a map from "database.table" or
+// "table" to column names. Qualified keys take precedence.
+type MapSchema map[string][]string
+
+func (m MapSchema) TableColumns(database, table string) []string {
+	if database != "" {
+		if columns, ok := m[database+"."+table]; ok {
+			return columns
+		}
+	}
+	return m[table]
+}
+
+// ColumnSource is a column of a source table.
+type ColumnSource struct {
+	Database string
+	Table    string
+	Column   string
+}
+
+func (s ColumnSource) String() string {
+	if s.Database == "" {
+		return s.Table + "." + s.Column
+	}
+	return s.Database + "." + s.Table + "." + s.Column
+}
+
+// ColumnLineage is an output column and the source table columns its value
+// is computed from. Columns only used to filter, group, join or order rows
+// are not sources.
+type ColumnLineage struct {
+	Column  string
+	Sources []ColumnSource
+}
+
+// Lineage maps the output columns of a SELECT, an INSERT ... SELECT or a
+// CREATE MATERIALIZED VIEW to the table columns they derive from. For an
+// INSERT, the output columns are those of the target table.
+//
+// schema may be nil. Without it, * over a table is reported as a single
+// "*" column whose source is table.*, and an unqualified column is only
+// resolved when a single table could hold it.
+func Lineage(stmt Expr, schema SchemaProvider) ([]ColumnLineage, error) {
+	switch s := stmt.(type) {
+	case *SelectQuery:
+		analyzer := newLineageAnalyzer(schema, LambdaBindings(s), nil)
+		return analyzer.lineage(analyzer.resolver.query(s, nil)), nil
+	case *CreateMaterializedView:
+		if s.SubQuery == nil || s.SubQuery.Select == nil {
+			return nil, errors.New("materialized view has no query")
+		}
+		analyzer := newLineageAnalyzer(schema, LambdaBindings(s.SubQuery), nil)
+		return analyzer.lineage(analyzer.resolver.query(s.SubQuery.Select, nil)), nil
+	case *InsertStmt:
+		if s.SelectExpr == nil {
+			return nil, errors.New("INSERT has no SELECT")
+		}
+		return newLineageAnalyzer(schema, LambdaBindings(s.SelectExpr), nil).insertLineage(s)
+	case nil:
+		return nil, errors.New("no statement")
+	default:
+		return nil, fmt.Errorf("lineage is not supported for %T", stmt)
+	}
+}
+
+type lineageAnalyzer struct {
+	schema      SchemaProvider
+	lambdaBound map[*Ident]*LambdaExpr
+	// validator is set by Validate to check the references it resolves.
+	validator *validator
+	resolver  *resolver[[]ColumnSource]
+}
+
+func newLineageAnalyzer(schema SchemaProvider, lambdaBound map[*Ident]*LambdaExpr, validator *validator) *lineageAnalyzer {
+	analyzer := &lineageAnalyzer{schema: schema, lambdaBound: lambdaBound, validator: validator}
+	analyzer.resolver = &resolver[[]ColumnSource]{analysis: analyzer}
+	return analyzer
+}
+
+// lineageColumn is a column and the sources of its value.
+type lineageColumn = scopeColumn[[]ColumnSource]
+
+// lineageScope holds the names visible in one SELECT.
+type lineageScope = scope[[]ColumnSource]
+
+// lineageRelation is a table, subquery or CTE in a FROM clause.
+type lineageRelation = relation[[]ColumnSource]
+
+func (a *lineageAnalyzer) lineage(columns []lineageColumn) []ColumnLineage {
+	result := make([]ColumnLineage, len(columns))
+	for i, column := range columns {
+		result[i] = ColumnLineage{Column: column.name, Sources: column.value}
+	}
+	return result
+}
+
+func (a *lineageAnalyzer) insertLineage(s *InsertStmt) ([]ColumnLineage, error) {
+	columns := a.resolver.query(s.SelectExpr, nil)
+	var targets []string
+	switch {
+	case s.ColumnNames != nil:
+		for _, name := range s.ColumnNames.ColumnNames {
+			targets = append(targets, nestedIdentifierName(&name))
+		}
+	case a.schema != nil:
+		if table, ok := s.Table.(*TableIdentifier); ok {
+			var database string
+			if table.Database != nil {
+				database = table.Database.Name
+			}
+			targets = a.schema.TableColumns(database, table.Table.Name)
+		}
+	}
+	if targets == nil {
+		return a.lineage(columns), nil
+	}
+	if len(targets) != len(columns) {
+		return nil, fmt.Errorf("INSERT has %d target columns but SELECT returns %d", len(targets), len(columns))
+	}
+	for i := range columns {
+		columns[i].name = targets[i]
+	}
+	return a.lineage(columns), nil
+}
+
+func (a *lineageAnalyzer) expr(expr Expr, scope *lineageScope) []ColumnSource {
+	return a.exprSources(expr, scope)
+}
+
+func (a *lineageAnalyzer) tableColumns(table *TableIdentifier) []lineageColumn {
+	var columns []lineageColumn
+	if a.schema != nil {
+		var database string
+		if table.Database != nil {
+			database = table.Database.Name
+		}
+		for _, column := range a.schema.TableColumns(database, table.Table.Name) {
+			columns = append(columns, lineageColumn{name: column, value: []ColumnSource{tableSource(table, column)}})
+		}
+	}
+	if a.validator != nil && columns == nil {
+		a.validator.checkTable(table)
+	}
+	return columns
+}
+
+// unknownColumn attributes a column to the table of a relation whose
+// columns are unknown.
+func (a *lineageAnalyzer) unknownColumn(relation *lineageRelation, name string) ([]ColumnSource, bool) {
+	if table := relation.table(); table != nil {
+		return []ColumnSource{tableSource(table, name)}, true
+	}
+	return nil, false
+}
+
+// unknownStar reports * over a table whose columns are unknown as a single
+// "*" column whose source is table.*.
+func (a *lineageAnalyzer) unknownStar(relation *lineageRelation) []lineageColumn {
+	if table := relation.table(); table != nil {
+		return []lineageColumn{{name: "*", value: []ColumnSource{tableSource(table, "*")}}}
+	}
+	return nil
+}
+
+// element returns the sources of the elements of an array, which are those
+// of the array.
+func (a *lineageAnalyzer) element(sources []ColumnSource) ([]ColumnSource, bool) {
+	return sources, true
+}
+
+func (a *lineageAnalyzer) field(sources []ColumnSource, name string) []ColumnSource {
+	return sources
+}
+
+func (a *lineageAnalyzer) merge(sources, more []ColumnSource) []ColumnSource {
+	return appendSources(sources, more...)
+}
+
+func (a *lineageAnalyzer) clauses(q *SelectQuery, scope *lineageScope) {
+	if a.validator != nil {
+		a.validator.checkClauses(q, scope)
+	}
+}
+
+func tableSource(table *TableIdentifier, column string) ColumnSource {
+	source := ColumnSource{Table: table.Table.Name, Column: column}
+	if table.Database != nil {
+		source.Database = table.Database.Name
+	}
+	return source
+}
+
+// exprSources returns the source columns an expression reads, including
+// those read by its subqueries.
+func (a *lineageAnalyzer) exprSources(expr Expr, scope *lineageScope) []ColumnSource {
+	if expr == nil {
+		return nil
+	}
+	collector := &lineageCollector{analyzer: a, scope: scope, skipped: make(map[*Ident]bool)}
+	_ = expr.Accept(collector)
+	return collector.sources
+}
+
+// lineageCollector gathers the column references of an expression. Nested
+// queries are resolved as a whole, so their contents are skipped.
+type lineageCollector struct {
+	DefaultASTVisitor
+	analyzer *lineageAnalyzer
+	scope    *lineageScope
+	sources  []ColumnSource
+	// depth counts the enclosing subtrees that hold no column references.
+	depth   int
+	skipped map[*Ident]bool
+}
+
+// skipsSubtree reports whether the collector leaves out the contents of
+// expr. Outside nested queries, PARTITION BY and ORDER BY clauses only
+// appear in window specifications.
+func (c *lineageCollector) skipsSubtree(expr Expr) bool {
+	switch expr.(type) {
+	case *SelectQuery, ColumnType, *PartitionByClause, *OrderByClause:
+		return true
+	}
+	return false
+}
+
+func (c *lineageCollector) Enter(expr Expr) {
+	if c.depth > 0 {
+		if c.skipsSubtree(expr) {
+			c.depth++
+		}
+		return
+	}
+	switch e := expr.(type) {
+	case *SelectQuery:
+		for _, column := range c.analyzer.resolver.query(e, c.scope) {
+			c.sources = appendSources(c.sources, column.value...)
+		}
+		c.depth++
+	case ColumnType, *PartitionByClause, *OrderByClause:
+		c.depth++
+	case *WindowExpr:
+		// the columns partitioning and ordering a window are not sources,
+		// but they are still validated
+		if c.analyzer.validator != nil {
+			if e.PartitionBy != nil {
+				c.analyzer.exprSources(e.PartitionBy.Expr, c.scope)
+			}
+			if e.OrderBy != nil {
+				for _, item := range e.OrderBy.Items {
+					c.analyzer.exprSources(item, c.scope)
+				}
+			}
+		}
+	case *FunctionExpr:
+		c.skipped[e.Name] = true
+	case *WindowFunctionExpr:
+		if ident, ok := e.OverExpr.(*Ident); ok {
+			c.skipped[ident] = true
+		}
+	case *IntervalExpr:
+		c.skipped[e.Unit] = true
+	case *IntervalFrom:
+		c.skipped[e.Interval] = true
+	case *BinaryOperation:
+		if e.Operation == TokenKindDash {
+			// the right-hand side of :: is a type, as in a::Nullable(String)
+			Walk(e.RightExpr, func(node Expr) bool {
+				if ident, ok := node.(*Ident); ok {
+					c.skipped[ident] = true
+				}
+				return true
+			})
+		}
+	case *LambdaExpr:
+		for _, param := range e.Params {
+			c.skipped[param] = true
+		}
+	case *QueryParam:
+		c.skipped[e.Name] = true
+	case *ColumnExpr:
+		c.skipped[e.Alias] = true
+	case *AliasExpr:
+		if ident, ok := e.Alias.(*Ident); ok {
+			c.skipped[ident] = true
+		}
+	case *NestedIdentifier, *Path:
+		parts := identifierParts(e)
+		Walk(e, func(node Expr) bool {
+			if ident, ok := node.(*Ident); ok {
+				c.skipped[ident] = true
+			}
+			return true
+		})
+		c.sources = appendSources(c.sources, c.analyzer.resolver.column(parts, c.scope)...)
+		if c.analyzer.validator != nil {
+			c.analyzer.validator.checkColumn(e, parts, c.scope)
+		}
+	case *Ident:
+		if c.skipped[e] || c.analyzer.lambdaBound[e] != nil || e.Name == "*" {
+			return
+		}
+		c.sources = appendSources(c.sources, c.analyzer.resolver.column([]string{e.Name}, c.scope)...)
+		if c.analyzer.validator != nil {
+			c.analyzer.validator.checkColumn(e, []string{e.Name}, c.scope)
+		}
+	}
+}
+
+func (c *lineageCollector) Leave(expr Expr) {
+	if c.depth > 0 && c.skipsSubtree(expr) {
+		c.depth--
+	}
+}
+
+func identifierParts(expr Expr) []string {
+	switch e := expr.(type) {
+	case *NestedIdentifier:
+		if e.DotIdent == nil {
+			return []string{e.Ident.Name}
+		}
+		return []string{e.Ident.Name, e.DotIdent.Name}
+	case *Path:
+		parts := make([]string, len(e.Fields))
+		for i, field := range e.Fields {
+			parts[i] = field.Name
+		}
+		return parts
+	}
+	return nil
+}
+
+func nestedIdentifierName(n *NestedIdentifier) string {
+	return strings.Join(identifierParts(n), ".")
+}
+
+func appendSources(sources []ColumnSource, more ...ColumnSource) []ColumnSource {
+	for _, source := range more {
+		duplicate := false
+		for _, existing := range sources {
+			if existing == source {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func lineageOf(t *testing.T, sql string, schema SchemaProvider) map[string][]string {
+	t.Helper()
+	stmts, err := NewParser(sql).ParseStmts()
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+	lineage, err := Lineage(stmts[0], schema)
+	require.NoError(t, err)
+	result := make(map[string][]string, len(lineage))
+	for _, column := range lineage {
+		sources := make([]string, 0, len(column.Sources))
+		for _, source := range column.Sources {
+			sources = append(sources, source.String())
+		}
+		result[column.Column] = sources
+	}
+	return result
+}
+
+func TestLineage(t *testing.T) {
+	schema := MapSchema{
+		"db.events": {"id", "user_id", "ts", "tags", "n.key", "n.value"},
+		"users":     {"id", "name", "country"},
+	}
+	tests := []struct {
+		name     string
+		sql      string
+		expected map[string][]string
+	}{
+		{
+			name: "expressions and aliases",
+			sql:  "SELECT id, toDate(ts) AS day, day AS d2, concat(u.name, '-', u.country) AS label FROM db.events e JOIN users u ON e.user_id = u.id",
+			expected: map[string][]string{
+				"id":    {"db.events.id"},
+				"day":   {"db.events.ts"},
+				"d2":    {"db.events.ts"},
+				"label": {"users.name", "users.country"},
+			},
+		},
+		{
+			name: "window partitioning and ordering are not sources",
+			sql:  "SELECT sum(id) OVER (PARTITION BY user_id ORDER BY ts) AS running, count() OVER w AS c FROM db.events WINDOW w AS (PARTITION BY user_id)",
+			expected: map[string][]string{
+				"running": {"db.events.id"},
+				"c":       {},
+			},
+		},
+		{
+			name: "qualified columns keep their name",
+			sql:  "SELECT e.id, db.events.ts FROM db.events AS e",
+			expected: map[string][]string{
+				"id": {"db.events.id"},
+				"ts": {"db.events.ts"},
+			},
+		},
+		{
+			name: "CTEs and subqueries",
+			sql: "WITH per_user AS (SELECT user_id, count() AS c FROM db.events GROUP BY user_id) " +
+				"SELECT p.user_id, p.c, s.name FROM per_user p JOIN (SELECT id, upper(name) AS name FROM users) s ON p.user_id = s.id",
+			expected: map[string][]string{
+				"user_id": {"db.events.user_id"},
+				"c":       {},
+				"name":    {"users.name"},
+			},
+		},
+		{
+			name: "star expansion",
+			sql:  "SELECT * EXCEPT (country) REPLACE (upper(name) AS name) FROM users",
+			expected: map[string][]string{
+				"id":   {"users.id"},
+				"name": {"users.name"},
+			},
+		},
+		{
+			name: "qualified star",
+			sql:  "SELECT u.* FROM db.events e JOIN users u ON e.user_id = u.id",
+			expected: map[string][]string{
+				"id":      {"users.id"},
+				"name":    {"users.name"},
+				"country": {"users.country"},
+			},
+		},
+		{
+			name: "array join and lambdas",
+			sql:  "SELECT tag, arrayMap(x -> x + id, [1, 2]) AS shifted FROM db.events ARRAY JOIN tags AS tag",
+			expected: map[string][]string{
+				"tag":     {"db.events.tags"},
+				"shifted": {"db.events.id"},
+			},
+		},
+		{
+			name: "arrays and Nested columns joined under their own name or an alias",
+			sql:  "SELECT tags, n.key, m.value FROM db.events ARRAY JOIN tags, n, n AS m",
+			expected: map[string][]string{
+				"tags":    {"db.events.tags"},
+				"n.key":   {"db.events.n.key"},
+				"m.value": {"db.events.n.value"},
+			},
+		},
+		{
+			name: "nested columns",
+			sql:  "SELECT n.key AS k FROM db.events",
+			expected: map[string][]string{
+				"k": {"db.events.n.key"},
+			},
+		},
+		{
+			name: "scalar subqueries and WITH expressions",
+			sql:  "WITH max(ts) AS latest SELECT latest, (SELECT count() FROM users WHERE country = 'NZ') AS nz FROM db.events",
+			expected: map[string][]string{
+				"latest": {"db.events.ts"},
+				"nz":     {},
+			},
+		},
+		{
+			name: "union merges sources by position",
+			sql:  "SELECT id AS key FROM users UNION ALL SELECT user_id FROM db.events",
+			expected: map[string][]string{
+				"key": {"users.id", "db.events.user_id"},
+			},
+		},
+		{
+			name: "insert select maps to target columns",
+			sql:  "INSERT INTO target (a, b) SELECT name, id * 2 FROM users",
+			expected: map[string][]string{
+				"a": {"users.name"},
+				"b": {"users.id"},
+			},
+		},
+		{
+			name: "materialized view",
+			sql:  "CREATE MATERIALIZED VIEW mv TO dest AS SELECT user_id, count() AS hits FROM db.events GROUP BY user_id",
+			expected: map[string][]string{
+				"user_id": {"db.events.user_id"},
+				"hits":    {},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, lineageOf(t, tt.sql, schema))
+		})
+	}
+}
+
+func TestLineageWithoutSchema(t *testing.T) {
+	require.Equal(t, map[string][]string{
+		"*":   {"db.events.*"},
+		"day": {"db.events.ts"},
+	}, lineageOf(t, "SELECT *, toDate(ts) AS day FROM db.events", nil))
+
+	// an unqualified column is ambiguous between two unknown tables
+	require.Equal(t, map[string][]string{
+		"a": {},
+		"b": {"y.b"},
+	}, lineageOf(t, "SELECT a, y.b FROM x JOIN y ON x.id = y.id", nil))
+}
+
+func TestLineageInsertWithSchema(t *testing.T) {
+	schema := MapSchema{"target": {"a", "b"}, "src": {"x", "y"}}
+	require.Equal(t, map[string][]string{
+		"a": {"src.y"},
+		"b": {"src.x"},
+	}, lineageOf(t, "INSERT INTO target SELECT y, x FROM src", schema))
+
+	stmts, err := NewParser("INSERT INTO target SELECT x FROM src").ParseStmts()
+	require.NoError(t, err)
+	_, err = Lineage(stmts[0], schema)
+	require.Error(t, err)
+}
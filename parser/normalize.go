@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"hash/fnv"
+)
+
+// NormalizeQuery returns the normalized form of stmt: literals are replaced by
+// `?` placeholders, the literal values or tuples an IN tests become a single
+// (?), arrays of literals are collapsed to their first element, an INSERT
+// keeps a single VALUES row, and operators and parentheses are
+// canonicalized. The result is rendered through Format, so queries differing
+// only in literal values, keyword case, whitespace or redundant parentheses
+// normalize to the same text, and normalizing it again leaves it unchanged.
+// Literals that are part of the statement's structure rather than its data
+// are kept as written: data types, engines, settings, ON CLUSTER names,
+// dictionary and named collection parameters, and tuple element indexes.
+// stmt is not modified.
+func NormalizeQuery(stmt Expr) string {
+	if stmt == nil {
+		return ""
+	}
+	formatted := Format(stmt)
+	// reparsing the formatted statement yields a copy that can be rewritten
+	stmts, err := NewParser(formatted).ParseStmts()
+	if err != nil || len(stmts) != 1 {
+		return formatted
+	}
+	normalized := ToOperatorForm(ToFunctionForm(stmts[0]))
+
+	collector := &literalCollector{literals: make(map[Expr]bool)}
+	_ = normalized.Accept(collector)
+	normalized = rewriteExpr(normalized, func(expr Expr) Expr {
+		if collector.literals[expr] {
+			return &PlaceHolder{PlaceholderPos: expr.Pos(), PlaceHolderEnd: expr.End(), Type: "?"}
+		}
+		collapseLiteralList(expr)
+		return expr
+	})
+	if insert, ok := normalized.(*InsertStmt); ok && len(insert.Values) > 1 {
+		insert.Values = insert.Values[:1]
+	}
+	return Format(normalized)
+}
+
+// Fingerprint returns a 64-bit hash of the normalized form of stmt, in the
+// spirit of ClickHouse's normalizedQueryHash: statements with the same
+// NormalizeQuery text share a fingerprint. The hash is FNV-1a, so it is
+// stable across processes and platforms.
+func Fingerprint(stmt Expr) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(NormalizeQuery(stmt)))
+	return hash.Sum64()
+}
+
+// literalCollector gathers the literals of a statement that normalization
+// replaces, leaving out those that are part of the statement's structure.
+type literalCollector struct {
+	DefaultASTVisitor
+	literals map[Expr]bool
+	// kept holds the literals that are part of the query structure.
+	kept map[Expr]bool
+	// depth counts the enclosing subtrees that are left out.
+	depth int
+}
+
+func (c *literalCollector) Enter(expr Expr) {
+	if c.depth > 0 {
+		c.depth++
+		return
+	}
+	switch e := expr.(type) {
+	case *ScalarType, *JSONType, *PropertyType, *TypeWithParams, *ComplexType, *NestedType, *EnumType,
+		*EngineExpr, *SettingExpr, *SettingPair, *ClusterClause,
+		*DictionaryAttribute, *DictionarySourceClause, *NamedCollectionParam:
+		c.depth++
+	case *IndexOperation:
+		if e.Operation == TokenKindDot {
+			if c.kept == nil {
+				c.kept = make(map[Expr]bool)
+			}
+			c.kept[e.Index] = true
+		}
+	case *NumberLiteral, *StringLiteral, *BoolLiteral:
+		if !c.kept[e] {
+			c.literals[e] = true
+		}
+	case *TypedLiteral, *IntervalLiteral:
+		c.literals[e] = true
+		c.depth++
+	case *UnaryExpr:
+		if _, ok := e.Expr.(*NumberLiteral); ok && e.Operator() == OperatorNegate {
+			c.literals[e] = true
+			c.depth++
+		}
+	case *NegateExpr:
+		if _, ok := e.Expr.(*NumberLiteral); ok {
+			c.literals[e] = true
+			c.depth++
+		}
+	}
+}
+
+func (c *literalCollector) Leave(expr Expr) {
+	if c.depth > 0 {
+		c.depth--
+	}
+}
+
+// collapseLiteralList replaces the right-hand side of an IN that only holds
+// placeholders, whether a list of values, of tuples or an array, by a
+// single (?), and shortens arrays whose elements are all placeholders (or
+// lists of them) to their first element, so the number of values does not
+// change the normalized query.
+func collapseLiteralList(expr Expr) {
+	switch e := expr.(type) {
+	case *BinaryOperation:
+		switch e.Operator() {
+		case OperatorIn, OperatorNotIn, OperatorGlobalIn, OperatorGlobalNotIn:
+			right := unwrapColumnExpr(e.RightExpr)
+			if !isLiteralList([]Expr{right}) {
+				return
+			}
+			placeholder := &PlaceHolder{PlaceholderPos: right.Pos(), PlaceHolderEnd: right.End(), Type: "?"}
+			e.RightExpr = &ParamExprList{
+				LeftParenPos:  right.Pos(),
+				RightParenPos: right.End(),
+				Items:         &ColumnExprList{ListPos: right.Pos(), ListEnd: right.End(), Items: []Expr{placeholder}},
+			}
+		}
+	case *ArrayParamList:
+		if e.Items != nil && len(e.Items.Items) > 1 && isLiteralList(e.Items.Items) {
+			e.Items.Items = e.Items.Items[:1]
+		}
+	}
+}
+
+// isLiteralList reports whether items are placeholders or non-empty tuples
+// and arrays of them.
+func isLiteralList(items []Expr) bool {
+	for _, item := range items {
+		switch e := unwrapColumnExpr(item).(type) {
+		case *PlaceHolder:
+		case *ParamExprList:
+			if e.ColumnArgList != nil || e.Items == nil || len(e.Items.Items) == 0 || !isLiteralList(e.Items.Items) {
+				return false
+			}
+		case *ArrayParamList:
+			if e.Items == nil || len(e.Items.Items) == 0 || !isLiteralList(e.Items.Items) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
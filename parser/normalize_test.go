@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{
+			sql:      "select a, b+1 from t where x in (1, 2, 3) and y = 'abc' and ((z > -5)) limit 10",
+			expected: "SELECT a, b + ? FROM t WHERE x IN (?) AND y = ? AND z > ? LIMIT ?",
+		},
+		{
+			sql:      "SELECT a FROM t WHERE b NOT IN ('x') AND c GLOBAL IN ((1, 2), (3, 4)) AND d = [1, 2, 3] AND e IN (1, f)",
+			expected: "SELECT a FROM t WHERE b NOT IN (?) AND c GLOBAL IN (?) AND d = [?] AND e IN (?, f)",
+		},
+		{
+			sql:      "SELECT a FROM t WHERE (x, y) IN ((1, 2)) OR (x, y) IN ((1, 2), (3, 4)) OR z IN [1, 2] OR w IN ([1], [2, 3])",
+			expected: "SELECT a FROM t WHERE (x, y) IN (?) OR (x, y) IN (?) OR z IN (?) OR w IN (?)",
+		},
+		{
+			sql:      "INSERT INTO t (a, b) VALUES (1, 'a'), (2, 'b')",
+			expected: "INSERT INTO t (a, b) VALUES (?, ?)",
+		},
+		{
+			sql: "SELECT t.1, arr[2], CAST(x AS Decimal(10, 2)), DATE '2024-01-01', INTERVAL 1 DAY, quantile(0.9)(x), " +
+				"{p: UInt8}, NULL FROM numbers(10) SETTINGS max_threads = 8",
+			expected: "SELECT t.1, arr[?], CAST(x AS Decimal(10, 2)), ?, INTERVAL ? DAY, quantile(?)(x), " +
+				"{p: UInt8}, NULL FROM numbers(?) SETTINGS max_threads=8",
+		},
+		{
+			sql:      "SELECT plus(a, 1), (a + b) + c, a + (b + c) FROM t",
+			expected: "SELECT a + ?, a + b + c, a + (b + c) FROM t",
+		},
+		{
+			sql:      "CREATE TABLE t ON CLUSTER 'c' (a FixedString(16) DEFAULT 'x') ENGINE = ReplicatedMergeTree('/p', 'r') ORDER BY a",
+			expected: "CREATE TABLE t ON CLUSTER 'c' (a FixedString(16) DEFAULT ?) ENGINE = ReplicatedMergeTree('/p', 'r') ORDER BY a",
+		},
+		{
+			sql:      "CREATE USER u NOT IDENTIFIED",
+			expected: "CREATE USER u NOT IDENTIFIED",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt := parseOneStmt(t, tt.sql)
+			formatted := Format(stmt)
+			normalized := NormalizeQuery(stmt)
+			require.Equal(t, tt.expected, normalized)
+			require.Equal(t, formatted, Format(stmt), "the statement must not be modified")
+			require.Equal(t, normalized, NormalizeQuery(parseOneStmt(t, normalized)), "normalizing must be idempotent")
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := func(sql string) uint64 {
+		return Fingerprint(parseOneStmt(t, sql))
+	}
+	same := fingerprint("SELECT a FROM t WHERE id IN (1, 2, 3) AND name = 'x' LIMIT 10")
+	require.Equal(t, same, fingerprint("select  a\nfrom t where (id in (4)) and name='y' limit 5"))
+	require.NotEqual(t, same, fingerprint("SELECT b FROM t WHERE id IN (1, 2, 3) AND name = 'x' LIMIT 10"))
+	require.Equal(t, fingerprint("INSERT INTO t VALUES (1)"), fingerprint("INSERT INTO t VALUES (2), (3)"))
+	require.Equal(t, fingerprint("SELECT a FROM t WHERE (x, y) IN ((1, 2))"), fingerprint("SELECT a FROM t WHERE (x, y) IN ((1, 2), (3, 4))"))
+	require.Equal(t, fingerprint("SELECT a FROM t WHERE x IN (1)"), fingerprint("SELECT a FROM t WHERE x IN (1, 2, 3)"))
+	require.Equal(t, uint64(0x199e7dca63ea8858), fingerprint("SELECT 42"), "the FNV-1a hash of SELECT ?")
+}
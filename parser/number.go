@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// isInfOrNaN reports whether name spells one of the special float literals.
+func isInfOrNaN(name string) bool {
+	switch strings.ToLower(name) {
+	case "inf", "infinity", "nan":
+		return true
+	}
+	return false
+}
+
+// parseNumberValue converts the text of a number literal into its value; see
+// NumberLiteral.Value for the types it returns.
+func parseNumberValue(literal string) any {
+	text := strings.ReplaceAll(literal, "_", "")
+	negative := false
+	unsigned := text
+	if unsigned != "" && (unsigned[0] == '+' || unsigned[0] == '-') {
+		negative = unsigned[0] == '-'
+		unsigned = unsigned[1:]
+	}
+
+	if isInfOrNaN(unsigned) {
+		if strings.EqualFold(unsigned, "nan") {
+			return math.NaN()
+		}
+		if negative {
+			return math.Inf(-1)
+		}
+		return math.Inf(1)
+	}
+
+	base := 10
+	digits := unsigned
+	if len(digits) > 1 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			base, digits = 16, digits[2:]
+		case 'b', 'B':
+			base, digits = 2, digits[2:]
+		}
+	}
+
+	isFloat := strings.ContainsAny(digits, ".")
+	switch base {
+	case 10:
+		isFloat = isFloat || strings.ContainsAny(digits, "eE")
+	case 16:
+		isFloat = isFloat || strings.ContainsAny(digits, "pP")
+	}
+	if isFloat {
+		if base == 16 {
+			// Go only accepts hex floats with an explicit binary exponent.
+			if !strings.ContainsAny(digits, "pP") {
+				text += "p0"
+			}
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		// out-of-range floats still have a value: ±Inf or zero
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return nil
+		}
+		return value
+	}
+
+	value, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil
+	}
+	if negative {
+		value.Neg(value)
+	}
+	switch {
+	case value.IsInt64():
+		return value.Int64()
+	case value.IsUint64():
+		return value.Uint64()
+	default:
+		return value
+	}
+}
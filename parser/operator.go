@@ -0,0 +1,432 @@
+package parser
+
+import "strings"
+
+// Operator identifies an operator independently of how it was spelled: `!=`
+// and `<>` are both OperatorNotEquals, and NOT IN is OperatorNotIn whether the
+// parser recorded the negation in BinaryOperation.Operation or in HasNot.
+type Operator int
+
+const (
+	OperatorUnknown Operator = iota
+	OperatorPlus
+	OperatorMinus
+	OperatorMultiply
+	OperatorDivide
+	OperatorModulo
+	OperatorConcat
+	OperatorEquals
+	OperatorNotEquals
+	OperatorLess
+	OperatorLessOrEquals
+	OperatorGreater
+	OperatorGreaterOrEquals
+	OperatorAnd
+	OperatorOr
+	OperatorIn
+	OperatorNotIn
+	OperatorGlobalIn
+	OperatorGlobalNotIn
+	OperatorLike
+	OperatorNotLike
+	OperatorILike
+	OperatorNotILike
+	OperatorRegexp
+	OperatorIsDistinctFrom
+	OperatorIsNotDistinctFrom
+	OperatorNegate       // prefix -
+	OperatorNot          // prefix NOT
+	OperatorArrayElement // x[i]
+)
+
+type operatorInfo struct {
+	sql        string // canonical SQL spelling
+	function   string // ClickHouse function the operator is sugar for
+	precedence int
+}
+
+var operatorInfos = map[Operator]operatorInfo{
+	OperatorPlus:              {"+", "plus", PrecedenceAddSub},
+	OperatorMinus:             {"-", "minus", PrecedenceAddSub},
+	OperatorMultiply:          {"*", "multiply", PrecedenceMulDivMod},
+	OperatorDivide:            {"/", "divide", PrecedenceMulDivMod},
+	OperatorModulo:            {"%", "modulo", PrecedenceMulDivMod},
+	OperatorConcat:            {"||", "concat", PrecedenceConcat},
+	OperatorEquals:            {"=", "equals", PrecedenceCompare},
+	OperatorNotEquals:         {"!=", "notEquals", PrecedenceCompare},
+	OperatorLess:              {"<", "less", PrecedenceCompare},
+	OperatorLessOrEquals:      {"<=", "lessOrEquals", PrecedenceCompare},
+	OperatorGreater:           {">", "greater", PrecedenceCompare},
+	OperatorGreaterOrEquals:   {">=", "greaterOrEquals", PrecedenceCompare},
+	OperatorAnd:               {"AND", "and", PrecedenceAnd},
+	OperatorOr:                {"OR", "or", PrecedenceOr},
+	OperatorIn:                {"IN", "in", precedenceIn},
+	OperatorNotIn:             {"NOT IN", "notIn", precedenceIn},
+	OperatorGlobalIn:          {"GLOBAL IN", "globalIn", precedenceIn},
+	OperatorGlobalNotIn:       {"GLOBAL NOT IN", "globalNotIn", precedenceIn},
+	OperatorLike:              {"LIKE", "like", PrecedenceBetweenLike},
+	OperatorNotLike:           {"NOT LIKE", "notLike", PrecedenceBetweenLike},
+	OperatorILike:             {"ILIKE", "ilike", PrecedenceBetweenLike},
+	OperatorNotILike:          {"NOT ILIKE", "notILike", PrecedenceBetweenLike},
+	OperatorRegexp:            {"REGEXP", "match", PrecedenceBetweenLike},
+	OperatorIsDistinctFrom:    {"IS DISTINCT FROM", "isDistinctFrom", PrecedenceIs},
+	OperatorIsNotDistinctFrom: {"IS NOT DISTINCT FROM", "isNotDistinctFrom", PrecedenceIs},
+	OperatorNegate:            {"-", "negate", PrecedenceBracket},
+	OperatorNot:               {"NOT", "not", PrecedenceNot},
+	OperatorArrayElement:      {"[]", "arrayElement", PrecedenceBracket},
+}
+
+// operatorsByFunction is the reverse of operatorInfos' function column.
+var operatorsByFunction = func() map[string]Operator {
+	m := make(map[string]Operator, len(operatorInfos))
+	for op, info := range operatorInfos {
+		m[info.function] = op
+	}
+	return m
+}()
+
+// String returns the canonical SQL spelling of the operator.
+func (o Operator) String() string {
+	return operatorInfos[o].sql
+}
+
+// FunctionName returns the ClickHouse function the operator stands for, such
+// as "plus" for `+`, or "" for OperatorUnknown.
+func (o Operator) FunctionName() string {
+	return operatorInfos[o].function
+}
+
+// OperatorForFunction returns the operator whose function form is name.
+func OperatorForFunction(name string) (Operator, bool) {
+	op, ok := operatorsByFunction[name]
+	return op, ok
+}
+
+// Operator classifies the operation. It returns OperatorUnknown for operators
+// without a function form, such as `::`.
+func (p *BinaryOperation) Operator() Operator {
+	switch strings.ToUpper(string(p.Operation)) {
+	case "+":
+		return OperatorPlus
+	case "-":
+		return OperatorMinus
+	case "*":
+		return OperatorMultiply
+	case "/":
+		return OperatorDivide
+	case "%":
+		return OperatorModulo
+	case "||":
+		return OperatorConcat
+	case "=", "==":
+		return OperatorEquals
+	case "!=", "<>":
+		return OperatorNotEquals
+	case "<":
+		return OperatorLess
+	case "<=":
+		return OperatorLessOrEquals
+	case ">":
+		return OperatorGreater
+	case ">=":
+		return OperatorGreaterOrEquals
+	case KeywordAnd:
+		return OperatorAnd
+	case KeywordOr:
+		return OperatorOr
+	case KeywordIn:
+		switch {
+		case p.HasGlobal && p.HasNot:
+			return OperatorGlobalNotIn
+		case p.HasGlobal:
+			return OperatorGlobalIn
+		case p.HasNot:
+			return OperatorNotIn
+		}
+		return OperatorIn
+	case "NOT IN":
+		if p.HasGlobal {
+			return OperatorGlobalNotIn
+		}
+		return OperatorNotIn
+	case KeywordLike:
+		return OperatorLike
+	case "NOT LIKE":
+		return OperatorNotLike
+	case KeywordIlike:
+		return OperatorILike
+	case "NOT ILIKE":
+		return OperatorNotILike
+	case KeywordRegexp:
+		return OperatorRegexp
+	}
+	return OperatorUnknown
+}
+
+// Operator returns OperatorIsNotDistinctFrom for IS NOT DISTINCT FROM and
+// <=>, and OperatorIsDistinctFrom otherwise.
+func (d *DistinctFromExpr) Operator() Operator {
+	if d.HasNot {
+		return OperatorIsNotDistinctFrom
+	}
+	return OperatorIsDistinctFrom
+}
+
+// Operator classifies the prefix operator. Unary plus has no function form
+// and is OperatorUnknown.
+func (n *UnaryExpr) Operator() Operator {
+	switch strings.ToUpper(string(n.Kind)) {
+	case "-":
+		return OperatorNegate
+	case KeywordNot:
+		return OperatorNot
+	}
+	return OperatorUnknown
+}
+
+// ToFunctionForm rewrites every operator in expr into the function call it
+// stands for, the way ClickHouse does before analysis: `a + b` becomes
+// `plus(a, b)`, `a NOT IN s` becomes `notIn(a, s)` and `x[1]` becomes
+// `arrayElement(x, 1)`. Redundant parentheses are dropped and AND/OR chains
+// are flattened, so equivalent expressions get identical trees. The tree is
+// rewritten in place; the returned expression replaces expr.
+func ToFunctionForm(expr Expr) Expr {
+	return rewriteExpr(expr, toFunctionForm)
+}
+
+// ToOperatorForm is the inverse of ToFunctionForm: calls of operator functions
+// become operators again, with parentheses added where precedence needs them.
+func ToOperatorForm(expr Expr) Expr {
+	return rewriteExpr(expr, toOperatorForm)
+}
+
+func toFunctionForm(expr Expr) Expr {
+	switch e := expr.(type) {
+	case *BinaryOperation:
+		op := e.Operator()
+		if op == OperatorUnknown {
+			return e
+		}
+		args := []Expr{e.LeftExpr, e.RightExpr}
+		if op == OperatorAnd || op == OperatorOr {
+			args = append(flattenCall(e.LeftExpr, op), flattenCall(e.RightExpr, op)...)
+		}
+		return newOperatorCall(op, e.Pos(), e.End(), args...)
+	case *UnaryExpr:
+		op := e.Operator()
+		if op == OperatorUnknown {
+			return e
+		}
+		return newOperatorCall(op, e.Pos(), e.End(), e.Expr)
+	case *DistinctFromExpr:
+		return newOperatorCall(e.Operator(), e.Pos(), e.End(), e.LeftExpr, e.RightExpr)
+	case *ObjectParams:
+		if e.Params == nil || e.Params.Items == nil || len(e.Params.Items.Items) != 1 {
+			return e
+		}
+		return newOperatorCall(OperatorArrayElement, e.Pos(), e.End(), e.Object, unwrapColumnExpr(e.Params.Items.Items[0]))
+	case *ParamExprList:
+		// `(x)` is only grouping; a tuple needs two items or a trailing comma
+		if e.ColumnArgList == nil && e.Items != nil && len(e.Items.Items) == 1 {
+			if item := unwrapColumnExpr(e.Items.Items[0]); item != e.Items.Items[0] || !isColumnExpr(item) {
+				return item
+			}
+		}
+	}
+	return expr
+}
+
+// isNegation reports whether expr is a unary minus.
+func isNegation(expr Expr) bool {
+	switch e := expr.(type) {
+	case *UnaryExpr:
+		return e.Operator() == OperatorNegate
+	case *NegateExpr:
+		return true
+	}
+	return false
+}
+
+func toOperatorForm(expr Expr) Expr {
+	fn, ok := expr.(*FunctionExpr)
+	if !ok || fn.Params == nil || fn.Params.ColumnArgList != nil || fn.Params.Items == nil || fn.Params.Items.HasDistinct {
+		return expr
+	}
+	op, ok := OperatorForFunction(fn.Name.Name)
+	if !ok {
+		return expr
+	}
+	args := make([]Expr, 0, len(fn.Params.Items.Items))
+	for _, item := range fn.Params.Items.Items {
+		arg := unwrapColumnExpr(item)
+		if isColumnExpr(arg) {
+			return expr // an aliased argument has no operator spelling
+		}
+		args = append(args, arg)
+	}
+
+	switch op {
+	case OperatorNegate, OperatorNot:
+		if len(args) != 1 {
+			return expr
+		}
+		operand := args[0]
+		// NOT's operand is parsed at NOT's own precedence, the negated operand
+		// of a unary minus is a primary expression, and `- -x` does not parse
+		if (op == OperatorNot && exprPrecedence(operand) <= PrecedenceNot) ||
+			(op == OperatorNegate && (exprPrecedence(operand) < PrecedenceBracket || isNegation(operand))) {
+			operand = parenthesize(operand)
+		}
+		return &UnaryExpr{UnaryPos: fn.Pos(), Kind: TokenKind(op.String()), Expr: operand}
+	case OperatorArrayElement:
+		if len(args) != 2 {
+			return expr
+		}
+		object := args[0]
+		if exprPrecedence(object) < PrecedenceBracket {
+			object = parenthesize(object)
+		}
+		return &ObjectParams{
+			Object: object,
+			Params: &ArrayParamList{
+				LeftBracketPos:  args[1].Pos(),
+				RightBracketPos: fn.End(),
+				Items:           &ColumnExprList{ListPos: args[1].Pos(), ListEnd: args[1].End(), Items: []Expr{&ColumnExpr{Expr: args[1]}}},
+			},
+		}
+	case OperatorAnd, OperatorOr:
+		if len(args) < 2 {
+			return expr
+		}
+	case OperatorIsDistinctFrom, OperatorIsNotDistinctFrom:
+		if len(args) != 2 {
+			return expr
+		}
+		left, right := args[0], args[1]
+		if exprPrecedence(left) < PrecedenceIs {
+			left = parenthesize(left)
+		}
+		if exprPrecedence(right) <= PrecedenceIs {
+			right = parenthesize(right)
+		}
+		return &DistinctFromExpr{LeftExpr: left, RightExpr: right, HasNot: op == OperatorIsNotDistinctFrom}
+	default:
+		if len(args) != 2 {
+			return expr
+		}
+	}
+
+	// fold left, which is how the parser associates a chain of operators
+	result := args[0]
+	for _, arg := range args[1:] {
+		result = newBinaryOperation(op, result, arg)
+	}
+	return result
+}
+
+func newBinaryOperation(op Operator, left, right Expr) *BinaryOperation {
+	precedence := operatorInfos[op].precedence
+	if exprPrecedence(left) < precedence {
+		left = parenthesize(left)
+	}
+	if exprPrecedence(right) <= precedence {
+		right = parenthesize(right)
+	}
+	binary := &BinaryOperation{LeftExpr: left, RightExpr: right}
+	switch op {
+	case OperatorGlobalIn:
+		binary.Operation = TokenKind(KeywordIn)
+		binary.HasGlobal = true
+	case OperatorGlobalNotIn:
+		binary.Operation = TokenKind(KeywordIn)
+		binary.HasGlobal = true
+		binary.HasNot = true
+	default:
+		binary.Operation = TokenKind(op.String())
+	}
+	return binary
+}
+
+// newOperatorCall builds the function call form of op spanning pos to end.
+func newOperatorCall(op Operator, pos, end Pos, args ...Expr) *FunctionExpr {
+	items := make([]Expr, len(args))
+	for i, arg := range args {
+		items[i] = &ColumnExpr{Expr: arg}
+	}
+	return &FunctionExpr{
+		Name: &Ident{Name: op.FunctionName(), NamePos: pos, NameEnd: pos},
+		Params: &ParamExprList{
+			LeftParenPos:  pos,
+			RightParenPos: end,
+			Items:         &ColumnExprList{ListPos: pos, ListEnd: end, Items: items},
+		},
+	}
+}
+
+// flattenCall returns the arguments of expr if it is already a call of op's
+// function, so that `a AND b AND c` becomes and(a, b, c).
+func flattenCall(expr Expr, op Operator) []Expr {
+	fn, ok := expr.(*FunctionExpr)
+	if !ok || fn.Name.Name != op.FunctionName() || fn.Params == nil || fn.Params.Items == nil {
+		return []Expr{expr}
+	}
+	args := make([]Expr, len(fn.Params.Items.Items))
+	for i, item := range fn.Params.Items.Items {
+		args[i] = unwrapColumnExpr(item)
+	}
+	return args
+}
+
+// parenthesize wraps expr in grouping parentheses.
+func parenthesize(expr Expr) Expr {
+	return &ParamExprList{
+		LeftParenPos:  expr.Pos(),
+		RightParenPos: expr.End(),
+		Items:         &ColumnExprList{ListPos: expr.Pos(), ListEnd: expr.End(), Items: []Expr{&ColumnExpr{Expr: expr}}},
+	}
+}
+
+// exprPrecedence returns how tightly expr binds when printed without
+// parentheses; operands that bind looser than their operator need them.
+func exprPrecedence(expr Expr) int {
+	switch e := expr.(type) {
+	case *BinaryOperation:
+		if op := e.Operator(); op != OperatorUnknown {
+			return operatorInfos[op].precedence
+		}
+		return PrecedenceDoubleColon
+	case *UnaryExpr:
+		if e.Operator() == OperatorNot {
+			return PrecedenceNot
+		}
+		return PrecedenceBracket
+	case *DistinctFromExpr:
+		if e.Shorthand {
+			return PrecedenceCompare
+		}
+		return PrecedenceIs
+	case *QuantifiedComparisonExpr:
+		return PrecedenceCompare
+	case *BetweenClause:
+		return PrecedenceBetweenLike
+	case *IsNullExpr, *IsNotNullExpr:
+		return PrecedenceIs
+	case *TernaryOperation:
+		return PrecedenceQuery
+	case *LambdaExpr:
+		return PrecedenceArrow
+	}
+	return PrecedenceDoubleColon + 1
+}
+
+func unwrapColumnExpr(expr Expr) Expr {
+	if column, ok := expr.(*ColumnExpr); ok && column.Alias == nil {
+		return column.Expr
+	}
+	return expr
+}
+
+func isColumnExpr(expr Expr) bool {
+	_, ok := expr.(*ColumnExpr)
+	return ok
+}
@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryOperationOperator(t *testing.T) {
+	tests := []struct {
+		sql      string
+		operator Operator
+	}{
+		{"SELECT a + b", OperatorPlus},
+		{"SELECT a <> b", OperatorNotEquals},
+		{"SELECT a != b", OperatorNotEquals},
+		{"SELECT a == b", OperatorEquals},
+		{"SELECT a NOT IN (1, 2)", OperatorNotIn},
+		{"SELECT a GLOBAL IN (1, 2)", OperatorGlobalIn},
+		{"SELECT a GLOBAL NOT IN (1, 2)", OperatorGlobalNotIn},
+		{"SELECT a NOT ILIKE 'x%'", OperatorNotILike},
+		{"SELECT a REGEXP 'x'", OperatorRegexp},
+		{"SELECT a AND b", OperatorAnd},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			expr := parseSelectItemExpr(t, tt.sql)
+			binary, ok := expr.(*BinaryOperation)
+			require.True(t, ok, "expected *BinaryOperation, got %T", expr)
+			require.Equal(t, tt.operator, binary.Operator())
+		})
+	}
+}
+
+func TestOperatorForFunction(t *testing.T) {
+	op, ok := OperatorForFunction("lessOrEquals")
+	require.True(t, ok)
+	require.Equal(t, OperatorLessOrEquals, op)
+	require.Equal(t, "<=", op.String())
+
+	_, ok = OperatorForFunction("toString")
+	require.False(t, ok)
+}
+
+func TestToFunctionForm(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT a + b * c", "plus(a, multiply(b, c))"},
+		{"SELECT (a + b) * c", "multiply(plus(a, b), c)"},
+		{"SELECT a IN (1, 2)", "in(a, (1, 2))"},
+		{"SELECT a GLOBAL NOT IN t", "globalNotIn(a, t)"},
+		{"SELECT x[1]", "arrayElement(x, 1)"},
+		{"SELECT -x", "negate(x)"},
+		{"SELECT NOT a = b", "not(equals(a, b))"},
+		{"SELECT a AND b AND c OR d", "or(and(a, b, c), d)"},
+		{"SELECT a || b REGEXP 'x'", "match(concat(a, b), 'x')"},
+		{"SELECT f(a - 1, x -> x % 2)", "f(minus(a, 1), x -> modulo(x, 2))"},
+		{"SELECT a::String", "a::String"},
+		{"SELECT a <=> b", "isNotDistinctFrom(a, b)"},
+		{"SELECT a IS DISTINCT FROM b", "isDistinctFrom(a, b)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			expr := ToFunctionForm(parseSelectItemExpr(t, tt.sql))
+			require.Equal(t, tt.want, Format(expr))
+		})
+	}
+}
+
+func TestToOperatorForm(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT plus(a, multiply(b, c))", "a + b * c"},
+		{"SELECT multiply(plus(a, b), c)", "(a + b) * c"},
+		{"SELECT minus(a, minus(b, c))", "a - (b - c)"},
+		{"SELECT minus(minus(a, b), c)", "a - b - c"},
+		{"SELECT and(a, b, or(c, d))", "a AND b AND (c OR d)"},
+		{"SELECT not(equals(a, b))", "NOT (a = b)"}, // NOT( parses as the NOT operator
+		{"SELECT negate(plus(a, b))", "- (a + b)"},
+		{"SELECT negate(negate(x))", "- (- x)"},
+		{"SELECT arrayElement(arrayElement(x, 1), 2)", "x[1][2]"},
+		{"SELECT notIn(a, (1, 2))", "a NOT IN (1, 2)"},
+		{"SELECT globalIn(a, t)", "a GLOBAL IN t"},
+		{"SELECT isDistinctFrom(a, or(b, c))", "a IS DISTINCT FROM (b OR c)"},
+		{"SELECT plus(a AS x, b)", "plus(a AS x, b)"},
+		{"SELECT plus(a, b, c)", "plus(a, b, c)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			expr := ToOperatorForm(parseSelectItemExpr(t, tt.sql))
+			require.Equal(t, tt.want, Format(expr))
+		})
+	}
+}
+
+func TestFunctionFormMakesSpellingsEquivalent(t *testing.T) {
+	pairs := [][2]string{
+		{"SELECT a + b", "SELECT plus(a, b)"},
+		{"SELECT ((a <> b))", "SELECT notEquals(a, b)"},
+		{"SELECT x[i + 1]", "SELECT arrayElement(x, plus(i, 1))"},
+	}
+	for _, pair := range pairs {
+		left := Format(ToFunctionForm(parseSelectItemExpr(t, pair[0])))
+		right := Format(ToFunctionForm(parseSelectItemExpr(t, pair[1])))
+		require.Equal(t, left, right, "%s vs %s", pair[0], pair[1])
+	}
+}
+
+func TestOperatorFormRoundTrip(t *testing.T) {
+	for _, sql := range []string{
+		"SELECT a + b * c - d / e",
+		"SELECT (a OR b) AND NOT c",
+		"SELECT NOT a = b",
+		"SELECT x[1] || 'a' LIKE 'b%'",
+		"SELECT a NOT IN (1, 2) OR -(b - c) > 0",
+		"SELECT -(-x)",
+		"SELECT -(-(-x)) + 1",
+	} {
+		want := Format(parseSelectItemExpr(t, sql))
+		expr := ToOperatorForm(ToFunctionForm(parseSelectItemExpr(t, sql)))
+		require.Equal(t, want, Format(expr), sql)
+	}
+}
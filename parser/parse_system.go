@@ -566,18 +566,19 @@ func (p *Parser) parseAuthenticationClause(pos Pos) (*AuthenticationClause, erro
 	auth := &AuthenticationClause{AuthPos: pos}
 
 	if p.tryConsumeKeywords(KeywordNot) {
+		// the clause may end the input, so ends are taken before consuming
+		auth.AuthEnd = p.End()
 		if err := p.expectKeyword(KeywordIdentified); err != nil {
 			return nil, err
 		}
 		auth.NotIdentified = true
-		auth.AuthEnd = p.current().End
 		return auth, nil
 	}
 
+	auth.AuthEnd = p.End()
 	if err := p.expectKeyword(KeywordIdentified); err != nil {
 		return nil, err
 	}
-	auth.AuthEnd = p.current().End
 
 	if p.tryConsumeKeywords(KeywordWith) {
 		if p.matchKeyword(KeywordLdap) {
@@ -592,9 +593,9 @@ func (p *Parser) parseAuthenticationClause(pos Pos) (*AuthenticationClause, erro
 			auth.LdapServer = server
 			auth.AuthEnd = server.End()
 		} else if p.matchKeyword(KeywordKerberos) {
+			auth.AuthEnd = p.End()
 			_ = p.lexer.consumeToken()
 			auth.IsKerberos = true
-			auth.AuthEnd = p.current().End
 			if p.tryConsumeKeywords(KeywordRealm) {
 				realm, err := p.parseString(p.Pos())
 				if err != nil {
@@ -606,9 +607,9 @@ func (p *Parser) parseAuthenticationClause(pos Pos) (*AuthenticationClause, erro
 		} else if p.matchTokenKind(TokenKindIdent) {
 			// Auth types like no_password, plaintext_password, etc.
 			authType := p.current().String
+			auth.AuthEnd = p.End()
 			_ = p.lexer.consumeToken()
 			auth.AuthType = authType
-			auth.AuthEnd = p.current().End
 
 			if p.tryConsumeKeywords(KeywordBy) {
 				value, err := p.parseString(p.Pos())
@@ -634,9 +635,9 @@ func (p *Parser) parseHostClause(pos Pos) (*HostClause, error) {
 	switch {
 	case p.matchOneOfKeywords(KeywordLocal, KeywordAny, KeywordNone):
 		hostType := p.current().String
+		host.HostEnd = p.End()
 		_ = p.lexer.consumeToken()
 		host.HostType = hostType
-		host.HostEnd = p.current().End
 	case p.matchOneOfKeywords(KeywordName, KeywordRegexp, KeywordIp, KeywordLike):
 		hostType := p.current().String
 		_ = p.lexer.consumeToken()
@@ -664,9 +665,10 @@ func (p *Parser) parseDefaultRoleClause(pos Pos) (*DefaultRoleClause, error) {
 
 	defaultRole := &DefaultRoleClause{DefaultPos: pos}
 
-	if p.tryConsumeKeywords(KeywordNone) {
+	if p.matchKeyword(KeywordNone) {
 		defaultRole.None = true
-		defaultRole.DefaultEnd = p.current().End
+		defaultRole.DefaultEnd = p.End()
+		_ = p.lexer.consumeToken()
 		return defaultRole, nil
 	}
 
@@ -697,12 +699,11 @@ func (p *Parser) parseGranteesClause(pos Pos) (*GranteesClause, error) {
 
 	grantees := &GranteesClause{GranteesPos: pos}
 
-	if p.tryConsumeKeywords(KeywordAny) {
-		grantees.Any = true
-		grantees.GranteesEnd = p.current().End
-	} else if p.tryConsumeKeywords(KeywordNone) {
-		grantees.None = true
-		grantees.GranteesEnd = p.current().End
+	if p.matchOneOfKeywords(KeywordAny, KeywordNone) {
+		grantees.Any = p.matchKeyword(KeywordAny)
+		grantees.None = !grantees.Any
+		grantees.GranteesEnd = p.End()
+		_ = p.lexer.consumeToken()
 	} else {
 		// Parse list of grantees
 		granteeList := make([]*RoleName, 0)
@@ -822,9 +823,10 @@ func (p *Parser) parseDefaultClause(createUser *CreateUser) (bool, error) {
 	} else if nextToken.String == KeywordDatabase {
 		_ = p.lexer.consumeToken() // consume DEFAULT
 		_ = p.lexer.consumeToken() // consume DATABASE
-		if p.tryConsumeKeywords(KeywordNone) {
+		if p.matchKeyword(KeywordNone) {
 			createUser.DefaultDbNone = true
-			createUser.StatementEnd = p.current().End
+			createUser.StatementEnd = p.End()
+			_ = p.lexer.consumeToken()
 		} else {
 			db, err := p.parseIdent()
 			if err != nil {
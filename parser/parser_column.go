@@ -36,6 +36,16 @@ func (p *Parser) tryParseColumnComment(pos Pos) (*StringLiteral, error) {
 	return p.parseString(pos)
 }
 
+// isColumnDefOptionEnd reports whether the current token ends the optional
+// value of a column definition: the next column option, the end of the
+// column, or the end of input.
+func (p *Parser) isColumnDefOptionEnd() bool {
+	return p.lexer.isEOF() ||
+		p.matchTokenKind(TokenKindComma) || p.matchTokenKind(TokenKindRParen) ||
+		p.matchOneOfKeywords(KeywordComment, KeywordCodec, KeywordStatistics,
+			KeywordTtl, KeywordPrimary, KeywordSettings)
+}
+
 func (p *Parser) getNextPrecedence() int {
 	switch {
 	case p.matchKeyword(KeywordOr):
@@ -62,7 +72,7 @@ func (p *Parser) getNextPrecedence() int {
 		return PrecedenceDoubleColon
 	case p.matchTokenKind(TokenKindSingleEQ), p.matchTokenKind(TokenKindLT), p.matchTokenKind(TokenKindLE),
 		p.matchTokenKind(TokenKindGE), p.matchTokenKind(TokenKindGT), p.matchTokenKind(TokenKindDoubleEQ),
-		p.matchTokenKind(TokenKindNE), p.matchTokenKind("<>"):
+		p.matchTokenKind(TokenKindNE), p.matchTokenKind("<>"), p.matchTokenKind(TokenKindNullSafeEQ):
 		return PrecedenceCompare
 	case p.matchTokenKind(TokenKindConcat):
 		return PrecedenceConcat
@@ -106,6 +116,12 @@ func (p *Parser) parseInfix(expr Expr, precedence int) (Expr, error) {
 		p.matchTokenKind(TokenKindDoubleEQ):
 		op := p.current().ToString()
 		_ = p.lexer.consumeToken()
+		if comparisonOperators.Contains(op) {
+			quantified, err := p.tryParseQuantifiedComparison(expr, TokenKind(op))
+			if err != nil || quantified != nil {
+				return quantified, err
+			}
+		}
 		rightExpr, err := p.parseSubExpr(p.Pos(), precedence)
 		if err != nil {
 			return nil, err
@@ -115,18 +131,34 @@ func (p *Parser) parseInfix(expr Expr, precedence int) (Expr, error) {
 			Operation: TokenKind(op),
 			RightExpr: rightExpr,
 		}, nil
+	case p.matchTokenKind(TokenKindNullSafeEQ):
+		_ = p.lexer.consumeToken()
+		rightExpr, err := p.parseSubExpr(p.Pos(), precedence)
+		if err != nil {
+			return nil, err
+		}
+		return &DistinctFromExpr{
+			LeftExpr:  expr,
+			RightExpr: rightExpr,
+			HasNot:    true,
+			Shorthand: true,
+		}, nil
 	case p.matchTokenKind(TokenKindArrow):
 		_ = p.lexer.consumeToken()
 		// Lambdas are right-associative: `x -> y -> body` is `x -> (y -> body)`,
 		// so the body is parsed one level below the arrow's own precedence.
-		rightExpr, err := p.parseSubExpr(p.Pos(), precedence-1)
+		params, err := lambdaParams(expr)
 		if err != nil {
 			return nil, err
 		}
-		return &BinaryOperation{
-			LeftExpr:  expr,
-			Operation: TokenKindArrow,
-			RightExpr: rightExpr,
+		body, err := p.parseSubExpr(p.Pos(), precedence-1)
+		if err != nil {
+			return nil, err
+		}
+		return &LambdaExpr{
+			ParamsPos: expr.Pos(),
+			Params:    params,
+			Body:      body,
 		}, nil
 	case p.matchTokenKind(TokenKindDash):
 		_ = p.lexer.consumeToken()
@@ -250,6 +282,20 @@ func (p *Parser) parseInfix(expr Expr, precedence int) (Expr, error) {
 		isPos := p.Pos()
 		_ = p.lexer.consumeToken()
 		isNotNull := p.tryConsumeKeywords(KeywordNot)
+		if p.tryConsumeKeywords(KeywordDistinct) {
+			if err := p.expectKeyword(KeywordFrom); err != nil {
+				return nil, err
+			}
+			rightExpr, err := p.parseSubExpr(p.Pos(), precedence)
+			if err != nil {
+				return nil, err
+			}
+			return &DistinctFromExpr{
+				LeftExpr:  expr,
+				RightExpr: rightExpr,
+				HasNot:    isNotNull,
+			}, nil
+		}
 		// the expression ends at the NULL keyword; capture its end before
 		// expectKeyword consumes it
 		nullEnd := p.End()
@@ -273,6 +319,82 @@ func (p *Parser) parseInfix(expr Expr, precedence int) (Expr, error) {
 	}
 }
 
+// comparisonOperators are the operators that may be quantified with ANY or
+// ALL over a subquery.
+var comparisonOperators = NewSet("=", "==", "!=", "<>", "<", "<=", ">", ">=")
+
+// tryParseQuantifiedComparison parses the `ANY (SELECT ...)` or
+// `ALL (SELECT ...)` right side of a comparison whose operator has just been
+// consumed. It returns nil without consuming anything when the right side is
+// something else, such as a call of the any() aggregate function.
+func (p *Parser) tryParseQuantifiedComparison(expr Expr, op TokenKind) (*QuantifiedComparisonExpr, error) {
+	var quantifier SubqueryQuantifier
+	switch {
+	case p.matchKeyword(KeywordAny):
+		quantifier = SubqueryQuantifierAny
+	case p.matchKeyword(KeywordAll):
+		quantifier = SubqueryQuantifierAll
+	case p.matchTokenKind(TokenKindIdent) && p.current().QuoteType == Unquoted && strings.EqualFold(p.current().String, "SOME"):
+		quantifier = SubqueryQuantifierAny
+	default:
+		return nil, nil
+	}
+
+	savedState := p.lexer.saveState()
+	quantifierPos := p.Pos()
+	_ = p.lexer.consumeToken()
+	if !p.matchTokenKind(TokenKindLParen) || (!p.peekKeyword(KeywordSelect) && !p.peekKeyword(KeywordWith)) {
+		p.lexer.restoreState(savedState)
+		return nil, nil
+	}
+	_ = p.lexer.consumeToken()
+
+	subQuery, err := p.parseSubQuery(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	rightParenPos := p.Pos()
+	if err := p.expectTokenKind(TokenKindRParen); err != nil {
+		return nil, err
+	}
+	subQuery.HasParen = true
+	return &QuantifiedComparisonExpr{
+		LeftExpr:      expr,
+		Operation:     op,
+		Quantifier:    quantifier,
+		QuantifierPos: quantifierPos,
+		RightParenPos: rightParenPos,
+		SubQuery:      subQuery,
+	}, nil
+}
+
+// lambdaParams extracts the parameter names from the expression before `->`:
+// a single identifier `x`, or a parenthesized list `(k, v)`.
+func lambdaParams(expr Expr) ([]*Ident, error) {
+	switch e := expr.(type) {
+	case *Ident:
+		return []*Ident{e}, nil
+	case *ParamExprList:
+		if e.Items == nil || len(e.Items.Items) == 0 {
+			return nil, fmt.Errorf("lambda must have at least one parameter")
+		}
+		params := make([]*Ident, 0, len(e.Items.Items))
+		for _, item := range e.Items.Items {
+			if column, ok := item.(*ColumnExpr); ok && column.Alias == nil {
+				item = column.Expr
+			}
+			ident, ok := item.(*Ident)
+			if !ok {
+				return nil, fmt.Errorf("lambda parameter must be an identifier, got %q", Format(item))
+			}
+			params = append(params, ident)
+		}
+		return params, nil
+	default:
+		return nil, fmt.Errorf("lambda parameters must be an identifier or a parenthesized list of identifiers, got %q", Format(expr))
+	}
+}
+
 func (p *Parser) parseExpr(pos Pos) (Expr, error) {
 	return p.parseSubExpr(pos, PrecedenceUnknown)
 }
@@ -410,6 +532,15 @@ func (p *Parser) parseUnaryExpr(pos Pos) (Expr, error) {
 		p.matchTokenKind(TokenKindMinus):
 		_ = p.lexer.consumeToken()
 		expr, err = p.parseColumnExpr(p.Pos())
+		// fold the sign into -inf and +nan the way the lexer does for digits
+		if number, ok := expr.(*NumberLiteral); ok && isInfOrNaN(number.Literal) {
+			return &NumberLiteral{
+				NumPos:  pos,
+				NumEnd:  number.NumEnd,
+				Literal: op.String + number.Literal,
+				Base:    10,
+			}, nil
+		}
 	case p.matchKeyword(KeywordNot):
 		_ = p.lexer.consumeToken()
 		// Prefix NOT binds looser than comparisons: `NOT a = b` negates the
@@ -431,6 +562,15 @@ func (p *Parser) parseUnaryExpr(pos Pos) (Expr, error) {
 
 }
 
+// matchInfOrNaN reports whether the current token is an unquoted inf,
+// infinity or nan.
+func (p *Parser) matchInfOrNaN() bool {
+	token := p.current()
+	return token != nil && token.QuoteType == Unquoted &&
+		(token.Kind == TokenKindIdent || token.Kind == TokenKindKeyword) &&
+		isInfOrNaN(token.String)
+}
+
 func (p *Parser) peekTokenKind(kind TokenKind) bool {
 	if p.lexer.isEOF() {
 		return false
@@ -485,6 +625,11 @@ func (p *Parser) peekIsClauseStarterKeyword() bool {
 	return false
 }
 
+// isEndOfStatement reports whether the current token is EOF or `;`.
+func (p *Parser) isEndOfStatement() bool {
+	return p.current() == nil || p.matchTokenKind(";")
+}
+
 // peekIsEndOfStatement reports whether the next token is EOF or `;`.
 func (p *Parser) peekIsEndOfStatement() bool {
 	next, err := p.lexer.peekToken()
@@ -572,6 +717,19 @@ func (p *Parser) parseColumnExpr(pos Pos) (Expr, error) { //nolint:funlen
 		return p.parseIdentOrFunction(pos)
 	}
 
+	// inf and nan are float literals rather than column names, unless they
+	// are called or qualified.
+	if p.matchInfOrNaN() && !p.peekTokenKind(TokenKindLParen) && !p.peekTokenKind(TokenKindDot) {
+		token := p.current()
+		_ = p.lexer.consumeToken()
+		return &NumberLiteral{
+			NumPos:  token.Pos,
+			NumEnd:  token.End,
+			Literal: token.String,
+			Base:    10,
+		}, nil
+	}
+
 	// Parse the keyword as an identifier if it is followed by `,`, `AS`,
 	// another clause-starter keyword, or end-of-statement (EOF or `;`).
 	// ClickHouse accepts most reserved words as bare column names in
@@ -604,6 +762,9 @@ func (p *Parser) parseColumnExpr(pos Pos) (Expr, error) { //nolint:funlen
 		if _, failed := p.failedIntervalOffsets[intervalPos]; failed {
 			return p.parseAnyKeyword()
 		}
+		if literal, ok, err := p.tryParseIntervalLiteral(); ok || err != nil {
+			return literal, err
+		}
 
 		savedState := p.lexer.saveState()
 		interval, err := p.parseInterval(true)
@@ -624,7 +785,7 @@ func (p *Parser) parseColumnExpr(pos Pos) (Expr, error) { //nolint:funlen
 			return nil, err
 		}
 		if nextToken != nil && nextToken.Kind == TokenKindString {
-			return p.parseString(p.Pos())
+			return p.parseTypedLiteral()
 		}
 		return p.parseIdentOrFunction(pos)
 	case p.matchKeyword(KeywordCast):
@@ -822,6 +983,58 @@ func (p *Parser) parseInterval(requireKeyword bool) (*IntervalExpr, error) {
 	}, nil
 }
 
+// tryParseIntervalLiteral parses INTERVAL followed by a string that carries
+// its own units, such as INTERVAL '1 day'. It reports false and consumes
+// nothing when the string is followed by a unit instead (INTERVAL '1' DAY),
+// or when INTERVAL is not followed by a string at all.
+func (p *Parser) tryParseIntervalLiteral() (*IntervalLiteral, bool, error) {
+	if next, err := p.lexer.peekToken(); err != nil || next == nil || next.Kind != TokenKindString {
+		return nil, false, nil
+	}
+	savedState := p.lexer.saveState()
+	intervalPos := p.Pos()
+	_ = p.lexer.consumeToken()
+	value, err := p.parseString(p.Pos())
+	if err != nil {
+		return nil, false, err
+	}
+	if unit := p.current(); unit != nil &&
+		(unit.Kind == TokenKindIdent || unit.Kind == TokenKindKeyword) &&
+		intervalUnits.Contains(strings.ToUpper(unit.String)) {
+		p.lexer.restoreState(savedState)
+		return nil, false, nil
+	}
+	parts, err := parseIntervalParts(value.Value())
+	if err != nil {
+		return nil, false, err
+	}
+	return &IntervalLiteral{
+		IntervalPos: intervalPos,
+		Value:       value,
+		Parts:       parts,
+	}, true, nil
+}
+
+// parseTypedLiteral parses DATE or TIMESTAMP followed by a string literal.
+func (p *Parser) parseTypedLiteral() (*TypedLiteral, error) {
+	typePos := p.Pos()
+	typeToken := p.current()
+	_ = p.lexer.consumeToken()
+	value, err := p.parseString(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	return &TypedLiteral{
+		TypePos: typePos,
+		Type: &Ident{
+			NamePos: typeToken.Pos,
+			NameEnd: typeToken.End,
+			Name:    typeToken.String,
+		},
+		Value: value,
+	}, nil
+}
+
 func (p *Parser) parseFunctionExpr(_ Pos) (*FunctionExpr, error) {
 	// parse function name; callers gate entry (select-item modifiers match
 	// EXCEPT/APPLY/REPLACE first, INSERT INTO FUNCTION follows the FUNCTION
@@ -1807,6 +2020,31 @@ func (p *Parser) tryParseCompressionCodecs(pos Pos) (*CompressionCodec, error) {
 	}, nil
 }
 
+func (p *Parser) tryParseStatisticsClause(pos Pos) (*StatisticsClause, error) {
+	if !p.tryConsumeKeywords(KeywordStatistics) {
+		return nil, nil // nolint
+	}
+	if err := p.expectTokenKind(TokenKindLParen); err != nil {
+		return nil, err
+	}
+	statistics := &StatisticsClause{StatisticsPos: pos}
+	for {
+		statType, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		statistics.Types = append(statistics.Types, statType)
+		if p.tryConsumeTokenKind(TokenKindComma) == nil {
+			break
+		}
+	}
+	statistics.RightParenPos = p.End()
+	if err := p.expectTokenKind(TokenKindRParen); err != nil {
+		return nil, err
+	}
+	return statistics, nil
+}
+
 func (p *Parser) parseEnumValueExpr(pos Pos) (*EnumValue, error) {
 	name, err := p.parseString(pos)
 	if err != nil {
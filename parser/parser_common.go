@@ -232,7 +232,7 @@ func (p *Parser) parseIdentOrString() (*Ident, error) {
 		return &Ident{
 			NamePos:   curToken.Pos,
 			NameEnd:   curToken.End,
-			Name:      curToken.String,
+			Name:      decodeString(curToken.StringKind, curToken.String),
 			QuoteType: SingleQuote, // Treat string literals as single-quoted identifiers
 		}, nil
 	default:
@@ -392,6 +392,8 @@ func (p *Parser) parseString(pos Pos) (*StringLiteral, error) {
 		LiteralPos: pos,
 		LiteralEnd: curToken.End,
 		Literal:    curToken.String,
+		Kind:       curToken.StringKind,
+		HeredocTag: curToken.HeredocTag,
 	}
 	return str, nil
 }
@@ -463,6 +465,11 @@ func (p *Parser) wrapError(err error) error {
 		return nil
 	}
 
+	if p.lexer.err != nil {
+		// parsing stopped at input the lexer could not read, which explains
+		// the failure better than the missing token does
+		err = p.lexer.err
+	}
 	var pe *ParseError
 	if !errors.As(err, &pe) {
 		pe = &ParseError{
@@ -472,7 +479,7 @@ func (p *Parser) wrapError(err error) error {
 		}
 	}
 	if pe.Line == 0 {
-		pe.Line, pe.Column = p.lineStarts().position(int(pe.Pos))
+		pe.Line, pe.Column = p.lineStarts().position(p.lexer.input, int(pe.Pos))
 	}
 	pe.input = p.lexer.input
 	pe.starts = p.lineStarts()
@@ -1,6 +1,8 @@
 package parser
 
-func (p *Parser) parseDropDatabase(pos Pos) (*DropDatabase, error) {
+import "fmt"
+
+func (p *Parser) parseDropDatabase(pos Pos, isDetach bool) (*DropDatabase, error) {
 	if err := p.expectKeyword(KeywordDatabase); err != nil {
 		return nil, err
 	}
@@ -25,6 +27,14 @@ func (p *Parser) parseDropDatabase(pos Pos) (*DropDatabase, error) {
 		statementEnd = onCluster.End()
 	}
 
+	permanently, err := p.tryParsePermanently(isDetach)
+	if err != nil {
+		return nil, err
+	}
+	if permanently {
+		statementEnd = p.Pos()
+	}
+
 	modifier, err := p.tryParseModifier()
 	if err != nil {
 		return nil, err
@@ -35,15 +45,17 @@ func (p *Parser) parseDropDatabase(pos Pos) (*DropDatabase, error) {
 
 	return &DropDatabase{
 		DropPos:      pos,
+		IsDetach:     isDetach,
 		Name:         name,
 		IfExists:     isExists,
 		OnCluster:    onCluster,
+		Permanently:  permanently,
 		Modifier:     modifier,
 		StatementEnd: statementEnd,
 	}, nil
 }
 
-func (p *Parser) parseDropStmt(pos Pos) (*DropStmt, error) {
+func (p *Parser) parseDropStmt(pos Pos, isDetach bool) (*DropStmt, error) {
 	var isTemporary bool
 	dropTarget := KeywordTable
 	switch {
@@ -73,6 +85,11 @@ func (p *Parser) parseDropStmt(pos Pos) (*DropStmt, error) {
 		return nil, err
 	}
 
+	permanently, err := p.tryParsePermanently(isDetach)
+	if err != nil {
+		return nil, err
+	}
+
 	modifier, err := p.tryParseModifier()
 	if err != nil {
 		return nil, err
@@ -80,16 +97,31 @@ func (p *Parser) parseDropStmt(pos Pos) (*DropStmt, error) {
 
 	return &DropStmt{
 		DropPos:      pos,
+		IsDetach:     isDetach,
 		DropTarget:   dropTarget,
 		Name:         name,
 		IfExists:     isExists,
 		OnCluster:    onCluster,
 		IsTemporary:  isTemporary,
+		Permanently:  permanently,
 		Modifier:     modifier,
 		StatementEnd: p.Pos(),
 	}, nil
 }
 
+// tryParsePermanently parses the PERMANENTLY modifier, which keeps a detached
+// object from being re-attached on server restart. It only exists for DETACH.
+func (p *Parser) tryParsePermanently(isDetach bool) (bool, error) {
+	if !p.matchKeyword(KeywordPermanently) {
+		return false, nil
+	}
+	if !isDetach {
+		return false, fmt.Errorf("PERMANENTLY is only allowed with DETACH")
+	}
+	_ = p.lexer.consumeToken()
+	return true, nil
+}
+
 func (p *Parser) tryParseModifier() (string, error) {
 	switch {
 	case p.tryConsumeKeywords(KeywordSync):
@@ -4,8 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-
-	"slices"
 )
 
 func (p *Parser) tryParseWithClause(pos Pos) (*WithClause, error) {
@@ -169,82 +167,91 @@ func (p *Parser) tryParseJoinConstraints(pos Pos) (Expr, error) {
 	return nil, nil
 }
 
-func (p *Parser) parseJoinType(_ Pos) []string {
-	var modifiers []string
-	switch {
-	case p.tryConsumeKeywords(KeywordCross): // cross join
-		modifiers = append(modifiers, KeywordCross)
-	case p.matchKeyword(KeywordAny), p.matchKeyword(KeywordAll):
-		modifiers = append(modifiers, p.current().String)
-		_ = p.lexer.consumeToken()
-		if p.matchKeyword(KeywordFull) {
-			modifiers = append(modifiers, p.current().String)
-			_ = p.lexer.consumeToken()
-		}
-		if p.matchKeyword(KeywordLeft) || p.matchKeyword(KeywordRight) || p.matchKeyword(KeywordInner) || p.matchKeyword(KeywordOuter) {
-			modifiers = append(modifiers, p.current().String)
-			_ = p.lexer.consumeToken()
-		}
-	case p.matchKeyword(KeywordSemi), p.matchKeyword(KeywordAsof):
-		modifiers = append(modifiers, p.current().String)
-		_ = p.lexer.consumeToken()
-		if p.matchKeyword(KeywordLeft) || p.matchKeyword(KeywordRight) {
-			modifiers = append(modifiers, p.current().String)
-			_ = p.lexer.consumeToken()
-		}
-		if p.matchKeyword(KeywordOuter) {
-			modifiers = append(modifiers, p.current().String)
-			_ = p.lexer.consumeToken()
-		}
-	case p.matchKeyword(KeywordInner):
-		modifiers = append(modifiers, p.current().String)
-		_ = p.lexer.consumeToken()
-		if p.matchKeyword(KeywordAll) || p.matchKeyword(KeywordAny) || p.matchKeyword(KeywordAsof) || p.matchKeyword(KeywordArray) {
-			modifiers = append(modifiers, p.current().String)
-			_ = p.lexer.consumeToken()
-		}
-	case p.matchKeyword(KeywordLeft):
-		modifiers = append(modifiers, p.current().String)
-		_ = p.lexer.consumeToken()
-		if p.matchKeyword(KeywordOuter) {
-			modifiers = append(modifiers, p.current().String)
-			_ = p.lexer.consumeToken()
-		}
-		if p.matchKeyword(KeywordSemi) || p.matchKeyword(KeywordAnti) ||
-			p.matchKeyword(KeywordAny) || p.matchKeyword(KeywordAll) ||
-			p.matchKeyword(KeywordAsof) || p.matchKeyword(KeywordArray) {
-			modifiers = append(modifiers, p.current().String)
+// joinOperator is what precedes the JOIN keyword of a join.
+type joinOperator struct {
+	locality     JoinLocality
+	strictness   JoinStrictness
+	kind         JoinKind
+	implicitKind bool
+	hasOuter     bool
+}
+
+// isEmpty reports whether no join keyword was parsed.
+func (o joinOperator) isEmpty() bool {
+	return o.locality == JoinLocalityNone && o.strictness == JoinStrictnessNone && o.kind == JoinKindNone
+}
+
+// matchPaste reports whether the current token starts PASTE JOIN. PASTE is
+// not a keyword, so it is only recognized right before JOIN.
+func (p *Parser) matchPaste() bool {
+	return p.matchTokenKind(TokenKindIdent) && p.current().QuoteType == Unquoted &&
+		strings.EqualFold(p.current().String, "PASTE") && p.peekKeyword(KeywordJoin)
+}
+
+// parseJoinType parses the strictness and kind keywords of a join. ClickHouse
+// accepts the strictness on either side of the kind (`ANY LEFT`, `LEFT ANY`),
+// OUTER after LEFT, RIGHT or FULL, and ARRAY right before JOIN.
+func (p *Parser) parseJoinType(op *joinOperator) error {
+	for {
+		token := p.current()
+		switch {
+		case p.matchOneOfKeywords(KeywordAll, KeywordAny, KeywordSemi, KeywordAnti, KeywordAsof):
+			if op.strictness != JoinStrictnessNone {
+				return fmt.Errorf("unexpected %s after %s in join", token.String, op.strictness)
+			}
+			op.strictness = JoinStrictness(strings.ToUpper(token.String))
+		case p.matchOneOfKeywords(KeywordInner, KeywordLeft, KeywordRight, KeywordFull, KeywordCross), p.matchPaste():
+			if op.kind != JoinKindNone {
+				return fmt.Errorf("unexpected %s after %s in join", token.String, op.kind)
+			}
+			op.kind = JoinKind(strings.ToUpper(token.String))
+		case p.matchKeyword(KeywordOuter):
+			if op.hasOuter || (op.kind != JoinKindLeft && op.kind != JoinKindRight && op.kind != JoinKindFull) {
+				return errors.New("OUTER must follow LEFT, RIGHT or FULL")
+			}
+			op.hasOuter = true
+		case p.matchKeyword(KeywordArray):
 			_ = p.lexer.consumeToken()
+			if op.strictness != JoinStrictnessNone || op.hasOuter ||
+				(op.kind != JoinKindNone && op.kind != JoinKindInner && op.kind != JoinKindLeft) {
+				return errors.New("ARRAY JOIN can only be preceded by LEFT or INNER")
+			}
+			if op.kind == JoinKindLeft {
+				op.kind = JoinKindLeftArray
+			} else {
+				op.kind = JoinKindArray
+			}
+			return nil
+		default:
+			return nil
 		}
-	case p.matchKeyword(KeywordRight):
-		modifiers = append(modifiers, p.current().String)
 		_ = p.lexer.consumeToken()
-		if p.matchKeyword(KeywordOuter) {
-			modifiers = append(modifiers, p.current().String)
-			_ = p.lexer.consumeToken()
-		}
-		if p.matchKeyword(KeywordSemi) || p.matchKeyword(KeywordAnti) ||
-			p.matchKeyword(KeywordAny) || p.matchKeyword(KeywordAll) ||
-			p.matchKeyword(KeywordAsof) {
-			modifiers = append(modifiers, p.current().String)
-			_ = p.lexer.consumeToken()
+	}
+}
+
+// validateJoinOperator fills in the implicit INNER kind and rejects
+// combinations the server does not support.
+func validateJoinOperator(op *joinOperator) error {
+	if op.kind == JoinKindNone {
+		op.kind = JoinKindInner
+		op.implicitKind = true
+	}
+	switch op.strictness {
+	case JoinStrictnessNone:
+	case JoinStrictnessSemi, JoinStrictnessAnti:
+		if op.kind != JoinKindLeft && op.kind != JoinKindRight {
+			return fmt.Errorf("%s JOIN must be LEFT or RIGHT, got %s", op.strictness, op.kind)
 		}
-	case p.matchKeyword(KeywordFull):
-		modifiers = append(modifiers, p.current().String)
-		_ = p.lexer.consumeToken()
-		if p.matchKeyword(KeywordOuter) {
-			modifiers = append(modifiers, p.current().String)
-			_ = p.lexer.consumeToken()
+	case JoinStrictnessAsof:
+		if op.kind != JoinKindInner && op.kind != JoinKindLeft {
+			return fmt.Errorf("ASOF cannot be combined with %s JOIN", op.kind)
 		}
-		if p.matchKeyword(KeywordAll) || p.matchKeyword(KeywordAny) {
-			modifiers = append(modifiers, p.current().String)
-			_ = p.lexer.consumeToken()
+	default:
+		if op.kind == JoinKindCross || op.kind == JoinKindPaste {
+			return fmt.Errorf("%s cannot be combined with %s JOIN", op.strictness, op.kind)
 		}
-	case p.matchKeyword(KeywordArray):
-		modifiers = append(modifiers, p.current().String)
-		_ = p.lexer.consumeToken()
 	}
-	return modifiers
+	return nil
 }
 
 func (p *Parser) parseJoinTableExpr(_ Pos) (Expr, error) {
@@ -280,39 +287,41 @@ func (p *Parser) parseJoinTableExpr(_ Pos) (Expr, error) {
 	}
 }
 
-// parseJoinModifiers parses the modifiers that may precede JOIN: an optional
+// parseJoinModifiers parses the operator that may precede JOIN: an optional
 // GLOBAL/LOCAL locality followed by the join type. When a consumed locality
-// turns out not to precede a join, it rewinds the lexer and returns nil,
-// leaving the keyword for the caller to reject.
-func (p *Parser) parseJoinModifiers(pos Pos) ([]string, error) {
+// turns out not to precede a join, it rewinds the lexer and returns an empty
+// operator, leaving the keyword for the caller to reject.
+func (p *Parser) parseJoinModifiers() (joinOperator, error) {
+	var op joinOperator
 	if !p.matchOneOfKeywords(KeywordGlobal, KeywordLocal) {
-		return p.parseJoinType(pos), nil
+		return op, p.parseJoinType(&op)
 	}
 
 	savedState := p.lexer.saveState()
 	locality := p.current()
 	_ = p.lexer.consumeToken()
 
-	joinType := p.parseJoinType(p.Pos())
-	if len(joinType) == 0 && !p.matchKeyword(KeywordJoin) {
+	if err := p.parseJoinType(&op); err != nil {
+		return op, err
+	}
+	if op.isEmpty() && !p.matchKeyword(KeywordJoin) {
 		p.lexer.restoreState(savedState)
-		return nil, nil
+		return op, nil
 	}
 
 	// ARRAY JOIN reads a column list rather than a distributed table, so it has
-	// no locality. Modifiers keep their source spelling, hence EqualFold.
-	if slices.ContainsFunc(joinType, func(modifier string) bool {
-		return strings.EqualFold(modifier, KeywordArray)
-	}) {
+	// no locality.
+	if op.kind == JoinKindArray || op.kind == JoinKindLeftArray {
 		// point at the locality, not at wherever the join op stopped
-		return nil, &ParseError{
+		return op, &ParseError{
 			Pos: locality.Pos,
 			Got: locality,
 			Msg: fmt.Sprintf("%s cannot be combined with ARRAY JOIN", locality.String),
 		}
 	}
 
-	return append([]string{locality.String}, joinType...), nil
+	op.locality = JoinLocality(strings.ToUpper(locality.String))
+	return op, nil
 }
 
 // peekJoinAfterLocality reports whether the current GLOBAL/LOCAL keyword is
@@ -322,42 +331,42 @@ func (p *Parser) peekJoinAfterLocality() bool {
 	savedState := p.lexer.saveState()
 	defer p.lexer.restoreState(savedState)
 
-	modifiers, err := p.parseJoinModifiers(p.Pos())
+	op, err := p.parseJoinModifiers()
 	if err != nil {
 		// A malformed locality join such as GLOBAL ARRAY JOIN still belongs
 		// to the FROM clause, which reports the error.
 		return true
 	}
 
-	return modifiers != nil && p.matchKeyword(KeywordJoin)
+	return !op.isEmpty() && p.matchKeyword(KeywordJoin)
 }
 
 func (p *Parser) parseJoinRightExpr(pos Pos) (expr Expr, err error) {
 	var rightExpr Expr
-	var modifiers []string
+	var op joinOperator
 	switch {
 	case p.tryConsumeTokenKind(TokenKindComma) != nil:
 		return p.parseJoinExpr(p.Pos())
 	default:
 		// GLOBAL/LOCAL only says how the right-hand table is distributed, so
 		// the join type still follows it: `GLOBAL LEFT JOIN` is a LEFT join.
-		modifiers, err = p.parseJoinModifiers(p.Pos())
+		op, err = p.parseJoinModifiers()
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if len(modifiers) != 0 && !p.matchKeyword(KeywordJoin) {
+	if !op.isEmpty() && !p.matchKeyword(KeywordJoin) {
 		return nil, fmt.Errorf("expected JOIN, got %s", p.currentTokenKind())
 	}
 	if !p.tryConsumeKeywords(KeywordJoin) {
 		return nil, nil
 	}
+	if err := validateJoinOperator(&op); err != nil {
+		return nil, err
+	}
 
-	modifiers = append(modifiers, KeywordJoin)
-
-	// Check if this is an ARRAY JOIN
-	if slices.Contains(modifiers, KeywordArray) {
+	if op.kind == JoinKindArray || op.kind == JoinKindLeftArray {
 		// For ARRAY JOIN, parse column expression list instead of table expression
 		expr, err = p.parseColumnExprList(p.Pos())
 		if err != nil {
@@ -371,11 +380,12 @@ func (p *Parser) parseJoinRightExpr(pos Pos) (expr Expr, err error) {
 			return nil, err
 		}
 		return &JoinExpr{
-			JoinPos:     pos,
-			Left:        expr,
-			Right:       rightExpr,
-			Modifiers:   modifiers,
-			Constraints: nil,
+			JoinPos:    pos,
+			Left:       expr,
+			Right:      rightExpr,
+			Locality:   op.locality,
+			Strictness: op.strictness,
+			Kind:       op.kind,
 		}, nil
 	}
 
@@ -387,6 +397,9 @@ func (p *Parser) parseJoinRightExpr(pos Pos) (expr Expr, err error) {
 	if err != nil {
 		return nil, err
 	}
+	if constrains != nil && (op.kind == JoinKindCross || op.kind == JoinKindPaste) {
+		return nil, fmt.Errorf("%s JOIN cannot have an ON or USING clause", op.kind)
+	}
 
 	// try parse next join
 	rightExpr, err = p.parseJoinRightExpr(p.Pos())
@@ -394,11 +407,15 @@ func (p *Parser) parseJoinRightExpr(pos Pos) (expr Expr, err error) {
 		return nil, err
 	}
 	return &JoinExpr{
-		JoinPos:     pos,
-		Left:        expr,
-		Right:       rightExpr,
-		Modifiers:   modifiers,
-		Constraints: constrains,
+		JoinPos:      pos,
+		Left:         expr,
+		Right:        rightExpr,
+		Locality:     op.locality,
+		Strictness:   op.strictness,
+		Kind:         op.kind,
+		ImplicitKind: op.implicitKind,
+		HasOuter:     op.hasOuter,
+		Constraints:  constrains,
 	}, nil
 }
 
@@ -425,25 +442,7 @@ func (p *Parser) parseTableExpr(pos Pos) (*TableExpr, error) {
 	var err error
 	switch {
 	case p.matchTokenKind(TokenKindString), p.matchTokenKind(TokenKindIdent):
-		// table name
-		tableIdentifier, err := p.parseTableIdentifier(p.Pos())
-		if err != nil {
-			return nil, err
-		}
-		// it's a table name
-		if tableIdentifier.Database != nil || !p.matchTokenKind(TokenKindLParen) { // database.table
-			expr = tableIdentifier
-		} else {
-			// table function expr
-			tableArgs, err := p.parseTableArgList(p.Pos())
-			if err != nil {
-				return nil, err
-			}
-			expr = &TableFunctionExpr{
-				Name: tableIdentifier.Table,
-				Args: tableArgs,
-			}
-		}
+		expr, err = p.parseTableIdentifierOrFunction(p.Pos())
 	case p.matchTokenKind(TokenKindLParen):
 		expr, err = p.parseSubQuery(p.Pos())
 	default:
@@ -467,7 +466,7 @@ func (p *Parser) parseTableExpr(pos Pos) (*TableExpr, error) {
 			Alias:    alias,
 		}
 		tableEnd = expr.End()
-	} else if p.matchTokenKind(TokenKindIdent) && p.currentTokenKind() != TokenKindKeyword {
+	} else if p.matchTokenKind(TokenKindIdent) && p.currentTokenKind() != TokenKindKeyword && !p.matchPaste() {
 		alias, err := p.parseIdent()
 		if err != nil {
 			return nil, err
@@ -500,6 +499,28 @@ func (p *Parser) parseTableExpr(pos Pos) (*TableExpr, error) {
 	}, nil
 }
 
+// parseTableIdentifierOrFunction parses a table name, database.table, or a
+// table function call such as numbers(10).
+func (p *Parser) parseTableIdentifierOrFunction(pos Pos) (Expr, error) {
+	tableIdentifier, err := p.parseTableIdentifier(pos)
+	if err != nil {
+		return nil, err
+	}
+	// it's a table name
+	if tableIdentifier.Database != nil || !p.matchTokenKind(TokenKindLParen) { // database.table
+		return tableIdentifier, nil
+	}
+	// table function expr
+	tableArgs, err := p.parseTableArgList(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	return &TableFunctionExpr{
+		Name: tableIdentifier.Table,
+		Args: tableArgs,
+	}, nil
+}
+
 func (p *Parser) tryParsePrewhereClause(pos Pos) (*PrewhereClause, error) {
 	if !p.matchKeyword(KeywordPrewhere) {
 		return nil, nil
@@ -630,34 +651,82 @@ func (p *Parser) tryParseLimitClause(pos Pos) (*LimitClause, error) {
 }
 
 func (p *Parser) parseLimitClause(pos Pos) (*LimitClause, error) {
-	var limit Expr
-	var offset Expr
+	limit := &LimitClause{LimitPos: pos}
 	var err error
 	if p.tryConsumeKeywords(KeywordLimit) {
-		limit, err = p.parseExpr(p.Pos())
+		limit.Limit, err = p.parseExpr(p.Pos())
 		if err != nil {
 			return nil, err
 		}
 
 		if p.tryConsumeKeywords(KeywordOffset) {
-			offset, err = p.parseExpr(p.Pos())
+			limit.Offset, err = p.parseExpr(p.Pos())
 		} else if p.tryConsumeTokenKind(TokenKindComma) != nil {
-			offset = limit
-			limit, err = p.parseExpr(p.Pos())
+			limit.Offset = limit.Limit
+			limit.Limit, err = p.parseExpr(p.Pos())
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if p.matchKeyword(KeywordWith) && p.peekKeyword(KeywordTies) {
+			_ = p.lexer.consumeToken()
+			limit.WithTies = true
+			limit.KeywordEnd = p.End()
+			_ = p.lexer.consumeToken()
 		}
 	} else if p.tryConsumeKeywords(KeywordOffset) {
-		offset, err = p.parseExpr(p.Pos())
+		limit.Offset, err = p.parseExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		if p.matchOneOfKeywords(KeywordRow, KeywordRows) {
+			limit.KeywordEnd = p.End()
+			_ = p.lexer.consumeToken()
+		}
+		if p.matchKeyword(KeywordFetch) {
+			if err := p.parseFetchClause(limit); err != nil {
+				return nil, err
+			}
+		}
 	}
 
+	return limit, nil
+}
+
+// parseFetchClause parses FETCH FIRST|NEXT n ROW|ROWS ONLY|WITH TIES into
+// limit, which already holds the OFFSET that must precede it.
+func (p *Parser) parseFetchClause(limit *LimitClause) error {
+	if err := p.expectKeyword(KeywordFetch); err != nil {
+		return err
+	}
+	if !p.matchOneOfKeywords(KeywordFirst, KeywordNext) {
+		return fmt.Errorf("expected FIRST or NEXT after FETCH, got %s", p.currentTokenKind())
+	}
+	_ = p.lexer.consumeToken()
+
+	count, err := p.parseExpr(p.Pos())
 	if err != nil {
-		return nil, err
+		return err
 	}
+	if !p.matchOneOfKeywords(KeywordRow, KeywordRows) {
+		return fmt.Errorf("expected ROW or ROWS after FETCH count, got %s", p.currentTokenKind())
+	}
+	_ = p.lexer.consumeToken()
 
-	return &LimitClause{
-		LimitPos: pos,
-		Limit:    limit,
-		Offset:   offset,
-	}, nil
+	switch {
+	case p.matchKeyword(KeywordOnly):
+	case p.tryConsumeKeywords(KeywordWith) && p.matchKeyword(KeywordTies):
+		limit.WithTies = true
+	default:
+		return fmt.Errorf("expected ONLY or WITH TIES after FETCH, got %s", p.currentTokenKind())
+	}
+	limit.KeywordEnd = p.End()
+	_ = p.lexer.consumeToken()
+
+	limit.Limit = count
+	limit.Fetch = true
+	return nil
 }
 
 func (p *Parser) tryParseLimitByClause(pos Pos) (Expr, error) {
@@ -706,6 +775,9 @@ func (p *Parser) parseLimitByClause(pos Pos) (Expr, error) {
 	if !p.tryConsumeKeywords(KeywordBy) {
 		return limit, nil
 	}
+	if limit.WithTies {
+		return nil, fmt.Errorf("WITH TIES cannot be used with LIMIT BY")
+	}
 	if by, err = p.parseColumnExprListWithLParen(p.Pos()); err != nil {
 		return nil, err
 	}
@@ -1081,6 +1153,15 @@ func (p *Parser) parseSelectQuery(_ Pos) (*SelectQuery, error) {
 			selectStmt.StatementEnd = settings.End()
 		}
 
+		intoOutfile, err := p.tryParseIntoOutfileClause(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		if intoOutfile != nil {
+			selectStmt.IntoOutfile = intoOutfile
+			selectStmt.StatementEnd = intoOutfile.End()
+		}
+
 		format, err := p.tryParseFormat(p.Pos())
 		if err != nil {
 			return nil, err
@@ -1090,10 +1171,10 @@ func (p *Parser) parseSelectQuery(_ Pos) (*SelectQuery, error) {
 			selectStmt.StatementEnd = format.End()
 		}
 
-		// ClickHouse allows a set operator after ')' only when no SETTINGS
-		// or FORMAT was consumed: (SELECT 1) SETTINGS a=1 UNION ALL SELECT 2
-		// is a syntax error there.
-		if settings != nil || format != nil {
+		// ClickHouse allows a set operator after ')' only when no SETTINGS,
+		// INTO OUTFILE or FORMAT was consumed: (SELECT 1) SETTINGS a=1
+		// UNION ALL SELECT 2 is a syntax error there.
+		if settings != nil || intoOutfile != nil || format != nil {
 			return selectStmt, nil
 		}
 	} else {
@@ -1270,6 +1351,10 @@ func (p *Parser) parseSelectStmt(pos Pos) (*SelectQuery, error) { // nolint: fun
 		}
 	}
 
+	if limit != nil && limit.WithTies && orderBy == nil {
+		return nil, fmt.Errorf("WITH TIES requires ORDER BY")
+	}
+
 	settings, err := p.tryParseSettingsClause(p.Pos())
 	if err != nil {
 		return nil, err
@@ -1278,6 +1363,14 @@ func (p *Parser) parseSelectStmt(pos Pos) (*SelectQuery, error) { // nolint: fun
 		statementEnd = settings.End()
 	}
 
+	intoOutfile, err := p.tryParseIntoOutfileClause(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	if intoOutfile != nil {
+		statementEnd = intoOutfile.End()
+	}
+
 	format, err := p.tryParseFormat(p.Pos())
 	if err != nil {
 		return nil, err
@@ -1304,11 +1397,76 @@ func (p *Parser) parseSelectStmt(pos Pos) (*SelectQuery, error) { // nolint: fun
 		LimitBy:      limitBy,
 		Limit:        limit,
 		Settings:     settings,
+		IntoOutfile:  intoOutfile,
 		Format:       format,
 		WithTotal:    withTotal,
 	}, nil
 }
 
+func (p *Parser) tryParseIntoOutfileClause(pos Pos) (*IntoOutfileClause, error) {
+	if !p.matchKeyword(KeywordInto) || !p.peekKeyword(KeywordOutfile) {
+		return nil, nil // nolint
+	}
+	return p.parseIntoOutfileClause(pos)
+}
+
+// parseIntoOutfileClause parses INTO OUTFILE 'file' followed by its options,
+// which ClickHouse accepts in any order.
+func (p *Parser) parseIntoOutfileClause(pos Pos) (*IntoOutfileClause, error) {
+	if err := p.expectKeyword(KeywordInto); err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword(KeywordOutfile); err != nil {
+		return nil, err
+	}
+	fileName, err := p.parseString(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	clause := &IntoOutfileClause{
+		IntoPos:   pos,
+		ClauseEnd: fileName.End(),
+		FileName:  fileName,
+	}
+
+	for {
+		switch {
+		case p.matchKeyword(KeywordAnd) && p.peekKeyword(KeywordStdout):
+			if clause.AndStdout {
+				return nil, fmt.Errorf("duplicate AND STDOUT in INTO OUTFILE")
+			}
+			_ = p.lexer.consumeToken()
+			clause.AndStdout = true
+			clause.ClauseEnd = p.End()
+			_ = p.lexer.consumeToken()
+		case p.matchOneOfKeywords(KeywordAppend, KeywordTruncate):
+			if clause.Mode != OutfileModeNone {
+				return nil, fmt.Errorf("unexpected %s after %s in INTO OUTFILE", p.current().String, clause.Mode)
+			}
+			clause.Mode = OutfileMode(strings.ToUpper(p.current().String))
+			clause.ClauseEnd = p.End()
+			_ = p.lexer.consumeToken()
+		case p.matchKeyword(KeywordCompression):
+			if clause.Compression != nil {
+				return nil, fmt.Errorf("duplicate COMPRESSION in INTO OUTFILE")
+			}
+			_ = p.lexer.consumeToken()
+			if clause.Compression, err = p.parseString(p.Pos()); err != nil {
+				return nil, err
+			}
+			clause.ClauseEnd = clause.Compression.End()
+			if p.tryConsumeKeywords(KeywordLevel) {
+				if clause.CompressionLevel, err = p.parseNumber(p.Pos()); err != nil {
+					return nil, err
+				}
+				clause.ClauseEnd = clause.CompressionLevel.End()
+			}
+		default:
+			return clause, nil
+		}
+	}
+}
+
 func (p *Parser) parseCTEStmt(pos Pos) (*CTEStmt, error) {
 	expr, err := p.parseExpr(pos)
 	if err != nil {
@@ -1386,19 +1544,36 @@ func (p *Parser) parseExplainStmt(pos Pos) (*ExplainStmt, error) {
 	case p.matchKeyword(KeywordSyntax),
 		p.matchKeyword(KeywordPipeline),
 		p.matchKeyword(KeywordEstimate),
-		p.matchKeyword(KeywordAst):
-		explainType = p.current().String
+		p.matchKeyword(KeywordAst),
+		p.matchTokenKind(TokenKindIdent) && p.current().QuoteType == Unquoted && strings.EqualFold(p.current().String, "PLAN"):
+		explainType = strings.ToUpper(p.current().String)
 		_ = p.lexer.consumeToken()
-	default:
-		return nil, fmt.Errorf("expected SYNTAX, PIPELINE, ESTIMATE or AST, got %s", p.currentTokenKind())
+	case p.matchKeyword(KeywordQuery):
+		_ = p.lexer.consumeToken()
+		if !p.matchTokenKind(TokenKindIdent) || !strings.EqualFold(p.current().String, "TREE") {
+			return nil, fmt.Errorf("expected TREE after EXPLAIN QUERY, got %s", p.currentTokenKind())
+		}
+		_ = p.lexer.consumeToken()
+		explainType = "QUERY TREE"
 	}
-	stmt, err := p.parseSelectQuery(p.Pos())
+
+	// EXPLAIN settings are a bare list: EXPLAIN PLAN header = 1, actions = 1
+	var settings []*SettingExpr
+	if p.matchTokenKind(TokenKindIdent, TokenKindKeyword) && p.peekTokenKind(TokenKindSingleEQ) {
+		var err error
+		if settings, err = p.parseSettingsList(p.Pos()); err != nil {
+			return nil, err
+		}
+	}
+
+	stmt, err := p.parseStatement(p.Pos())
 	if err != nil {
 		return nil, err
 	}
 	return &ExplainStmt{
 		ExplainPos: pos,
 		Type:       explainType,
+		Settings:   settings,
 		Statement:  stmt,
 	}, nil
 }
@@ -30,10 +30,10 @@ func (p *Parser) parseDDL(pos Pos) (DDL, error) {
 		case p.matchKeyword(KeywordDatabase):
 			return p.parseCreateDatabase(pos)
 		case p.matchKeyword(KeywordDictionary):
-			return p.parseCreateDictionary(pos, orReplace)
+			return p.parseCreateDictionary(pos, orReplace, isAttach)
 		case p.matchKeyword(KeywordTable),
 			p.matchKeyword(KeywordTemporary):
-			return p.parseCreateTable(pos, orReplace)
+			return p.parseCreateTable(pos, orReplace, isAttach)
 		case p.matchKeyword(KeywordFunction):
 			return p.parseCreateFunction(pos, orReplace)
 		case p.matchKeyword(KeywordMaterialized):
@@ -50,6 +50,19 @@ func (p *Parser) parseDDL(pos Pos) (DDL, error) {
 			return nil, fmt.Errorf("expected keyword: NAMED|DATABASE|DICTIONARY|TABLE|VIEW|ROLE|USER|FUNCTION|MATERIALIZED, but got %q",
 				p.currentTokenKind())
 		}
+	case p.matchKeyword(KeywordReplace):
+		// REPLACE TABLE swaps in a new definition atomically and, unlike
+		// CREATE OR REPLACE, requires the table to exist
+		_ = p.lexer.consumeToken()
+		if !p.matchOneOfKeywords(KeywordTemporary, KeywordTable) {
+			return nil, fmt.Errorf("expected keyword: TEMPORARY|TABLE, but got %q", p.currentTokenString())
+		}
+		createTable, err := p.parseCreateTable(pos, false, false)
+		if err != nil {
+			return nil, err
+		}
+		createTable.IsReplace = true
+		return createTable, nil
 	case p.matchKeyword(KeywordAlter):
 		_ = p.lexer.consumeToken()
 		switch {
@@ -62,15 +75,16 @@ func (p *Parser) parseDDL(pos Pos) (DDL, error) {
 		}
 	case p.matchKeyword(KeywordDrop),
 		p.matchKeyword(KeywordDetach):
+		isDetach := p.matchKeyword(KeywordDetach)
 		_ = p.lexer.consumeToken()
 		switch {
 		case p.matchKeyword(KeywordDatabase):
-			return p.parseDropDatabase(pos)
+			return p.parseDropDatabase(pos, isDetach)
 		case p.matchKeyword(KeywordTemporary),
 			p.matchKeyword(KeywordView),
 			p.matchKeyword(KeywordDictionary),
 			p.matchKeyword(KeywordTable):
-			return p.parseDropStmt(pos)
+			return p.parseDropStmt(pos, isDetach)
 		case p.matchKeyword(KeywordUser),
 			p.matchKeyword(KeywordRole):
 			return p.parserDropUserOrRole(pos)
@@ -130,13 +144,14 @@ func (p *Parser) parseCreateDatabase(pos Pos) (*CreateDatabase, error) {
 	}, nil
 }
 
-func (p *Parser) parseCreateDictionary(pos Pos, orReplace bool) (*CreateDictionary, error) {
+func (p *Parser) parseCreateDictionary(pos Pos, orReplace, isAttach bool) (*CreateDictionary, error) {
 	if err := p.expectKeyword(KeywordDictionary); err != nil {
 		return nil, err
 	}
 
 	createDict := &CreateDictionary{
 		CreatePos: pos,
+		IsAttach:  isAttach,
 		OrReplace: orReplace,
 	}
 
@@ -153,6 +168,7 @@ func (p *Parser) parseCreateDictionary(pos Pos, orReplace bool) (*CreateDictiona
 		return nil, err
 	}
 	createDict.Name = name
+	createDict.StatementEnd = name.End()
 
 	// try parse UUID clause if exists
 	uuid, err := p.tryParseUUID()
@@ -167,6 +183,15 @@ func (p *Parser) parseCreateDictionary(pos Pos, orReplace bool) (*CreateDictiona
 		return nil, err
 	}
 	createDict.OnCluster = onCluster
+	if onCluster != nil {
+		createDict.StatementEnd = onCluster.End()
+	}
+
+	// ATTACH DICTIONARY name re-attaches a detached dictionary from its
+	// stored definition
+	if isAttach && p.isEndOfStatement() {
+		return createDict, nil
+	}
 
 	// parse dictionary schema clause (required)
 	schema, err := p.parseDictionarySchemaClause(p.Pos())
@@ -316,8 +341,8 @@ func (p *Parser) parseNamedCollectionParam(pos Pos) (*NamedCollectionParam, erro
 	return param, nil
 }
 
-func (p *Parser) parseCreateTable(pos Pos, orReplace bool) (*CreateTable, error) {
-	createTable := &CreateTable{CreatePos: pos, OrReplace: orReplace}
+func (p *Parser) parseCreateTable(pos Pos, orReplace, isAttach bool) (*CreateTable, error) {
+	createTable := &CreateTable{CreatePos: pos, IsAttach: isAttach, OrReplace: orReplace}
 	createTable.HasTemporary = p.tryConsumeKeywords(KeywordTemporary)
 
 	if err := p.expectKeyword(KeywordTable); err != nil {
@@ -336,6 +361,17 @@ func (p *Parser) parseCreateTable(pos Pos, orReplace bool) (*CreateTable, error)
 		return nil, err
 	}
 	createTable.Name = tableIdentifier
+	createTable.StatementEnd = tableIdentifier.End()
+
+	// ATTACH TABLE ... FROM 'path' attaches data files from the given
+	// directory instead of the table's own
+	if isAttach && p.tryConsumeKeywords(KeywordFrom) {
+		createTable.FromPath, err = p.parseString(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		createTable.StatementEnd = createTable.FromPath.End()
+	}
 
 	// try parse UUID clause if exists
 	uuid, err := p.tryParseUUID()
@@ -350,11 +386,44 @@ func (p *Parser) parseCreateTable(pos Pos, orReplace bool) (*CreateTable, error)
 	}
 	createTable.OnCluster = onCluster
 
-	tableSchema, err := p.parseTableSchemaClause(p.Pos())
-	if err != nil {
-		return nil, err
+	switch {
+	case p.matchKeyword(KeywordClone):
+		// CLONE AS copies both the structure and the data of the source
+		_ = p.lexer.consumeToken()
+		if err := p.expectKeyword(KeywordAs); err != nil {
+			return nil, err
+		}
+		createTable.HasClone = true
+		createTable.SourceTable, err = p.parseTableIdentifier(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		createTable.StatementEnd = createTable.SourceTable.End()
+	case p.matchKeyword(KeywordAs) && !p.peekKeyword(KeywordSelect) &&
+		!p.peekKeyword(KeywordWith) && !p.peekTokenKind(TokenKindLParen):
+		// AS [db.]table copies the structure of another table; AS SELECT
+		// is handled after the engine below
+		_ = p.lexer.consumeToken()
+		if p.peekTokenKind(TokenKindLParen) {
+			createTable.TableFunction, err = p.parseCreateTableFunction()
+			if err != nil {
+				return nil, err
+			}
+			createTable.StatementEnd = createTable.TableFunction.End()
+			break
+		}
+		createTable.SourceTable, err = p.parseTableIdentifier(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		createTable.StatementEnd = createTable.SourceTable.End()
+	default:
+		tableSchema, err := p.parseTableSchemaClause(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		createTable.TableSchema = tableSchema
 	}
-	createTable.TableSchema = tableSchema
 
 	engineExpr, err := p.tryParseEngineExpr(p.Pos())
 	if err != nil {
@@ -365,7 +434,20 @@ func (p *Parser) parseCreateTable(pos Pos, orReplace bool) (*CreateTable, error)
 		createTable.StatementEnd = engineExpr.End()
 	}
 
-	if p.tryConsumeKeywords(KeywordAs) {
+	if p.matchKeyword(KeywordEmpty) {
+		// EMPTY AS SELECT takes the structure of the query but inserts no rows
+		_ = p.lexer.consumeToken()
+		createTable.HasEmpty = true
+		if err := p.expectKeyword(KeywordAs); err != nil {
+			return nil, err
+		}
+		subQuery, err := p.parseSubQuery(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		createTable.SubQuery = subQuery
+		createTable.StatementEnd = subQuery.End()
+	} else if createTable.TableFunction == nil && p.tryConsumeKeywords(KeywordAs) {
 		// After AS, we can have: SELECT/WITH (with or without parens), or table_function(...)
 		// Check if it's a SELECT/WITH query (explicitly check keywords/paren before ident)
 		if p.matchKeyword(KeywordSelect) || p.matchKeyword(KeywordWith) || p.matchTokenKind(TokenKindLParen) {
@@ -378,24 +460,12 @@ func (p *Parser) parseCreateTable(pos Pos, orReplace bool) (*CreateTable, error)
 			createTable.StatementEnd = subQuery.End()
 		} else if p.matchTokenKind(TokenKindIdent) {
 			// It's a table function: remote(...), remoteSecure(...), etc.
-			ident, err := p.parseIdent()
+			tableFunction, err := p.parseCreateTableFunction()
 			if err != nil {
 				return nil, err
 			}
-			if p.matchTokenKind(TokenKindLParen) {
-				argsExpr, err := p.parseTableArgList(p.Pos())
-				if err != nil {
-					return nil, err
-				}
-				tableFunc := &TableFunctionExpr{
-					Name: ident,
-					Args: argsExpr,
-				}
-				createTable.TableFunction = tableFunc
-				createTable.StatementEnd = tableFunc.End()
-			} else {
-				return nil, fmt.Errorf("expected ( after identifier in AS clause, got %q", p.currentTokenKind())
-			}
+			createTable.TableFunction = tableFunction
+			createTable.StatementEnd = tableFunction.End()
 		} else {
 			return nil, fmt.Errorf("expected SELECT, WITH or identifier after AS, got %q", p.currentTokenKind())
 		}
@@ -409,6 +479,26 @@ func (p *Parser) parseCreateTable(pos Pos, orReplace bool) (*CreateTable, error)
 	return createTable, nil
 }
 
+// parseCreateTableFunction parses the table function after CREATE TABLE ... AS,
+// e.g. remote('host', 'db', 'table').
+func (p *Parser) parseCreateTableFunction() (*TableFunctionExpr, error) {
+	ident, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	if !p.matchTokenKind(TokenKindLParen) {
+		return nil, fmt.Errorf("expected ( after identifier in AS clause, got %q", p.currentTokenKind())
+	}
+	argsExpr, err := p.parseTableArgList(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	return &TableFunctionExpr{
+		Name: ident,
+		Args: argsExpr,
+	}, nil
+}
+
 func (p *Parser) parseIdentOrFunction(_ Pos) (Expr, error) {
 	var ident *Ident
 	var err error
@@ -689,7 +779,7 @@ func (p *Parser) parseTableColumnExpr(pos Pos) (*ColumnDef, error) {
 	column.Name = name
 	columnEnd := name.End()
 
-	if p.matchTokenKind(TokenKindIdent) && !p.matchKeyword(KeywordRemove) {
+	if p.matchTokenKind(TokenKindIdent) && !p.matchOneOfKeywords(KeywordRemove, KeywordEphemeral) {
 		columnType, err := p.parseColumnType(p.Pos())
 		if err != nil {
 			return nil, err
@@ -721,6 +811,15 @@ func (p *Parser) parseTableColumnExpr(pos Pos) (*ColumnDef, error) {
 	case p.tryConsumeKeywords(KeywordAlias):
 		column.AliasExpr, err = p.parseExpr(p.Pos())
 		valueExpr = column.AliasExpr
+	case p.matchKeyword(KeywordEphemeral):
+		// the default value of an EPHEMERAL column is optional
+		column.Ephemeral = true
+		columnEnd = p.End()
+		_ = p.lexer.consumeToken()
+		if !p.isColumnDefOptionEnd() {
+			column.EphemeralExpr, err = p.parseExpr(p.Pos())
+			valueExpr = column.EphemeralExpr
+		}
 	}
 	if err != nil {
 		return nil, err
@@ -744,6 +843,15 @@ func (p *Parser) parseTableColumnExpr(pos Pos) (*ColumnDef, error) {
 	if codec != nil {
 		columnEnd = codec.End()
 	}
+	statistics, err := p.tryParseStatisticsClause(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	if statistics != nil {
+		columnEnd = statistics.End()
+	}
+	column.Statistics = statistics
+
 	ttl, err := p.tryParseTTLClause(p.Pos(), false)
 	if err != nil {
 		return nil, err
@@ -753,6 +861,33 @@ func (p *Parser) parseTableColumnExpr(pos Pos) (*ColumnDef, error) {
 	}
 	column.TTL = ttl
 
+	if p.matchKeyword(KeywordPrimary) {
+		column.PrimaryKey = true
+		_ = p.lexer.consumeToken()
+		columnEnd = p.End()
+		if err := p.expectKeyword(KeywordKey); err != nil {
+			return nil, err
+		}
+	}
+
+	// Column SETTINGS always carries a parenthesized list, which tells it
+	// apart from a statement-level SETTINGS clause after ALTER ... ADD COLUMN.
+	if p.matchKeyword(KeywordSettings) && p.peekTokenKind(TokenKindLParen) {
+		settingsPos := p.Pos()
+		_ = p.lexer.consumeToken()
+		_ = p.lexer.consumeToken()
+		settings, err := p.parseSettingsClause(settingsPos)
+		if err != nil {
+			return nil, err
+		}
+		settings.ListEnd = p.End()
+		if err := p.expectTokenKind(TokenKindRParen); err != nil {
+			return nil, err
+		}
+		column.Settings = settings
+		columnEnd = settings.End()
+	}
+
 	column.ColumnEnd = columnEnd
 	column.Comment = comment
 	column.Codec = codec
@@ -818,6 +953,9 @@ func (p *Parser) parseTableArgPrimaryExpr(pos Pos) (Expr, error) {
 	case p.matchTokenKind(TokenKindInt), p.matchTokenKind(TokenKindFloat),
 		p.matchTokenKind(TokenKindString), p.matchKeyword(KeywordNull):
 		return p.parseLiteral(p.Pos())
+	case p.matchTokenKind(TokenKindQuestionMark):
+		// placeholder `?`, e.g. numbers(?)
+		return p.parseColumnExpr(pos)
 	default:
 		return nil, fmt.Errorf("unexpected token: %q, expected <Name>, <literal>", p.currentTokenString())
 	}
@@ -1606,11 +1744,31 @@ func (p *Parser) parseEngineExpr(pos Pos) (*EngineExpr, error) {
 }
 
 func (p *Parser) parseStmt(pos Pos) (Expr, error) {
+	expr, err := p.parseStatement(pos)
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.tryParseFormat(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+
+	// Statement can be terminated by ';' or EOF
+	if p.current() != nil && !p.matchTokenKind(";") {
+		return nil, fmt.Errorf("<EOF> or ';' was expected, but got: %q", p.currentTokenString())
+	}
+	return expr, nil
+}
+
+// parseStatement parses a single statement of any kind, without the trailing
+// FORMAT clause or terminator, so that EXPLAIN can wrap any statement.
+func (p *Parser) parseStatement(pos Pos) (Expr, error) {
 	var err error
 	var expr Expr
 	switch {
 	case p.matchKeyword(KeywordCreate),
 		p.matchKeyword(KeywordAttach),
+		p.matchKeyword(KeywordReplace),
 		p.matchKeyword(KeywordAlter),
 		p.matchKeyword(KeywordDrop),
 		p.matchKeyword(KeywordDetach),
@@ -1652,15 +1810,6 @@ func (p *Parser) parseStmt(pos Pos) (Expr, error) {
 	if err != nil {
 		return nil, err
 	}
-	_, err = p.tryParseFormat(p.Pos())
-	if err != nil {
-		return nil, err
-	}
-
-	// Statement can be terminated by ';' or EOF
-	if p.current() != nil && !p.matchTokenKind(";") {
-		return nil, fmt.Errorf("<EOF> or ';' was expected, but got: %q", p.currentTokenString())
-	}
 	return expr, nil
 }
 
@@ -1843,17 +1992,38 @@ func (p *Parser) parseDescribeStmt(pos Pos) (*DescribeStmt, error) {
 		describeType = "TABLE"
 	}
 
-	tableIdent, err := p.parseTableIdentifier(p.Pos())
+	stmt := &DescribeStmt{
+		DescribePos:  pos,
+		DescribeType: describeType,
+	}
+	if lparen := p.tryConsumeTokenKind(TokenKindLParen); lparen != nil {
+		selectQuery, err := p.parseSelectQuery(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		stmt.StatementEnd = p.End()
+		if err := p.expectTokenKind(TokenKindRParen); err != nil {
+			return nil, err
+		}
+		stmt.Target = &SubQuery{HasParen: true, Select: selectQuery}
+	} else {
+		target, err := p.parseTableIdentifierOrFunction(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		stmt.Target = target
+		stmt.StatementEnd = target.End()
+	}
+
+	settings, err := p.tryParseSettingsClause(p.Pos())
 	if err != nil {
 		return nil, err
 	}
-
-	return &DescribeStmt{
-		DescribePos:  pos,
-		StatementEnd: tableIdent.End(),
-		DescribeType: describeType,
-		Target:       tableIdent,
-	}, nil
+	if settings != nil {
+		stmt.Settings = settings
+		stmt.StatementEnd = settings.End()
+	}
+	return stmt, nil
 }
 
 // syntax: TRUNCATE TEMPORARY? TABLE (IF EXISTS)? tableIdentifier clusterClause?;
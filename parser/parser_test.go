@@ -192,6 +192,23 @@ func TestParser_InvalidSyntax(t *testing.T) {
 		"CREATE TABLE t (x String DEFAULT CAST(a +, 'String'))",
 		"CREATE TABLE t (x String MATERIALIZED a +)",
 		"CREATE TABLE t (x String ALIAS a +)",
+		// Column STATISTICS takes a parenthesized list, and an inline
+		// PRIMARY needs its KEY
+		"CREATE TABLE t (x String STATISTICS tdigest) ENGINE = Memory",
+		"CREATE TABLE t (x String STATISTICS()) ENGINE = Memory",
+		"CREATE TABLE t (x UInt64 PRIMARY) ENGINE = Memory",
+		"CREATE TABLE t (x UInt64 SETTINGS (max_compress_block_size = 1) ENGINE = Memory",
+		// CLONE and EMPTY are only valid before AS
+		"CREATE TABLE t CLONE db.src",
+		"CREATE TABLE t ENGINE = Memory EMPTY SELECT 1",
+		// PERMANENTLY only applies to DETACH, FROM 'path' only to ATTACH,
+		// and standalone REPLACE only to tables
+		"DROP TABLE t PERMANENTLY",
+		"DROP DATABASE d PERMANENTLY",
+		"CREATE TABLE t FROM '/path' (a UInt64) ENGINE = Memory",
+		"REPLACE VIEW v AS SELECT 1",
+		// a full dictionary definition still needs its schema
+		"ATTACH DICTIONARY d ON CLUSTER c PRIMARY KEY id",
 		// A TTL GROUP BY action only accepts a plain expression list; the
 		// query-level modifiers are syntax errors for ClickHouse in a TTL.
 		// (GROUP BY ALL and CUBE/ROLLUP(...) read as ordinary key
@@ -267,6 +284,18 @@ func TestParser_InvalidSyntax(t *testing.T) {
 		// ClickHouse rejects a set operator once SETTINGS is bound to a
 		// parenthesized group
 		"(SELECT 1) SETTINGS max_threads=1 UNION ALL SELECT 2",
+		// WITH TIES needs ORDER BY and cannot be combined with LIMIT BY; FETCH
+		// needs FIRST or NEXT, ROWS, and ONLY or WITH TIES
+		"SELECT a FROM t LIMIT 5 WITH TIES",
+		"SELECT a FROM t ORDER BY a LIMIT 1 WITH TIES BY a",
+		"SELECT a FROM t ORDER BY a OFFSET 1 ROWS FETCH 5 ROWS ONLY",
+		"SELECT a FROM t ORDER BY a OFFSET 1 ROWS FETCH FIRST 5 ONLY",
+		"SELECT a FROM t ORDER BY a OFFSET 1 ROWS FETCH FIRST 5 ROWS",
+		// INTO OUTFILE takes a string and each option at most once
+		"SELECT a FROM t INTO OUTFILE out",
+		"SELECT a FROM t INTO OUTFILE 'f' APPEND TRUNCATE",
+		"SELECT a FROM t INTO OUTFILE 'f' COMPRESSION 'gzip' COMPRESSION 'zstd'",
+		"SELECT a FROM t INTO OUTFILE 'f' COMPRESSION 'gzip' LEVEL",
 	}
 	for _, sql := range invalidSQLs {
 		parser := NewParser(sql)
@@ -322,3 +351,79 @@ func TestParser_ParenthesizedSetOperationOperands(t *testing.T) {
 	require.NotNil(t, group.Settings)
 	require.NotNil(t, group.Format)
 }
+
+func TestParser_DescribeTargets(t *testing.T) {
+	tests := []struct {
+		sql    string
+		target Expr
+	}{
+		{"DESCRIBE events", &TableIdentifier{}},
+		{"DESC TABLE s3('https://bucket/*.parquet', 'Parquet')", &TableFunctionExpr{}},
+		{"DESCRIBE (SELECT 1 AS a)", &SubQuery{}},
+	}
+	for _, tt := range tests {
+		stmts, err := NewParser(tt.sql).ParseStmts()
+		require.NoError(t, err, tt.sql)
+		describe, ok := stmts[0].(*DescribeStmt)
+		require.True(t, ok, "expected *DescribeStmt, got %T", stmts[0])
+		require.IsType(t, tt.target, describe.Target, tt.sql)
+		require.Nil(t, describe.Settings, tt.sql)
+	}
+
+	sql := "DESCRIBE file('a.json') SETTINGS describe_include_subcolumns = 1"
+	stmts, err := NewParser(sql).ParseStmts()
+	require.NoError(t, err)
+	describe := stmts[0].(*DescribeStmt)
+	require.NotNil(t, describe.Settings)
+	require.Len(t, describe.Settings.Items, 1)
+	require.Equal(t, Pos(len(sql)), describe.End())
+}
+
+func TestParser_ExplainAnyStatement(t *testing.T) {
+	tests := []struct {
+		sql         string
+		explainType string
+		settings    int
+		statement   Expr
+	}{
+		{"EXPLAIN SELECT 1", "", 0, &SelectQuery{}},
+		{"EXPLAIN PLAN header = 1, actions = 1 SELECT 1", "PLAN", 2, &SelectQuery{}},
+		{"EXPLAIN QUERY TREE SELECT 1", "QUERY TREE", 0, &SelectQuery{}},
+		{"EXPLAIN indexes = 1 SELECT * FROM t", "", 1, &SelectQuery{}},
+		{"EXPLAIN AST INSERT INTO t VALUES (1)", "AST", 0, &InsertStmt{}},
+		{"EXPLAIN SYNTAX DESCRIBE (SELECT 1)", "SYNTAX", 0, &DescribeStmt{}},
+	}
+	for _, tt := range tests {
+		stmts, err := NewParser(tt.sql).ParseStmts()
+		require.NoError(t, err, tt.sql)
+		explain, ok := stmts[0].(*ExplainStmt)
+		require.True(t, ok, "expected *ExplainStmt, got %T", stmts[0])
+		require.Equal(t, tt.explainType, explain.Type, tt.sql)
+		require.Len(t, explain.Settings, tt.settings, tt.sql)
+		require.IsType(t, tt.statement, explain.Statement, tt.sql)
+	}
+}
+
+func TestParser_CreateUserClauseEnds(t *testing.T) {
+	// Clauses ending in a keyword used to take their end from the token after
+	// it, overshooting into the next token and panicking at end of input.
+	for _, sql := range []string{
+		"CREATE USER u NOT IDENTIFIED",
+		"CREATE USER u IDENTIFIED WITH kerberos",
+		"CREATE USER u IDENTIFIED WITH no_password",
+		"CREATE USER u HOST ANY",
+		"CREATE USER u DEFAULT ROLE NONE",
+		"CREATE USER u DEFAULT DATABASE NONE",
+		"CREATE USER u GRANTEES ANY",
+		"CREATE USER u GRANTEES NONE",
+	} {
+		stmts, err := NewParser(sql).ParseStmts()
+		require.NoError(t, err, sql)
+		require.Len(t, stmts, 1, sql)
+		require.Equal(t, Pos(len(sql)), stmts[0].End(), sql)
+
+		stmts, err = NewParser(sql + ";").ParseStmts()
+		require.NoError(t, err, sql)
+		require.Equal(t, Pos(len(sql)), stmts[0].End(), sql)
+	}
+}
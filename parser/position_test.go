@@ -45,6 +45,20 @@ func TestGroupByClauseEnd(t *testing.T) {
 	require.Equal(t, Pos(len(sql)), stmt.GroupBy.End())
 }
 
+func TestLimitClauseEnd(t *testing.T) {
+	for _, sql := range []string{
+		"SELECT a FROM t ORDER BY a LIMIT 5",
+		"SELECT a FROM t ORDER BY a LIMIT 5 WITH TIES",
+		"SELECT a FROM t ORDER BY a OFFSET 10 ROWS",
+		"SELECT a FROM t ORDER BY a OFFSET 10 ROWS FETCH NEXT 5 ROWS ONLY",
+	} {
+		stmt := parseOneStmt(t, sql).(*SelectQuery)
+		require.NotNil(t, stmt.Limit, sql)
+		require.Equal(t, Pos(len(sql)), stmt.Limit.End(), sql)
+		require.Equal(t, Pos(len(sql)), stmt.End(), sql)
+	}
+}
+
 func TestIsNullExprPositions(t *testing.T) {
 	sql := "SELECT a IS NULL"
 	stmt := parseOneStmt(t, sql).(*SelectQuery)
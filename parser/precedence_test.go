@@ -3,6 +3,7 @@ package parser
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -27,24 +28,49 @@ func TestLambdaBodyBindsLoosest(t *testing.T) {
 	require.True(t, ok)
 	firstArg, ok := fn.Params.Items.Items[0].(*ColumnExpr)
 	require.True(t, ok)
-	lambda, ok := firstArg.Expr.(*BinaryOperation)
-	require.True(t, ok)
-	require.Equal(t, TokenKindArrow, lambda.Operation)
-	body, ok := lambda.RightExpr.(*BinaryOperation)
-	require.True(t, ok, "lambda body should be the binary operation `x + 1`, got %T", lambda.RightExpr)
+	lambda, ok := firstArg.Expr.(*LambdaExpr)
+	require.True(t, ok, "expected *LambdaExpr, got %T", firstArg.Expr)
+	require.Len(t, lambda.Params, 1)
+	require.Equal(t, "x", lambda.Params[0].Name)
+	body, ok := lambda.Body.(*BinaryOperation)
+	require.True(t, ok, "lambda body should be the binary operation `x + 1`, got %T", lambda.Body)
 	require.Equal(t, TokenKind("+"), body.Operation)
 }
 
 func TestLambdaIsRightAssociative(t *testing.T) {
 	expr := parseSelectItemExpr(t, "SELECT x -> y -> x + y")
-	outer, ok := expr.(*BinaryOperation)
+	outer, ok := expr.(*LambdaExpr)
+	require.True(t, ok, "expected *LambdaExpr, got %T", expr)
+	require.Len(t, outer.Params, 1)
+	require.Equal(t, "x", outer.Params[0].Name)
+	inner, ok := outer.Body.(*LambdaExpr)
+	require.True(t, ok, "outer lambda body should be the lambda `y -> x + y`, got %T", outer.Body)
+	require.Equal(t, "y", inner.Params[0].Name)
+}
+
+func TestLambdaParamList(t *testing.T) {
+	expr := parseSelectItemExpr(t, "SELECT arrayFilter((k, v) -> v > 0, ks, vs)")
+	fn, ok := expr.(*FunctionExpr)
 	require.True(t, ok)
-	require.Equal(t, TokenKindArrow, outer.Operation)
-	_, ok = outer.LeftExpr.(*Ident)
-	require.True(t, ok, "outer lambda parameter should be `x`, got %T", outer.LeftExpr)
-	inner, ok := outer.RightExpr.(*BinaryOperation)
+	firstArg, ok := fn.Params.Items.Items[0].(*ColumnExpr)
 	require.True(t, ok)
-	require.Equal(t, TokenKindArrow, inner.Operation)
+	lambda, ok := firstArg.Expr.(*LambdaExpr)
+	require.True(t, ok, "expected *LambdaExpr, got %T", firstArg.Expr)
+	require.Len(t, lambda.Params, 2)
+	require.Equal(t, "k", lambda.Params[0].Name)
+	require.Equal(t, "v", lambda.Params[1].Name)
+	require.Equal(t, "(k, v) -> v > 0", Format(lambda))
+}
+
+func TestLambdaInvalidParams(t *testing.T) {
+	for _, sql := range []string{
+		"SELECT arrayMap(1 -> 1, arr)",
+		"SELECT arrayMap((x, 1) -> x, arr)",
+		"SELECT arrayMap(f(x) -> x, arr)",
+	} {
+		_, err := NewParser(sql).ParseStmts()
+		require.Error(t, err, sql)
+	}
 }
 
 func TestNotBindsLooserThanComparison(t *testing.T) {
@@ -332,17 +358,19 @@ func TestIntersect(t *testing.T) {
 }
 
 func TestGlobalJoinLocalityPrefixesTheJoinType(t *testing.T) {
-	// the locality prefixes the join type, and both have to reach the modifiers
+	// the locality prefixes the join type, and both have to reach the join
 	for _, tc := range []struct {
-		sql       string
-		modifiers []string
+		sql        string
+		locality   JoinLocality
+		strictness JoinStrictness
+		kind       JoinKind
 	}{
-		{"SELECT * FROM t1 GLOBAL JOIN t2 ON t1.a = t2.a", []string{"GLOBAL", "JOIN"}},
-		{"SELECT * FROM t1 GLOBAL INNER JOIN t2 ON t1.a = t2.a", []string{"GLOBAL", "INNER", "JOIN"}},
-		{"SELECT * FROM t1 GLOBAL LEFT OUTER JOIN t2 ON t1.a = t2.a", []string{"GLOBAL", "LEFT", "OUTER", "JOIN"}},
-		{"SELECT * FROM t1 GLOBAL ANY LEFT JOIN t2 ON t1.a = t2.a", []string{"GLOBAL", "ANY", "LEFT", "JOIN"}},
-		{"SELECT * FROM t1 GLOBAL CROSS JOIN t2", []string{"GLOBAL", "CROSS", "JOIN"}},
-		{"SELECT * FROM t1 AS x LOCAL FULL JOIN t2 USING a", []string{"LOCAL", "FULL", "JOIN"}},
+		{"SELECT * FROM t1 GLOBAL JOIN t2 ON t1.a = t2.a", JoinLocalityGlobal, JoinStrictnessNone, JoinKindInner},
+		{"SELECT * FROM t1 GLOBAL INNER JOIN t2 ON t1.a = t2.a", JoinLocalityGlobal, JoinStrictnessNone, JoinKindInner},
+		{"SELECT * FROM t1 GLOBAL LEFT OUTER JOIN t2 ON t1.a = t2.a", JoinLocalityGlobal, JoinStrictnessNone, JoinKindLeft},
+		{"SELECT * FROM t1 GLOBAL ANY LEFT JOIN t2 ON t1.a = t2.a", JoinLocalityGlobal, JoinStrictnessAny, JoinKindLeft},
+		{"SELECT * FROM t1 GLOBAL CROSS JOIN t2", JoinLocalityGlobal, JoinStrictnessNone, JoinKindCross},
+		{"SELECT * FROM t1 AS x LOCAL FULL JOIN t2 USING a", JoinLocalityLocal, JoinStrictnessNone, JoinKindFull},
 	} {
 		from := parseOneStmt(t, tc.sql).(*SelectQuery).From.Expr
 		join, ok := from.(*JoinExpr)
@@ -350,11 +378,104 @@ func TestGlobalJoinLocalityPrefixesTheJoinType(t *testing.T) {
 
 		right, ok := join.Right.(*JoinExpr)
 		require.True(t, ok, "%s: expected the join to carry a right side, got %T", tc.sql, join.Right)
-		require.Equal(t, tc.modifiers, right.Modifiers, tc.sql)
+		require.Equal(t, tc.locality, right.Locality, tc.sql)
+		require.Equal(t, tc.strictness, right.Strictness, tc.sql)
+		require.Equal(t, tc.kind, right.Kind, tc.sql)
 		require.Equal(t, tc.sql, Format(parseOneStmt(t, tc.sql)), tc.sql)
 	}
 }
 
+func TestJoinOperators(t *testing.T) {
+	for _, tc := range []struct {
+		sql        string
+		strictness JoinStrictness
+		kind       JoinKind
+		formatted  string
+	}{
+		{"SELECT * FROM t1 LEFT ANY JOIN t2 ON t1.a = t2.a", JoinStrictnessAny, JoinKindLeft,
+			"SELECT * FROM t1 ANY LEFT JOIN t2 ON t1.a = t2.a"},
+		{"SELECT * FROM t1 left semi join t2 USING a", JoinStrictnessSemi, JoinKindLeft,
+			"SELECT * FROM t1 SEMI LEFT JOIN t2 USING a"},
+		{"SELECT * FROM t1 RIGHT ANTI JOIN t2 USING a", JoinStrictnessAnti, JoinKindRight,
+			"SELECT * FROM t1 ANTI RIGHT JOIN t2 USING a"},
+		{"SELECT * FROM t1 ASOF JOIN t2 ON t1.a = t2.a AND t1.t >= t2.t", JoinStrictnessAsof, JoinKindInner, ""},
+		{"SELECT * FROM t1 ASOF LEFT JOIN t2 USING a, t", JoinStrictnessAsof, JoinKindLeft, ""},
+		{"SELECT * FROM t1 ALL FULL OUTER JOIN t2 USING a", JoinStrictnessAll, JoinKindFull, ""},
+		{"SELECT * FROM t1 PASTE JOIN t2", JoinStrictnessNone, JoinKindPaste, ""},
+		{"SELECT * FROM t1 AS a PASTE JOIN t2 AS b", JoinStrictnessNone, JoinKindPaste, ""},
+		{"SELECT * FROM t ARRAY JOIN arr", JoinStrictnessNone, JoinKindArray, ""},
+		{"SELECT * FROM t INNER ARRAY JOIN arr", JoinStrictnessNone, JoinKindArray,
+			"SELECT * FROM t ARRAY JOIN arr"},
+		{"SELECT * FROM t LEFT ARRAY JOIN arr AS a", JoinStrictnessNone, JoinKindLeftArray, ""},
+	} {
+		stmt := parseOneStmt(t, tc.sql)
+		join, ok := stmt.(*SelectQuery).From.Expr.(*JoinExpr)
+		require.True(t, ok, tc.sql)
+		right, ok := join.Right.(*JoinExpr)
+		require.True(t, ok, tc.sql)
+		require.Equal(t, tc.strictness, right.Strictness, tc.sql)
+		require.Equal(t, tc.kind, right.Kind, tc.sql)
+		formatted := tc.formatted
+		if formatted == "" {
+			formatted = tc.sql
+		}
+		require.Equal(t, formatted, Format(stmt), tc.sql)
+	}
+
+	// a table may still be called paste
+	stmt := parseOneStmt(t, "SELECT * FROM paste JOIN t ON paste.a = t.a")
+	require.Equal(t, JoinKindInner, stmt.(*SelectQuery).From.Expr.(*JoinExpr).Right.(*JoinExpr).Kind)
+}
+
+func TestJoinKeepsTheKindAsWritten(t *testing.T) {
+	for _, tc := range []struct {
+		sql          string
+		implicitKind bool
+		hasOuter     bool
+	}{
+		{"SELECT * FROM t1 JOIN t2 ON t1.a = t2.a", true, false},
+		{"SELECT * FROM t1 ANY JOIN t2 ON t1.a = t2.a", true, false},
+		{"SELECT * FROM t1 INNER JOIN t2 ON t1.a = t2.a", false, false},
+		{"SELECT * FROM t1 LEFT OUTER JOIN t2 ON t1.a = t2.a", false, true},
+		{"SELECT * FROM t1 RIGHT OUTER JOIN t2 USING a", false, true},
+		{"SELECT * FROM t1 FULL OUTER JOIN t2 USING a", false, true},
+	} {
+		stmt := parseOneStmt(t, tc.sql)
+		right := stmt.(*SelectQuery).From.Expr.(*JoinExpr).Right.(*JoinExpr)
+		require.Equal(t, tc.implicitKind, right.ImplicitKind, tc.sql)
+		require.Equal(t, tc.hasOuter, right.HasOuter, tc.sql)
+		require.Equal(t, tc.sql, Format(stmt), tc.sql)
+	}
+
+	// a join built by hand spells out its kind
+	stmt := parseOneStmt(t, "SELECT * FROM t1 JOIN t2 ON t1.a = t2.a")
+	stmt.(*SelectQuery).From.Expr.(*JoinExpr).Right.(*JoinExpr).ImplicitKind = false
+	require.Equal(t, "SELECT * FROM t1 INNER JOIN t2 ON t1.a = t2.a", Format(stmt))
+}
+
+func TestInvalidJoinOperators(t *testing.T) {
+	for _, tc := range []struct {
+		sql string
+		msg string
+	}{
+		{"SELECT * FROM t1 ASOF FULL JOIN t2 USING a", "ASOF cannot be combined with FULL JOIN"},
+		{"SELECT * FROM t1 ASOF RIGHT JOIN t2 USING a", "ASOF cannot be combined with RIGHT JOIN"},
+		{"SELECT * FROM t1 SEMI JOIN t2 USING a", "SEMI JOIN must be LEFT or RIGHT"},
+		{"SELECT * FROM t1 ANTI FULL JOIN t2 USING a", "ANTI JOIN must be LEFT or RIGHT"},
+		{"SELECT * FROM t1 ANY CROSS JOIN t2", "ANY cannot be combined with CROSS JOIN"},
+		{"SELECT * FROM t1 CROSS JOIN t2 ON t1.a = t2.a", "CROSS JOIN cannot have an ON or USING clause"},
+		{"SELECT * FROM t1 PASTE JOIN t2 USING a", "PASTE JOIN cannot have an ON or USING clause"},
+		{"SELECT * FROM t1 INNER OUTER JOIN t2 USING a", "OUTER must follow LEFT, RIGHT or FULL"},
+		{"SELECT * FROM t1 ANY ALL JOIN t2 USING a", "unexpected ALL after ANY"},
+		{"SELECT * FROM t1 LEFT RIGHT JOIN t2 USING a", "unexpected RIGHT after LEFT"},
+		{"SELECT * FROM t ANY ARRAY JOIN arr", "ARRAY JOIN can only be preceded by LEFT or INNER"},
+		{"SELECT * FROM t GLOBAL ARRAY JOIN arr", "GLOBAL cannot be combined with ARRAY JOIN"},
+	} {
+		_, err := NewParser(tc.sql).ParseStmts()
+		require.ErrorContains(t, err, tc.msg, tc.sql)
+	}
+}
+
 func TestLocalityWithoutAJoinKeepsItsKeyword(t *testing.T) {
 	// a GLOBAL with no join used to be consumed and dropped from Format(); the
 	// error now has to name it rather than the end of the statement
@@ -375,11 +496,13 @@ func TestGlobalAfterJoinConstraintStartsANewJoin(t *testing.T) {
 
 	first, ok := join.Right.(*JoinExpr)
 	require.True(t, ok)
-	require.Equal(t, []string{"GLOBAL", "JOIN"}, first.Modifiers)
+	require.Equal(t, JoinLocalityGlobal, first.Locality)
+	require.Equal(t, JoinKindInner, first.Kind)
 
 	second, ok := first.Right.(*JoinExpr)
 	require.True(t, ok, "the second GLOBAL should start another join, got %T", first.Right)
-	require.Equal(t, []string{"GLOBAL", "LEFT", "JOIN"}, second.Modifiers)
+	require.Equal(t, JoinLocalityGlobal, second.Locality)
+	require.Equal(t, JoinKindLeft, second.Kind)
 	require.Equal(t, sql, Format(stmt))
 }
 
@@ -542,3 +665,124 @@ func TestRepeatedIntervalColumnsParseInPolynomialTime(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, stmts, 1)
 }
+
+func TestTypedLiterals(t *testing.T) {
+	expr := parseSelectItemExpr(t, "SELECT DATE '2024-02-29'")
+	date, ok := expr.(*TypedLiteral)
+	require.True(t, ok, "expected *TypedLiteral, got %T", expr)
+	value, err := date.Time()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), value)
+
+	expr = parseSelectItemExpr(t, "SELECT TIMESTAMP '2024-01-01 12:30:00.25' + 1")
+	sum, ok := expr.(*BinaryOperation)
+	require.True(t, ok, "expected *BinaryOperation, got %T", expr)
+	timestamp, ok := sum.LeftExpr.(*TypedLiteral)
+	require.True(t, ok, "expected *TypedLiteral, got %T", sum.LeftExpr)
+	value, err = timestamp.Time()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 1, 1, 12, 30, 0, 250_000_000, time.UTC), value)
+
+	_, err = parseSelectItemExpr(t, "SELECT DATE '2024-13-01'").(*TypedLiteral).Time()
+	require.Error(t, err)
+}
+
+func TestStringIntervals(t *testing.T) {
+	tests := []struct {
+		sql      string
+		parts    []IntervalPart
+		duration time.Duration
+		fixed    bool
+	}{
+		{"SELECT INTERVAL '1 day'", []IntervalPart{{1, "DAY"}}, 24 * time.Hour, true},
+		{"SELECT INTERVAL '2 hours 30 minutes'", []IntervalPart{{2, "HOUR"}, {30, "MINUTE"}}, 150 * time.Minute, true},
+		{"SELECT INTERVAL '-5 SECONDS'", []IntervalPart{{-5, "SECOND"}}, -5 * time.Second, true},
+		{"SELECT INTERVAL '1 year 2 weeks'", []IntervalPart{{1, "YEAR"}, {2, "WEEK"}}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			expr := parseSelectItemExpr(t, tt.sql)
+			interval, ok := expr.(*IntervalLiteral)
+			require.True(t, ok, "expected *IntervalLiteral, got %T", expr)
+			require.Equal(t, tt.parts, interval.Parts)
+			duration, fixed := interval.Duration()
+			require.Equal(t, tt.fixed, fixed)
+			require.Equal(t, tt.duration, duration)
+		})
+	}
+
+	// a unit after the string keeps the INTERVAL <expr> <unit> form
+	_, ok := parseSelectItemExpr(t, "SELECT INTERVAL '1' DAY").(*IntervalExpr)
+	require.True(t, ok)
+
+	for _, sql := range []string{
+		"SELECT INTERVAL '1'",
+		"SELECT INTERVAL 'one day'",
+		"SELECT INTERVAL '1 fortnight'",
+	} {
+		_, err := NewParser(sql).ParseStmts()
+		require.Error(t, err, sql)
+	}
+}
+
+func TestQuantifiedComparison(t *testing.T) {
+	expr := parseSelectItemExpr(t, "SELECT x > ANY (SELECT y FROM t) AND ok")
+	and, ok := expr.(*BinaryOperation)
+	require.True(t, ok, "expected *BinaryOperation, got %T", expr)
+	quantified, ok := and.LeftExpr.(*QuantifiedComparisonExpr)
+	require.True(t, ok, "left side of AND should be the quantified comparison, got %T", and.LeftExpr)
+	require.Equal(t, TokenKind(">"), quantified.Operation)
+	require.Equal(t, SubqueryQuantifierAny, quantified.Quantifier)
+
+	expr = parseSelectItemExpr(t, "SELECT x <> SOME (SELECT 1)")
+	quantified, ok = expr.(*QuantifiedComparisonExpr)
+	require.True(t, ok, "expected *QuantifiedComparisonExpr, got %T", expr)
+	require.Equal(t, SubqueryQuantifierAny, quantified.Quantifier)
+
+	expr = parseSelectItemExpr(t, "SELECT x = ALL (WITH 1 AS a SELECT a)")
+	quantified, ok = expr.(*QuantifiedComparisonExpr)
+	require.True(t, ok, "expected *QuantifiedComparisonExpr, got %T", expr)
+	require.Equal(t, SubqueryQuantifierAll, quantified.Quantifier)
+
+	// any() the aggregate function is an ordinary operand
+	expr = parseSelectItemExpr(t, "SELECT x = any(y)")
+	cmp, ok := expr.(*BinaryOperation)
+	require.True(t, ok, "expected *BinaryOperation, got %T", expr)
+	_, ok = cmp.RightExpr.(*FunctionExpr)
+	require.True(t, ok, "right side should be the any() call, got %T", cmp.RightExpr)
+}
+
+func TestDistinctFrom(t *testing.T) {
+	tests := []struct {
+		sql       string
+		hasNot    bool
+		shorthand bool
+	}{
+		{"SELECT a IS DISTINCT FROM b", false, false},
+		{"SELECT a IS NOT DISTINCT FROM b", true, false},
+		{"SELECT a <=> b", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			expr := parseSelectItemExpr(t, tt.sql)
+			distinct, ok := expr.(*DistinctFromExpr)
+			require.True(t, ok, "expected *DistinctFromExpr, got %T", expr)
+			require.Equal(t, tt.hasNot, distinct.HasNot)
+			require.Equal(t, tt.shorthand, distinct.Shorthand)
+		})
+	}
+
+	// IS DISTINCT FROM binds like IS NULL, looser than comparison and
+	// tighter than AND; <=> binds like =
+	expr := parseSelectItemExpr(t, "SELECT a IS DISTINCT FROM b + 1 AND c <=> d = e")
+	and, ok := expr.(*BinaryOperation)
+	require.True(t, ok, "expected *BinaryOperation, got %T", expr)
+	distinct, ok := and.LeftExpr.(*DistinctFromExpr)
+	require.True(t, ok, "left side of AND should be IS DISTINCT FROM, got %T", and.LeftExpr)
+	_, ok = distinct.RightExpr.(*BinaryOperation)
+	require.True(t, ok, "IS DISTINCT FROM should take all of `b + 1`, got %T", distinct.RightExpr)
+	eq, ok := and.RightExpr.(*BinaryOperation)
+	require.True(t, ok, "right side of AND should be the `=` comparison, got %T", and.RightExpr)
+	_, ok = eq.LeftExpr.(*DistinctFromExpr)
+	require.True(t, ok, "comparisons associate left, got %T", eq.LeftExpr)
+}
@@ -0,0 +1,433 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TableDiff is the migration from one definition of a table to another.
+type TableDiff struct {
+	Database string
+	Table    string
+	// Clauses are the ALTER TABLE clauses that migrate the table, in the
+	// order they must run.
+	Clauses []AlterTableClause
+	// RebuildReasons describes the changes that ALTER TABLE cannot make in
+	// place, such as a new engine or sorting key. When it is not empty the
+	// table has to be recreated and its data copied; Clauses then only cover
+	// the remaining changes.
+	RebuildReasons []string
+}
+
+// RequiresRebuild reports whether the table must be recreated.
+func (d *TableDiff) RequiresRebuild() bool {
+	return len(d.RebuildReasons) > 0
+}
+
+// Empty reports whether both definitions are the same.
+func (d *TableDiff) Empty() bool {
+	return len(d.Clauses) == 0 && len(d.RebuildReasons) == 0
+}
+
+// Statements returns one ALTER TABLE statement per clause. Clauses are kept
+// apart because ClickHouse refuses to combine some of them, and because
+// MODIFY SETTING and MODIFY TTL would absorb the clauses that follow them.
+// Use Format to render the statements.
+func (d *TableDiff) Statements() []*AlterTable {
+	table := &TableIdentifier{Table: &Ident{Name: d.Table}}
+	if d.Database != "" {
+		table.Database = &Ident{Name: d.Database}
+	}
+	stmts := make([]*AlterTable, 0, len(d.Clauses))
+	for _, clause := range d.Clauses {
+		stmts = append(stmts, &AlterTable{TableIdentifier: table, AlterExprs: []AlterTableClause{clause}})
+	}
+	return stmts
+}
+
+// SchemaDiff is the migration from one Catalog to another.
+type SchemaDiff struct {
+	// Created and Dropped are the tables, views and dictionaries that only
+	// exist in the new or the old catalog.
+	Created []*TableSchema
+	Dropped []*TableSchema
+	// Altered holds the non-empty diffs of the tables in both catalogs.
+	Altered []*TableDiff
+}
+
+// DiffCatalogs compares every table, view and dictionary of two catalogs.
+// Databases are not compared, and results are sorted by database and name.
+// Columns are never renamed; use DiffTables to pass rename hints.
+func DiffCatalogs(old, new *Catalog) *SchemaDiff {
+	diff := &SchemaDiff{}
+	for _, database := range old.Databases() {
+		for _, table := range database.Tables() {
+			newTable := new.Table(database.Name, table.Name)
+			if newTable == nil {
+				diff.Dropped = append(diff.Dropped, table)
+				continue
+			}
+			if tableDiff := DiffTables(table, newTable, nil); !tableDiff.Empty() {
+				diff.Altered = append(diff.Altered, tableDiff)
+			}
+		}
+	}
+	for _, database := range new.Databases() {
+		for _, table := range database.Tables() {
+			if old.Table(database.Name, table.Name) == nil {
+				diff.Created = append(diff.Created, table)
+			}
+		}
+	}
+	return diff
+}
+
+// DiffCreateTables compares two CREATE TABLE statements for the same table.
+// The clauses target the table named by old. renames is passed on to
+// DiffTables.
+func DiffCreateTables(old, new *CreateTable, renames map[string]string) (*TableDiff, error) {
+	oldTable, err := createTableSchema(old)
+	if err != nil {
+		return nil, err
+	}
+	newTable, err := createTableSchema(new)
+	if err != nil {
+		return nil, err
+	}
+	return DiffTables(oldTable, newTable, renames), nil
+}
+
+func createTableSchema(stmt *CreateTable) (*TableSchema, error) {
+	catalog := NewCatalog()
+	if stmt.Name.Database != nil {
+		name := stmt.Name.Database.Name
+		catalog.databases[name] = &DatabaseSchema{Name: name, tables: make(map[string]*TableSchema)}
+	}
+	if err := catalog.Apply(stmt); err != nil {
+		return nil, err
+	}
+	return catalog.Table(catalog.identifierDatabase(stmt.Name), stmt.Name.Table.Name), nil
+}
+
+// DiffTables returns the migration from old to new. Columns are matched by
+// name: a column that only exists in old is dropped and one that only exists
+// in new is added, unless renames, which maps old column names to new ones,
+// says it was renamed. Renames are never guessed, since renaming the wrong
+// column silently moves its data. Column order is only kept for added
+// columns that follow another column, and a changed index or projection is
+// dropped and added again.
+//
+// Indexes and projections are dropped before the columns change and added
+// after, as ClickHouse refuses to drop or modify a column they still use.
+func DiffTables(old, new *TableSchema, renames map[string]string) *TableDiff {
+	diff := &TableDiff{Database: old.Database, Table: old.Name}
+	if old.Kind != new.Kind {
+		diff.rebuild("kind changes from %s to %s", old.Kind, new.Kind)
+		return diff
+	}
+	diff.diffEngine(old, new)
+	indexDrops, indexAdds := diffIndexes(old.Indexes, new.Indexes)
+	projectionDrops, projectionAdds := diffProjections(old.Projections, new.Projections)
+	diff.Clauses = append(diff.Clauses, indexDrops...)
+	diff.Clauses = append(diff.Clauses, projectionDrops...)
+	diff.diffColumns(old.Columns, new.Columns, renames)
+	diff.Clauses = append(diff.Clauses, indexAdds...)
+	diff.Clauses = append(diff.Clauses, projectionAdds...)
+	diff.diffConstraints(old.Constraints, new.Constraints)
+	diff.diffQuery(old, new)
+	diff.diffSettings(old.Settings, new.Settings)
+	// MODIFY TTL comes last, like the TTL clause of CREATE TABLE.
+	if oldTTL, newTTL := formatOptional(old.TTL), formatOptional(new.TTL); oldTTL != newTTL {
+		if new.TTL == nil {
+			diff.Clauses = append(diff.Clauses, &AlterTableRemoveTTL{})
+		} else {
+			diff.Clauses = append(diff.Clauses, &AlterTableModifyTTL{TTL: new.TTL})
+		}
+	}
+	return diff
+}
+
+func (d *TableDiff) rebuild(format string, args ...any) {
+	d.RebuildReasons = append(d.RebuildReasons, fmt.Sprintf(format, args...))
+}
+
+func (d *TableDiff) diffEngine(old, new *TableSchema) {
+	if engineSignature(old.Engine) != engineSignature(new.Engine) {
+		d.rebuild("engine changes from %s to %s", engineSignature(old.Engine), engineSignature(new.Engine))
+	}
+	keys := []struct {
+		name     string
+		old, new Expr
+	}{
+		{"ORDER BY", old.OrderBy, new.OrderBy},
+		{"PARTITION BY", old.PartitionBy, new.PartitionBy},
+		{"PRIMARY KEY", old.PrimaryKey, new.PrimaryKey},
+		{"SAMPLE BY", old.SampleBy, new.SampleBy},
+	}
+	for _, key := range keys {
+		if oldKey, newKey := formatOptional(key.old), formatOptional(key.new); oldKey != newKey {
+			d.rebuild("%s changes from %q to %q", key.name, oldKey, newKey)
+		}
+	}
+}
+
+// engineSignature is the engine name and parameters, without the clauses
+// that TableSchema keeps apart.
+func engineSignature(engine *EngineExpr) string {
+	if engine == nil {
+		return ""
+	}
+	if engine.Params == nil {
+		return engine.Name
+	}
+	return engine.Name + Format(engine.Params)
+}
+
+func (d *TableDiff) diffColumns(old, new []*ColumnDef, renames map[string]string) {
+	oldByName := make(map[string]*ColumnDef, len(old))
+	for _, column := range old {
+		oldByName[nestedIdentifierName(column.Name)] = column
+	}
+	newByName := make(map[string]*ColumnDef, len(new))
+	for _, column := range new {
+		newByName[nestedIdentifierName(column.Name)] = column
+	}
+
+	// current follows the column names of the table as the clauses run
+	current := make([]string, 0, len(old))
+	// renamed maps the new name of a renamed column to its old definition
+	renamed := make(map[string]*ColumnDef)
+	var drops []AlterTableClause
+	for _, column := range old {
+		name := nestedIdentifierName(column.Name)
+		if newByName[name] != nil {
+			current = append(current, name)
+			continue
+		}
+		// a hint is only taken when the new name is free in old and not
+		// already taken by another rename
+		if newName, ok := renames[name]; ok && newByName[newName] != nil && oldByName[newName] == nil && renamed[newName] == nil {
+			d.Clauses = append(d.Clauses, &AlterTableRenameColumn{OldColumnName: column.Name, NewColumnName: newByName[newName].Name})
+			current = append(current, newName)
+			renamed[newName] = column
+			continue
+		}
+		drops = append(drops, &AlterTableDropColumn{ColumnName: column.Name})
+	}
+	d.Clauses = append(d.Clauses, drops...)
+
+	for i, column := range new {
+		name := nestedIdentifierName(column.Name)
+		oldColumn := oldByName[name]
+		if from := renamed[name]; from != nil {
+			// the renamed column is modified under its new name
+			moved := *from
+			moved.Name = column.Name
+			oldColumn = &moved
+		}
+		if oldColumn == nil {
+			add := &AlterTableAddColumn{Column: column}
+			// without FIRST, a leading column can only be appended
+			at := len(current)
+			if i > 0 {
+				previous := nestedIdentifierName(new[i-1].Name)
+				for j, currentName := range current {
+					if currentName == previous {
+						at = j + 1
+					}
+				}
+				if at < len(current) {
+					add.After = new[i-1].Name
+				}
+			}
+			current = insertAt(current, at, name)
+			d.Clauses = append(d.Clauses, add)
+			continue
+		}
+		d.diffColumn(oldColumn, column)
+	}
+}
+
+// diffColumn modifies a column. MODIFY COLUMN keeps the properties it does
+// not mention, so properties that new lacks are removed first.
+func (d *TableDiff) diffColumn(old, new *ColumnDef) {
+	if columnDefinition(old) == columnDefinition(new) {
+		return
+	}
+	properties := []struct {
+		name    string
+		present func(*ColumnDef) bool
+	}{
+		{defaultKind(old), func(c *ColumnDef) bool { return defaultKind(c) != "" }},
+		{KeywordCodec, func(c *ColumnDef) bool { return c.Codec != nil }},
+		{KeywordTtl, func(c *ColumnDef) bool { return c.TTL != nil }},
+		{KeywordComment, func(c *ColumnDef) bool { return c.Comment != nil }},
+		{KeywordSettings, func(c *ColumnDef) bool { return c.Settings != nil }},
+	}
+	remaining := old
+	for _, property := range properties {
+		if !property.present(old) || property.present(new) {
+			continue
+		}
+		remove := &RemovePropertyType{PropertyType: &PropertyType{Name: &Ident{Name: property.name}}}
+		d.Clauses = append(d.Clauses, &AlterTableModifyColumn{
+			Column:             &ColumnDef{Name: old.Name},
+			RemovePropertyType: remove,
+		})
+		remaining = removeColumnProperty(remaining, remove)
+	}
+	if columnDefinition(remaining) != columnDefinition(new) {
+		d.Clauses = append(d.Clauses, &AlterTableModifyColumn{Column: new})
+	}
+}
+
+// defaultKind returns the keyword of the default expression of a column,
+// or an empty string if it has none.
+func defaultKind(column *ColumnDef) string {
+	switch {
+	case column.DefaultExpr != nil:
+		return KeywordDefault
+	case column.MaterializedExpr != nil:
+		return KeywordMaterialized
+	case column.AliasExpr != nil:
+		return KeywordAlias
+	case column.Ephemeral:
+		return KeywordEphemeral
+	}
+	return ""
+}
+
+// columnDefinition formats a column without its name.
+func columnDefinition(column *ColumnDef) string {
+	unnamed := *column
+	unnamed.Name = &NestedIdentifier{Ident: &Ident{}}
+	return Format(&unnamed)
+}
+
+// diffIndexes returns the clauses dropping the indexes that are gone or
+// changed, and those adding the new and changed ones.
+func diffIndexes(old, new []*TableIndex) (drops, adds []AlterTableClause) {
+	for _, index := range old {
+		name := nestedIdentifierName(index.Name)
+		if i := indexOf(new, name); i < 0 || Format(new[i]) != Format(index) {
+			drops = append(drops, &AlterTableDropIndex{IndexName: index.Name})
+		}
+	}
+	for _, index := range new {
+		name := nestedIdentifierName(index.Name)
+		if i := indexOf(old, name); i < 0 || Format(old[i]) != Format(index) {
+			adds = append(adds, &AlterTableAddIndex{Index: index})
+		}
+	}
+	return drops, adds
+}
+
+// diffProjections is like diffIndexes for projections.
+func diffProjections(old, new []*TableProjection) (drops, adds []AlterTableClause) {
+	for _, projection := range old {
+		name := nestedIdentifierName(projection.Identifier)
+		if i := projectionOf(new, name); i < 0 || projectionDefinition(new[i]) != projectionDefinition(projection) {
+			drops = append(drops, &AlterTableDropProjection{ProjectionName: projection.Identifier})
+		}
+	}
+	for _, projection := range new {
+		name := nestedIdentifierName(projection.Identifier)
+		if i := projectionOf(old, name); i < 0 || projectionDefinition(old[i]) != projectionDefinition(projection) {
+			// ADD PROJECTION writes the keyword itself
+			bare := *projection
+			bare.IncludeProjectionKeyword = false
+			adds = append(adds, &AlterTableAddProjection{TableProjection: &bare})
+		}
+	}
+	return drops, adds
+}
+
+// projectionDefinition formats a projection the same way whether it comes
+// from CREATE TABLE or ADD PROJECTION.
+func projectionDefinition(projection *TableProjection) string {
+	bare := *projection
+	bare.IncludeProjectionKeyword = false
+	return Format(&bare)
+}
+
+// diffConstraints reports changed constraints as rebuild reasons, since
+// the AST has no ALTER TABLE clause for them.
+func (d *TableDiff) diffConstraints(old, new []*ConstraintClause) {
+	oldConstraints := make(map[string]string, len(old))
+	for _, constraint := range old {
+		oldConstraints[constraint.Constraint.Name] = Format(constraint)
+	}
+	newConstraints := make(map[string]string, len(new))
+	for _, constraint := range new {
+		newConstraints[constraint.Constraint.Name] = Format(constraint)
+	}
+	for _, constraint := range old {
+		if _, ok := newConstraints[constraint.Constraint.Name]; !ok {
+			d.rebuild("constraint %s is dropped", constraint.Constraint.Name)
+		}
+	}
+	for _, constraint := range new {
+		name := constraint.Constraint.Name
+		if definition, ok := oldConstraints[name]; !ok {
+			d.rebuild("constraint %s is added", name)
+		} else if definition != newConstraints[name] {
+			d.rebuild("constraint %s changes", name)
+		}
+	}
+}
+
+func (d *TableDiff) diffQuery(old, new *TableSchema) {
+	if oldDestination, newDestination := formatOptional(old.Destination), formatOptional(new.Destination); oldDestination != newDestination {
+		d.rebuild("destination changes from %q to %q", oldDestination, newDestination)
+	}
+	if formatOptional(old.Query) == formatOptional(new.Query) {
+		return
+	}
+	if old.Kind == TableKindMaterializedView && new.Query != nil {
+		d.Clauses = append(d.Clauses, &AlterTableModifyQuery{SelectExpr: new.Query})
+		return
+	}
+	d.rebuild("query changes")
+}
+
+func (d *TableDiff) diffSettings(old, new []*SettingExpr) {
+	oldSettings := make(map[string]string, len(old))
+	for _, setting := range old {
+		oldSettings[setting.Name.Name] = Format(setting.Expr)
+	}
+	newSettings := make(map[string]bool, len(new))
+	modify := &AlterTableModifySetting{}
+	for _, setting := range new {
+		newSettings[setting.Name.Name] = true
+		if value, ok := oldSettings[setting.Name.Name]; !ok || value != Format(setting.Expr) {
+			modify.Settings = append(modify.Settings, setting)
+		}
+	}
+	reset := &AlterTableResetSetting{}
+	for _, setting := range old {
+		if !newSettings[setting.Name.Name] {
+			reset.Settings = append(reset.Settings, setting.Name)
+		}
+	}
+	sort.Slice(reset.Settings, func(i, j int) bool {
+		return reset.Settings[i].Name < reset.Settings[j].Name
+	})
+	if len(modify.Settings) > 0 {
+		d.Clauses = append(d.Clauses, modify)
+	}
+	if len(reset.Settings) > 0 {
+		d.Clauses = append(d.Clauses, reset)
+	}
+}
+
+// formatOptional formats a node that may be a nil pointer.
+func formatOptional[T interface {
+	comparable
+	Expr
+}](expr T) string {
+	var zero T
+	if expr == zero {
+		return ""
+	}
+	return Format(expr)
+}
@@ -0,0 +1,192 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func diffOf(t *testing.T, oldSQL, newSQL string, renames map[string]string) *TableDiff {
+	t.Helper()
+	oldStmt, ok := parseOneStmt(t, oldSQL).(*CreateTable)
+	require.True(t, ok)
+	newStmt, ok := parseOneStmt(t, newSQL).(*CreateTable)
+	require.True(t, ok)
+	diff, err := DiffCreateTables(oldStmt, newStmt, renames)
+	require.NoError(t, err)
+	return diff
+}
+
+func formatStatements(diff *TableDiff) []string {
+	var sqls []string
+	for _, stmt := range diff.Statements() {
+		sqls = append(sqls, Format(stmt))
+	}
+	return sqls
+}
+
+func TestDiffCreateTables(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		renames  map[string]string
+		expected []string
+		rebuild  []string
+	}{
+		{
+			name: "no changes",
+			old:  "CREATE TABLE db.t (a UInt8) ENGINE = MergeTree ORDER BY a",
+			new:  "CREATE TABLE db.t (a UInt8) ENGINE = MergeTree ORDER BY a",
+		},
+		{
+			name:    "add, drop and rename columns",
+			old:     "CREATE TABLE db.t (a UInt8, b String, c Date, d Int32) ENGINE = MergeTree ORDER BY a",
+			new:     "CREATE TABLE db.t (a UInt8, x String, e Float64, c Date, f UInt8) ENGINE = MergeTree ORDER BY a",
+			renames: map[string]string{"b": "x"},
+			expected: []string{
+				"ALTER TABLE db.t RENAME COLUMN b TO x",
+				"ALTER TABLE db.t DROP COLUMN d",
+				"ALTER TABLE db.t ADD COLUMN e Float64 AFTER x",
+				"ALTER TABLE db.t ADD COLUMN f UInt8",
+			},
+		},
+		{
+			name: "columns are not renamed without a hint",
+			old:  "CREATE TABLE t (id UInt64, email String) ENGINE = MergeTree ORDER BY id",
+			new:  "CREATE TABLE t (id UInt64, phone String) ENGINE = MergeTree ORDER BY id",
+			expected: []string{
+				"ALTER TABLE default.t DROP COLUMN email",
+				"ALTER TABLE default.t ADD COLUMN phone String",
+			},
+		},
+		{
+			name:    "renamed column is modified under its new name",
+			old:     "CREATE TABLE t (id UInt64, email String COMMENT 'e') ENGINE = MergeTree ORDER BY id",
+			new:     "CREATE TABLE t (id UInt64, contact LowCardinality(String)) ENGINE = MergeTree ORDER BY id",
+			renames: map[string]string{"email": "contact", "missing": "id"},
+			expected: []string{
+				"ALTER TABLE default.t RENAME COLUMN email TO contact",
+				"ALTER TABLE default.t MODIFY COLUMN contact REMOVE COMMENT",
+				"ALTER TABLE default.t MODIFY COLUMN contact LowCardinality(String)",
+			},
+		},
+		{
+			name: "modify columns",
+			old:  "CREATE TABLE t (a UInt8, b String DEFAULT 'x' COMMENT 'b', c String MATERIALIZED 'y') ENGINE = MergeTree ORDER BY a",
+			new:  "CREATE TABLE t (a UInt16, b LowCardinality(String) DEFAULT 'x', c String) ENGINE = MergeTree ORDER BY a",
+			expected: []string{
+				"ALTER TABLE default.t MODIFY COLUMN a UInt16",
+				"ALTER TABLE default.t MODIFY COLUMN b REMOVE COMMENT",
+				"ALTER TABLE default.t MODIFY COLUMN b LowCardinality(String) DEFAULT 'x'",
+				"ALTER TABLE default.t MODIFY COLUMN c REMOVE MATERIALIZED",
+			},
+		},
+		{
+			name: "indexes and projections",
+			old: "CREATE TABLE t (a UInt8, b String, INDEX i1 a TYPE minmax GRANULARITY 1, INDEX i2 b TYPE bloom_filter GRANULARITY 1, " +
+				"PROJECTION p1 (SELECT a ORDER BY b)) ENGINE = MergeTree ORDER BY a",
+			new: "CREATE TABLE t (a UInt8, b String, INDEX i1 a TYPE minmax GRANULARITY 4, INDEX i3 b TYPE set(100) GRANULARITY 1, " +
+				"PROJECTION p2 (SELECT b ORDER BY a)) ENGINE = MergeTree ORDER BY a",
+			expected: []string{
+				"ALTER TABLE default.t DROP INDEX i1",
+				"ALTER TABLE default.t DROP INDEX i2",
+				"ALTER TABLE default.t DROP PROJECTION p1",
+				"ALTER TABLE default.t ADD INDEX i1 a TYPE minmax GRANULARITY 4",
+				"ALTER TABLE default.t ADD INDEX i3 b TYPE set(100) GRANULARITY 1",
+				"ALTER TABLE default.t ADD PROJECTION p2 (SELECT b ORDER BY a)",
+			},
+		},
+		{
+			name: "indexes and projections are dropped before the columns they use",
+			old: "CREATE TABLE t (a UInt8, b String, c UInt8, INDEX ib b TYPE bloom_filter GRANULARITY 1, " +
+				"PROJECTION pc (SELECT a ORDER BY c)) ENGINE = MergeTree ORDER BY a",
+			new: "CREATE TABLE t (a UInt8, c UInt16, d String, INDEX id d TYPE bloom_filter GRANULARITY 1, " +
+				"PROJECTION pc (SELECT a ORDER BY c, d)) ENGINE = MergeTree ORDER BY a",
+			expected: []string{
+				"ALTER TABLE default.t DROP INDEX ib",
+				"ALTER TABLE default.t DROP PROJECTION pc",
+				"ALTER TABLE default.t DROP COLUMN b",
+				"ALTER TABLE default.t MODIFY COLUMN c UInt16",
+				"ALTER TABLE default.t ADD COLUMN d String",
+				"ALTER TABLE default.t ADD INDEX id d TYPE bloom_filter GRANULARITY 1",
+				"ALTER TABLE default.t ADD PROJECTION pc (SELECT a ORDER BY c, d)",
+			},
+		},
+		{
+			name: "settings and TTL",
+			old:  "CREATE TABLE t (a UInt8, ts DateTime) ENGINE = MergeTree ORDER BY a TTL ts + INTERVAL 1 DAY SETTINGS index_granularity = 8192, ttl_only_drop_parts = 1",
+			new:  "CREATE TABLE t (a UInt8, ts DateTime) ENGINE = MergeTree ORDER BY a TTL ts + INTERVAL 7 DAY SETTINGS index_granularity = 4096, min_bytes_for_wide_part = 0",
+			expected: []string{
+				"ALTER TABLE default.t MODIFY SETTING index_granularity=4096, min_bytes_for_wide_part=0",
+				"ALTER TABLE default.t RESET SETTING ttl_only_drop_parts",
+				"ALTER TABLE default.t MODIFY TTL ts + INTERVAL 7 DAY",
+			},
+		},
+		{
+			name: "remove TTL",
+			old:  "CREATE TABLE t (a UInt8, ts DateTime) ENGINE = MergeTree ORDER BY a TTL ts + INTERVAL 1 DAY",
+			new:  "CREATE TABLE t (a UInt8, ts DateTime) ENGINE = MergeTree ORDER BY a",
+			expected: []string{
+				"ALTER TABLE default.t REMOVE TTL",
+			},
+		},
+		{
+			name:    "sorting key and engine changes need a rebuild",
+			old:     "CREATE TABLE t (a UInt8, b UInt8) ENGINE = MergeTree ORDER BY a",
+			new:     "CREATE TABLE t (a UInt8, b UInt8, c UInt8) ENGINE = ReplacingMergeTree(b) ORDER BY (a, b)",
+			rebuild: []string{"engine changes from MergeTree to ReplacingMergeTree(b)", `ORDER BY changes from "a" to "(a, b)"`},
+			expected: []string{
+				"ALTER TABLE default.t ADD COLUMN c UInt8",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := diffOf(t, tt.old, tt.new, tt.renames)
+			require.Equal(t, tt.expected, formatStatements(diff))
+			require.Equal(t, tt.rebuild, diff.RebuildReasons)
+			require.Equal(t, len(tt.rebuild) > 0, diff.RequiresRebuild())
+		})
+	}
+}
+
+func TestDiffCatalogs(t *testing.T) {
+	old := catalogOf(t, `
+CREATE TABLE kept (a UInt8) ENGINE = Memory;
+CREATE TABLE dropped (a UInt8) ENGINE = Memory;
+CREATE TABLE changed (a UInt8) ENGINE = Memory;
+CREATE MATERIALIZED VIEW mv TO kept AS SELECT a FROM changed;
+`)
+	new := catalogOf(t, `
+CREATE TABLE kept (a UInt8) ENGINE = Memory;
+CREATE TABLE created (a UInt8) ENGINE = Memory;
+CREATE TABLE changed (a UInt8, b String) ENGINE = Memory;
+CREATE MATERIALIZED VIEW mv TO kept AS SELECT a FROM changed WHERE a > 1;
+`)
+	diff := DiffCatalogs(old, new)
+	require.Len(t, diff.Created, 1)
+	require.Equal(t, "created", diff.Created[0].Name)
+	require.Len(t, diff.Dropped, 1)
+	require.Equal(t, "dropped", diff.Dropped[0].Name)
+	require.Len(t, diff.Altered, 2)
+	require.Equal(t, []string{"ALTER TABLE default.changed ADD COLUMN b String"}, formatStatements(diff.Altered[0]))
+	require.Equal(t, []string{"ALTER TABLE default.mv MODIFY QUERY SELECT a FROM changed WHERE a > 1"}, formatStatements(diff.Altered[1]))
+}
+
+func TestDiffTablesRoundTrip(t *testing.T) {
+	oldSQL := "CREATE TABLE t (a UInt8, b String COMMENT 'b', c Date, INDEX i a TYPE minmax GRANULARITY 1) ENGINE = MergeTree ORDER BY a SETTINGS index_granularity = 1024"
+	newSQL := "CREATE TABLE t (z Int8, a UInt8, renamed String COMMENT 'b', d Date DEFAULT today(), INDEX j a TYPE minmax GRANULARITY 1) ENGINE = MergeTree ORDER BY a"
+	diff := diffOf(t, oldSQL, newSQL, map[string]string{"b": "renamed"})
+
+	catalog := catalogOf(t, oldSQL)
+	for _, sql := range formatStatements(diff) {
+		require.NoError(t, catalog.Apply(parseOneStmt(t, sql)), sql)
+	}
+	expected := catalogOf(t, newSQL).Table("", "t")
+	migrated := catalog.Table("", "t")
+	require.ElementsMatch(t, expected.ColumnNames(), migrated.ColumnNames())
+	remaining := DiffTables(migrated, expected, nil)
+	require.Empty(t, remaining.RebuildReasons)
+	require.Empty(t, remaining.Clauses)
+}
@@ -0,0 +1,459 @@
+package parser
+
+import (
+	"strings"
+)
+
+// scopeColumn is a column of a relation or an output column of a query,
+// with the value an analysis computes for it: its sources for lineage and
+// validation, its type for type inference.
+type scopeColumn[T any] struct {
+	name  string
+	value T
+}
+
+// relation is a table, subquery or CTE in a FROM clause.
+type relation[T any] struct {
+	// names are the qualifiers its columns can be referenced by.
+	names []string
+	// columns is nil when they are unknown, as for a table missing from the
+	// schema or a table function.
+	columns []scopeColumn[T]
+	// source is the table or table function the relation reads, and nil for
+	// subqueries and CTEs.
+	source Expr
+}
+
+// table returns the table the relation reads, or nil.
+func (r *relation[T]) table() *TableIdentifier {
+	table, _ := r.source.(*TableIdentifier)
+	return table
+}
+
+// column returns the value of one of the known columns of the relation.
+func (r *relation[T]) column(name string) (T, bool) {
+	for _, column := range r.columns {
+		if column.name == name {
+			return column.value, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (r *relation[T]) hasName(name string) bool {
+	for _, n := range r.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// scope holds the names visible in one SELECT or lambda.
+type scope[T any] struct {
+	parent    *scope[T]
+	ctes      map[string]*relation[T]
+	relations []*relation[T]
+	// arrayJoins holds the elements of the arrays joined by ARRAY JOIN, by
+	// alias, by the name of an array joined under its own name, and by the
+	// dotted names of the fields of a joined Nested column.
+	arrayJoins map[string]T
+	// params holds the parameters of a lambda.
+	params map[string]T
+	// aliases maps the aliases of the SELECT list and of WITH expressions to
+	// their expressions; resolving guards against cyclic aliases.
+	aliases   map[string]Expr
+	resolving map[string]bool
+}
+
+func newScope[T any](parent *scope[T]) *scope[T] {
+	return &scope[T]{
+		parent:     parent,
+		ctes:       make(map[string]*relation[T]),
+		arrayJoins: make(map[string]T),
+		params:     make(map[string]T),
+		aliases:    make(map[string]Expr),
+		resolving:  make(map[string]bool),
+	}
+}
+
+func (s *scope[T]) cte(name string) (*relation[T], bool) {
+	for ; s != nil; s = s.parent {
+		if relation, ok := s.ctes[name]; ok {
+			return relation, relation != nil
+		}
+	}
+	return nil, false
+}
+
+// qualifies reports whether parts name a relation of the scope.
+func (s *scope[T]) qualifies(parts []string) bool {
+	name := strings.Join(parts, ".")
+	for _, relation := range s.relations {
+		if relation.hasName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeAnalysis computes the column values of an analysis that resolves
+// names through a resolver.
+type scopeAnalysis[T any] interface {
+	// expr returns the value of an expression.
+	expr(expr Expr, s *scope[T]) T
+	// tableColumns returns the columns of a table, or nil if they are
+	// unknown.
+	tableColumns(table *TableIdentifier) []scopeColumn[T]
+	// unknownColumn returns the value of a column of a relation whose
+	// columns are unknown, and whether the column can be attributed to it.
+	unknownColumn(r *relation[T], name string) (T, bool)
+	// unknownStar returns the columns * expands to over a relation whose
+	// columns are unknown.
+	unknownStar(r *relation[T]) []scopeColumn[T]
+	// element returns the value of the elements of an array, or false when
+	// value is not known to be an array.
+	element(value T) (T, bool)
+	// field returns the value of a subcolumn such as tuple_column.field.
+	field(value T, name string) T
+	// merge combines the values of a column in the branches of a set
+	// operation.
+	merge(a, b T) T
+	// clauses is called for the clauses of a SELECT that produce no output
+	// columns, once s holds the relations and aliases of the SELECT.
+	clauses(q *SelectQuery, s *scope[T])
+}
+
+// resolver resolves the relations, aliases and column references of
+// queries, leaving the values of columns to its analysis. Lineage, Validate
+// and type inference share it.
+type resolver[T any] struct {
+	analysis scopeAnalysis[T]
+}
+
+// query returns the output columns of a query and its set operations,
+// whose values are merged by position.
+func (r *resolver[T]) query(q *SelectQuery, parent *scope[T]) []scopeColumn[T] {
+	var columns []scopeColumn[T]
+	if q.InnerQuery != nil {
+		columns = r.query(q.InnerQuery, parent)
+	} else {
+		columns = r.selectColumns(q, parent)
+	}
+	for _, next := range []*SelectQuery{q.UnionAll, q.UnionDistinct, q.Except, q.Intersect} {
+		if next == nil {
+			continue
+		}
+		for i, column := range r.query(next, parent) {
+			if i < len(columns) {
+				columns[i].value = r.analysis.merge(columns[i].value, column.value)
+			}
+		}
+	}
+	return columns
+}
+
+func (r *resolver[T]) selectColumns(q *SelectQuery, parent *scope[T]) []scopeColumn[T] {
+	s := newScope(parent)
+	if q.With != nil {
+		for _, cte := range q.With.CTEs {
+			switch alias := cte.Alias.(type) {
+			case *SelectQuery:
+				name, ok := cte.Expr.(*Ident)
+				if !ok {
+					continue
+				}
+				// a nil entry hides the table of the same name while a
+				// recursive CTE is being resolved
+				s.ctes[name.Name] = nil
+				s.ctes[name.Name] = &relation[T]{
+					names:   []string{name.Name},
+					columns: r.query(alias, s),
+				}
+			case *Ident:
+				s.aliases[alias.Name] = cte.Expr
+			}
+		}
+	}
+	if q.From != nil {
+		r.addFromRelations(q.From.Expr, s)
+	}
+	for _, item := range q.SelectItems {
+		if item.Alias != nil {
+			s.aliases[item.Alias.Name] = item.Expr
+		}
+	}
+	r.analysis.clauses(q, s)
+
+	var columns []scopeColumn[T]
+	for _, item := range q.SelectItems {
+		if relations, ok := r.starRelations(item.Expr, s); ok {
+			columns = append(columns, r.expandStar(relations, item.Modifiers, s)...)
+			continue
+		}
+		columns = append(columns, scopeColumn[T]{
+			name:  selectItemName(item, s.qualifies),
+			value: r.analysis.expr(item.Expr, s),
+		})
+	}
+	return columns
+}
+
+func (r *resolver[T]) addFromRelations(expr Expr, s *scope[T]) {
+	switch e := expr.(type) {
+	case *JoinExpr:
+		if e.IsArrayJoin() {
+			r.addArrayJoin(e.Left, s)
+		} else {
+			r.addFromRelations(e.Left, s)
+		}
+		r.addFromRelations(e.Right, s)
+	case *JoinTableExpr:
+		r.addFromRelations(e.Table, s)
+	case *TableExpr:
+		if relation := r.tableRelation(e, s); relation != nil {
+			s.relations = append(s.relations, relation)
+		}
+	}
+}
+
+func (r *resolver[T]) addArrayJoin(expr Expr, s *scope[T]) {
+	list, ok := expr.(*ColumnExprList)
+	if !ok {
+		return
+	}
+	for _, item := range list.Items {
+		array, name := item, ""
+		if column, ok := item.(*ColumnExpr); ok {
+			array = column.Expr
+			if column.Alias != nil {
+				name = column.Alias.Name
+			}
+		}
+		ident, _ := unwrapColumnExpr(array).(*Ident)
+		if name == "" {
+			if ident == nil {
+				continue
+			}
+			name = ident.Name
+		}
+		s.arrayJoins[name], _ = r.analysis.element(r.analysis.expr(array, s))
+		if ident == nil {
+			continue
+		}
+		// joining a Nested column joins each of its fields
+		for _, relation := range s.relations {
+			for _, column := range relation.columns {
+				field, ok := strings.CutPrefix(column.name, ident.Name+".")
+				if !ok {
+					continue
+				}
+				if element, ok := r.analysis.element(column.value); ok {
+					s.arrayJoins[name+"."+field] = element
+				}
+			}
+		}
+	}
+}
+
+func (r *resolver[T]) tableRelation(e *TableExpr, s *scope[T]) *relation[T] {
+	expr := e.Expr
+	var alias string
+	if aliasExpr, ok := expr.(*AliasExpr); ok {
+		expr = aliasExpr.Expr
+		if ident, ok := aliasExpr.Alias.(*Ident); ok {
+			alias = ident.Name
+		}
+	}
+	var result *relation[T]
+	switch t := expr.(type) {
+	case *TableIdentifier:
+		if cte, ok := s.cte(t.Table.Name); ok && t.Database == nil {
+			result = &relation[T]{names: []string{t.Table.Name}, columns: cte.columns}
+			break
+		}
+		result = &relation[T]{names: []string{t.Table.Name}, source: t}
+		if t.Database != nil {
+			result.names = append(result.names, t.Database.Name+"."+t.Table.Name)
+		}
+		result.columns = r.analysis.tableColumns(t)
+	case *SubQuery:
+		if t.Select == nil {
+			return nil
+		}
+		result = &relation[T]{columns: r.query(t.Select, s)}
+	case *TableFunctionExpr:
+		result = &relation[T]{source: t}
+	default:
+		return nil
+	}
+	if alias != "" {
+		result.names = append([]string{alias}, result.names...)
+	}
+	return result
+}
+
+// starRelations reports whether expr is * or qualifier.*, and returns the
+// relations it expands.
+func (r *resolver[T]) starRelations(expr Expr, s *scope[T]) ([]*relation[T], bool) {
+	switch e := expr.(type) {
+	case *Ident:
+		if e.Name == "*" {
+			return s.relations, true
+		}
+	case *NestedIdentifier:
+		if e.DotIdent != nil && e.DotIdent.Name == "*" {
+			for i, relation := range s.relations {
+				if relation.hasName(e.Ident.Name) {
+					return s.relations[i : i+1], true
+				}
+			}
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+func (r *resolver[T]) expandStar(relations []*relation[T], modifiers []*FunctionExpr, s *scope[T]) []scopeColumn[T] {
+	excluded := make(map[string]bool)
+	replaced := make(map[string]Expr)
+	for _, modifier := range modifiers {
+		if modifier.Params == nil || modifier.Params.Items == nil {
+			continue
+		}
+		for _, param := range modifier.Params.Items.Items {
+			switch strings.ToUpper(modifier.Name.Name) {
+			case KeywordExcept:
+				if ident, ok := unwrapColumnExpr(param).(*Ident); ok {
+					excluded[ident.Name] = true
+				}
+			case KeywordReplace:
+				if column, ok := param.(*ColumnExpr); ok && column.Alias != nil {
+					replaced[column.Alias.Name] = column.Expr
+				}
+			}
+		}
+	}
+
+	var columns []scopeColumn[T]
+	for _, relation := range relations {
+		if relation.columns == nil {
+			columns = append(columns, r.analysis.unknownStar(relation)...)
+			continue
+		}
+		for _, column := range relation.columns {
+			if excluded[column.name] {
+				continue
+			}
+			if expr, ok := replaced[column.name]; ok {
+				column = scopeColumn[T]{name: column.name, value: r.analysis.expr(expr, s)}
+			}
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// column returns the value of a column reference given as its dotted parts,
+// such as [t x] for t.x, looking it up in s and then in its parents.
+func (r *resolver[T]) column(parts []string, s *scope[T]) T {
+	for ; s != nil; s = s.parent {
+		if value, ok := r.columnLocal(parts, s); ok {
+			return value
+		}
+	}
+	var zero T
+	return zero
+}
+
+func (r *resolver[T]) columnLocal(parts []string, s *scope[T]) (T, bool) {
+	var zero T
+	name := strings.Join(parts, ".")
+	if value, ok := s.arrayJoins[name]; ok {
+		return value, true
+	}
+	if len(parts) == 1 {
+		if value, ok := s.params[name]; ok {
+			return value, true
+		}
+		if expr, ok := s.aliases[name]; ok && !s.resolving[name] {
+			s.resolving[name] = true
+			defer delete(s.resolving, name)
+			return r.analysis.expr(expr, s), true
+		}
+	}
+	// a dotted name may be a column itself, like the columns of a Nested
+	for _, relation := range s.relations {
+		if value, ok := relation.column(name); ok {
+			return value, true
+		}
+	}
+	for i := len(parts) - 1; i > 0; i-- {
+		qualifier := strings.Join(parts[:i], ".")
+		for _, relation := range s.relations {
+			if !relation.hasName(qualifier) {
+				continue
+			}
+			if value, ok := relation.column(strings.Join(parts[i:], ".")); ok {
+				return value, true
+			}
+			value, ok := relation.column(parts[i])
+			if relation.columns == nil {
+				value, ok = r.analysis.unknownColumn(relation, parts[i])
+			}
+			if !ok {
+				return zero, true
+			}
+			for _, field := range parts[i+1:] {
+				value = r.analysis.field(value, field)
+			}
+			return value, true
+		}
+	}
+	if len(parts) > 1 {
+		// a subcolumn such as tuple_column.field
+		value, ok := r.columnLocal(parts[:1], s)
+		if !ok {
+			return zero, false
+		}
+		for _, field := range parts[1:] {
+			value = r.analysis.field(value, field)
+		}
+		return value, true
+	}
+	// the column can only come from a relation whose columns are unknown;
+	// it is attributed only when there is a single candidate
+	var candidate *relation[T]
+	for _, relation := range s.relations {
+		if relation.columns == nil {
+			if candidate != nil {
+				return zero, false
+			}
+			candidate = relation
+		}
+	}
+	if candidate == nil {
+		return zero, false
+	}
+	return r.analysis.unknownColumn(candidate, name)
+}
+
+// selectItemName returns the name of the column a select item produces.
+// qualifies reports whether dotted parts name a table, whose prefix the
+// column name drops.
+func selectItemName(item *SelectItem, qualifies func(parts []string) bool) string {
+	if item.Alias != nil {
+		return item.Alias.Name
+	}
+	switch e := item.Expr.(type) {
+	case *Ident:
+		return e.Name
+	case *NestedIdentifier, *Path:
+		parts := identifierParts(e)
+		if len(parts) > 1 && qualifies(parts[:len(parts)-1]) {
+			return parts[len(parts)-1]
+		}
+	}
+	return Format(item.Expr)
+}
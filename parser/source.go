@@ -1,6 +1,9 @@
 package parser
 
-import "sort"
+import (
+	"sort"
+	"unicode/utf8"
+)
 
 // lineStarts holds the byte offset where each line of the input begins
 // (lineStarts[i] is the start of 0-based line i). It is built once so that
@@ -17,8 +20,9 @@ func newLineStarts(input string) lineStarts {
 	return starts
 }
 
-// position returns the 1-based line and column for a byte offset.
-func (s lineStarts) position(offset int) (line, col int) {
+// position returns the 1-based line and column for a byte offset. Columns
+// count runes, not bytes, so they match what an editor shows for UTF-8 text.
+func (s lineStarts) position(input string, offset int) (line, col int) {
 	// Find the largest line start that is <= offset.
 	i := sort.Search(len(s), func(i int) bool {
 		return s[i] > offset
@@ -26,7 +30,13 @@ func (s lineStarts) position(offset int) (line, col int) {
 	if i < 0 {
 		i = 0
 	}
-	return i + 1, offset - s[i] + 1
+	if offset > len(input) {
+		offset = len(input)
+	}
+	if offset < s[i] {
+		return i + 1, 1
+	}
+	return i + 1, utf8.RuneCountInString(input[s[i]:offset]) + 1
 }
 
 // lineText returns the text of the given 1-based line, without the trailing
@@ -0,0 +1,299 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// TableRole is how a statement uses a table it references.
+type TableRole string
+
+const (
+	// TableRoleRead marks a table whose data the statement reads.
+	TableRoleRead TableRole = "READ"
+	// TableRoleWrite marks a table whose data the statement changes, such as
+	// the target of INSERT, DELETE or an ALTER TABLE mutation.
+	TableRoleWrite TableRole = "WRITE"
+	// TableRoleDDLTarget marks a table whose definition the statement creates,
+	// changes or removes, or that a maintenance statement such as OPTIMIZE or
+	// SYSTEM acts on.
+	TableRoleDDLTarget TableRole = "DDL_TARGET"
+	// TableRoleDependency marks a table that a created object refers to
+	// without reading it now: the query of a view, the TO table of a
+	// materialized view, the tables behind Distributed and Buffer engines
+	// and ClickHouse dictionary sources, and tables whose structure is copied.
+	TableRoleDependency TableRole = "DEPENDENCY"
+)
+
+// TableReference is a table, view or dictionary referenced by a statement.
+type TableReference struct {
+	// Database is empty when the statement does not qualify the table.
+	Database string
+	Table    string
+	// Alias is the alias the table is given in a FROM or JOIN clause.
+	Alias string
+	Role  TableRole
+	// Node is the *TableIdentifier naming the table, or the *EngineExpr or
+	// *DictionarySourceClause whose parameters name it.
+	Node Expr
+}
+
+// QualifiedName returns database.table, using defaultDatabase for an
+// unqualified table. It returns the bare table name when both are empty.
+func (r TableReference) QualifiedName(defaultDatabase string) string {
+	database := r.Database
+	if database == "" {
+		database = defaultDatabase
+	}
+	if database == "" {
+		return r.Table
+	}
+	return database + "." + r.Table
+}
+
+// TableReferences returns every table referenced by stmt, in source order.
+// References to CTEs, aliases and table functions are left out, and a table
+// referenced several times is reported once per reference.
+func TableReferences(stmt Expr) []TableReference {
+	if stmt == nil {
+		return nil
+	}
+	collector := &tableCollector{}
+	_ = stmt.Accept(collector)
+	sort.SliceStable(collector.refs, func(i, j int) bool {
+		return collector.refs[i].Node.Pos() < collector.refs[j].Node.Pos()
+	})
+	return collector.refs
+}
+
+type tableCollector struct {
+	DefaultASTVisitor
+	refs []TableReference
+	// ctes holds the CTE names defined by each enclosing SELECT.
+	ctes []map[string]bool
+	// definitions counts the enclosing view and materialized view queries,
+	// whose tables are dependencies rather than reads.
+	definitions int
+}
+
+func (c *tableCollector) add(table *TableIdentifier, role TableRole) {
+	c.addAliased(table, "", role)
+}
+
+func (c *tableCollector) addAliased(table *TableIdentifier, alias string, role TableRole) {
+	if table == nil || table.Table == nil || table.Table.Name == "*" {
+		return
+	}
+	ref := TableReference{Table: table.Table.Name, Alias: alias, Role: role, Node: table}
+	if table.Database != nil {
+		if table.Database.Name == "*" {
+			return
+		}
+		ref.Database = table.Database.Name
+	}
+	c.refs = append(c.refs, ref)
+}
+
+func (c *tableCollector) addNamed(node Expr, database, table string, role TableRole) {
+	if table == "" {
+		return
+	}
+	if strings.EqualFold(database, "currentDatabase()") {
+		database = ""
+	}
+	c.refs = append(c.refs, TableReference{Database: database, Table: table, Role: role, Node: node})
+}
+
+func (c *tableCollector) readRole() TableRole {
+	if c.definitions > 0 {
+		return TableRoleDependency
+	}
+	return TableRoleRead
+}
+
+func (c *tableCollector) isCTE(table *TableIdentifier) bool {
+	if table.Database != nil {
+		return false
+	}
+	for i := len(c.ctes) - 1; i >= 0; i-- {
+		if c.ctes[i][table.Table.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *tableCollector) Enter(expr Expr) {
+	switch e := expr.(type) {
+	case *SelectQuery:
+		names := make(map[string]bool)
+		if e.With != nil {
+			for _, cte := range e.With.CTEs {
+				if _, ok := cte.Alias.(*SelectQuery); !ok {
+					continue
+				}
+				if name, ok := cte.Expr.(*Ident); ok {
+					names[name.Name] = true
+				}
+			}
+		}
+		c.ctes = append(c.ctes, names)
+	case *TableExpr:
+		c.enterTableExpr(e)
+	case *InsertStmt:
+		if table, ok := e.Table.(*TableIdentifier); ok {
+			c.add(table, TableRoleWrite)
+		}
+	case *DeleteClause:
+		c.add(e.Table, TableRoleWrite)
+	case *AlterTable:
+		c.add(e.TableIdentifier, alterTableRole(e))
+	case *AlterTableAttachPartition:
+		c.add(e.From, TableRoleRead)
+	case *AlterTableReplacePartition:
+		c.add(e.Table, TableRoleRead)
+	case *AlterTableModifyQuery:
+		c.definitions++
+	case *CreateTable:
+		c.enterCreateTable(e)
+	case *CreateView:
+		c.add(e.Name, TableRoleDDLTarget)
+		c.definitions++
+	case *CreateMaterializedView:
+		c.add(e.Name, TableRoleDDLTarget)
+		if e.Destination != nil {
+			c.add(e.Destination.TableIdentifier, TableRoleDependency)
+		}
+		for _, table := range e.DependsOn {
+			c.add(table, TableRoleDependency)
+		}
+		c.definitions++
+	case *CreateLiveView:
+		c.add(e.Name, TableRoleDDLTarget)
+		if e.Destination != nil {
+			c.add(e.Destination.TableIdentifier, TableRoleDependency)
+		}
+		c.definitions++
+	case *CreateDictionary:
+		c.add(e.Name, TableRoleDDLTarget)
+	case *DictionarySourceClause:
+		c.enterDictionarySource(e)
+	case *TableSchemaClause:
+		c.add(e.AliasTable, TableRoleDependency)
+	case *DropStmt:
+		c.add(e.Name, TableRoleDDLTarget)
+	case *TruncateTable:
+		c.add(e.Name, TableRoleDDLTarget)
+	case *RenameStmt:
+		if e.RenameTarget != KeywordDatabase {
+			for _, pair := range e.TargetPairList {
+				c.add(pair.Old, TableRoleDDLTarget)
+				c.add(pair.New, TableRoleDDLTarget)
+			}
+		}
+	case *OptimizeStmt:
+		c.add(e.Table, TableRoleDDLTarget)
+	case *CheckStmt:
+		c.add(e.Table, TableRoleRead)
+	case *SystemFlushExpr:
+		c.add(e.Distributed, TableRoleDDLTarget)
+	case *SystemSyncExpr:
+		c.add(e.Cluster, TableRoleDDLTarget)
+	case *SystemCtrlExpr:
+		c.add(e.Cluster, TableRoleDDLTarget)
+	case *SystemReloadExpr:
+		c.add(e.Dictionary, TableRoleDDLTarget)
+	case *GrantPrivilegeStmt:
+		c.add(e.On, TableRoleDDLTarget)
+	case *ShowStmt:
+		c.add(e.Target, TableRoleRead)
+	case *DescribeStmt:
+		if table, ok := e.Target.(*TableIdentifier); ok {
+			c.add(table, TableRoleRead)
+		}
+	}
+}
+
+func (c *tableCollector) Leave(expr Expr) {
+	switch expr.(type) {
+	case *SelectQuery:
+		c.ctes = c.ctes[:len(c.ctes)-1]
+	case *CreateView, *CreateMaterializedView, *CreateLiveView, *AlterTableModifyQuery:
+		c.definitions--
+	}
+}
+
+func (c *tableCollector) enterTableExpr(e *TableExpr) {
+	expr := e.Expr
+	alias := ""
+	if aliasExpr, ok := expr.(*AliasExpr); ok {
+		expr = aliasExpr.Expr
+		if ident, ok := aliasExpr.Alias.(*Ident); ok {
+			alias = ident.Name
+		}
+	}
+	table, ok := expr.(*TableIdentifier)
+	if !ok || table.Table == nil || c.isCTE(table) {
+		return
+	}
+	c.addAliased(table, alias, c.readRole())
+}
+
+func (c *tableCollector) enterCreateTable(e *CreateTable) {
+	c.add(e.Name, TableRoleDDLTarget)
+	if e.HasClone {
+		c.add(e.SourceTable, TableRoleRead)
+	} else {
+		c.add(e.SourceTable, TableRoleDependency)
+	}
+	if e.Engine == nil {
+		return
+	}
+	model, err := e.Engine.Model()
+	if err != nil {
+		return
+	}
+	switch engine := model.(type) {
+	case *DistributedEngine:
+		c.addNamed(e.Engine, engine.Database, engine.Table, TableRoleDependency)
+	case *BufferEngine:
+		c.addNamed(e.Engine, engine.Database, engine.Table, TableRoleDependency)
+	}
+}
+
+// enterDictionarySource records the table of a ClickHouse source. Sources
+// defined by a query are left out, as the query is an opaque string.
+func (c *tableCollector) enterDictionarySource(e *DictionarySourceClause) {
+	if e.Source == nil || !strings.EqualFold(e.Source.Name, "CLICKHOUSE") {
+		return
+	}
+	var database, table string
+	for _, arg := range e.Args {
+		if arg.Name == nil || arg.Value == nil {
+			continue
+		}
+		switch strings.ToUpper(arg.Name.Name) {
+		case "DB":
+			database = engineArgString(arg.Value)
+		case "TABLE":
+			table = engineArgString(arg.Value)
+		}
+	}
+	c.addNamed(e, database, table, TableRoleDependency)
+}
+
+// alterTableRole tells an ALTER TABLE that only mutates data, such as
+// ALTER TABLE t DELETE WHERE ..., from one that changes the table.
+func alterTableRole(e *AlterTable) TableRole {
+	if len(e.AlterExprs) == 0 {
+		return TableRoleDDLTarget
+	}
+	for _, clause := range e.AlterExprs {
+		switch clause.(type) {
+		case *AlterTableUpdate, *AlterTableDelete:
+		default:
+			return TableRoleDDLTarget
+		}
+	}
+	return TableRoleWrite
+}
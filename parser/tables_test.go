@@ -0,0 +1,180 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected []string
+	}{
+		{
+			name:     "select with aliases and joins",
+			sql:      "SELECT * FROM db.events AS e LEFT JOIN users u ON e.user_id = u.id",
+			expected: []string{"READ db.events AS e", "READ users AS u"},
+		},
+		{
+			name:     "CTEs and table functions are not tables",
+			sql:      "WITH recent AS (SELECT * FROM events WHERE ts > now() - 60) SELECT * FROM recent JOIN numbers(10) n ON 1 = 1",
+			expected: []string{"READ events"},
+		},
+		{
+			name:     "qualified name is not a CTE",
+			sql:      "WITH events AS (SELECT 1) SELECT * FROM db.events, events",
+			expected: []string{"READ db.events"},
+		},
+		{
+			name:     "CTE is only visible in its query",
+			sql:      "SELECT * FROM (WITH t AS (SELECT 1) SELECT * FROM t) s, t",
+			expected: []string{"READ t"},
+		},
+		{
+			name:     "subqueries in expressions",
+			sql:      "SELECT (SELECT max(id) FROM a) FROM b WHERE x IN (SELECT x FROM c) UNION ALL SELECT 1 FROM d",
+			expected: []string{"READ a", "READ b", "READ c", "READ d"},
+		},
+		{
+			name:     "insert select",
+			sql:      "INSERT INTO db.target (a) SELECT a FROM source",
+			expected: []string{"WRITE db.target", "READ source"},
+		},
+		{
+			name:     "insert into table function",
+			sql:      "INSERT INTO FUNCTION file('out.csv') SELECT * FROM source",
+			expected: []string{"READ source"},
+		},
+		{
+			name:     "delete",
+			sql:      "DELETE FROM events WHERE id IN (SELECT id FROM bad)",
+			expected: []string{"WRITE events", "READ bad"},
+		},
+		{
+			name:     "alter mutation",
+			sql:      "ALTER TABLE events UPDATE x = 1 WHERE id = 2",
+			expected: []string{"WRITE events"},
+		},
+		{
+			name:     "alter definition",
+			sql:      "ALTER TABLE events ADD COLUMN x UInt8",
+			expected: []string{"DDL_TARGET events"},
+		},
+		{
+			name:     "alter attach partition from",
+			sql:      "ALTER TABLE events ATTACH PARTITION 202401 FROM events_tmp",
+			expected: []string{"DDL_TARGET events", "READ events_tmp"},
+		},
+		{
+			name:     "create table as select",
+			sql:      "CREATE TABLE t ENGINE = MergeTree ORDER BY a AS SELECT a FROM s",
+			expected: []string{"DDL_TARGET t", "READ s"},
+		},
+		{
+			name:     "create table copying structure",
+			sql:      "CREATE TABLE t AS db.s ENGINE = MergeTree ORDER BY a",
+			expected: []string{"DDL_TARGET t", "DEPENDENCY db.s"},
+		},
+		{
+			name:     "distributed engine",
+			sql:      "CREATE TABLE t_all (a UInt8) ENGINE = Distributed(cluster, db, t_local, rand())",
+			expected: []string{"DDL_TARGET t_all", "DEPENDENCY db.t_local"},
+		},
+		{
+			name:     "view",
+			sql:      "CREATE VIEW v AS SELECT * FROM events JOIN users USING id",
+			expected: []string{"DDL_TARGET v", "DEPENDENCY events", "DEPENDENCY users"},
+		},
+		{
+			name:     "materialized view",
+			sql:      "CREATE MATERIALIZED VIEW mv TO db.dest AS SELECT * FROM src",
+			expected: []string{"DDL_TARGET mv", "DEPENDENCY db.dest", "DEPENDENCY src"},
+		},
+		{
+			name:     "dictionary source",
+			sql:      "CREATE DICTIONARY d (id UInt64) PRIMARY KEY id SOURCE(CLICKHOUSE(DB 'db' TABLE 'users')) LIFETIME(300) LAYOUT(FLAT())",
+			expected: []string{"DDL_TARGET d", "DEPENDENCY db.users"},
+		},
+		{
+			name:     "drop",
+			sql:      "DROP TABLE IF EXISTS db.events",
+			expected: []string{"DDL_TARGET db.events"},
+		},
+		{
+			name:     "truncate",
+			sql:      "TRUNCATE TABLE events",
+			expected: []string{"DDL_TARGET events"},
+		},
+		{
+			name:     "rename",
+			sql:      "RENAME TABLE a TO b, db.c TO db.d",
+			expected: []string{"DDL_TARGET a", "DDL_TARGET b", "DDL_TARGET db.c", "DDL_TARGET db.d"},
+		},
+		{
+			name:     "rename database",
+			sql:      "RENAME DATABASE a TO b",
+			expected: nil,
+		},
+		{
+			name:     "optimize",
+			sql:      "OPTIMIZE TABLE events FINAL",
+			expected: []string{"DDL_TARGET events"},
+		},
+		{
+			name:     "check",
+			sql:      "CHECK TABLE events",
+			expected: []string{"READ events"},
+		},
+		{
+			name:     "system",
+			sql:      "SYSTEM SYNC REPLICA db.events",
+			expected: []string{"DDL_TARGET db.events"},
+		},
+		{
+			name:     "grant",
+			sql:      "GRANT SELECT ON db.events TO john",
+			expected: []string{"DDL_TARGET db.events"},
+		},
+		{
+			name:     "grant on wildcard",
+			sql:      "GRANT SELECT ON db.* TO john",
+			expected: nil,
+		},
+		{
+			name:     "describe",
+			sql:      "DESCRIBE TABLE db.events",
+			expected: []string{"READ db.events"},
+		},
+		{
+			name:     "explain",
+			sql:      "EXPLAIN INSERT INTO t SELECT * FROM s",
+			expected: []string{"WRITE t", "READ s"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := NewParser(tt.sql).ParseStmts()
+			require.NoError(t, err)
+			require.Len(t, stmts, 1)
+			var actual []string
+			for _, ref := range TableReferences(stmts[0]) {
+				entry := string(ref.Role) + " " + ref.QualifiedName("")
+				if ref.Alias != "" {
+					entry += " AS " + ref.Alias
+				}
+				actual = append(actual, entry)
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestTableReferenceQualifiedName(t *testing.T) {
+	ref := TableReference{Table: "events"}
+	require.Equal(t, "events", ref.QualifiedName(""))
+	require.Equal(t, "default.events", ref.QualifiedName("default"))
+	ref.Database = "db"
+	require.Equal(t, "db.events", ref.QualifiedName("default"))
+}
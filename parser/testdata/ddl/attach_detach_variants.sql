@@ -0,0 +1,9 @@
+DETACH TABLE db.events PERMANENTLY;
+DETACH TABLE IF EXISTS db.events ON CLUSTER 'default' PERMANENTLY SYNC;
+DETACH VIEW db.events_view SYNC;
+DETACH DICTIONARY db.dict PERMANENTLY;
+DETACH DATABASE db PERMANENTLY;
+ATTACH TABLE db.events;
+ATTACH TABLE db.events FROM '/var/lib/clickhouse/user_files/events' (id UInt64, name String) ENGINE = MergeTree ORDER BY id;
+ATTACH DICTIONARY db.dict;
+ATTACH DICTIONARY IF NOT EXISTS db.dict ON CLUSTER 'default';
@@ -0,0 +1,9 @@
+-- Copy the structure of another table
+CREATE TABLE IF NOT EXISTS db.events_copy AS db.events;
+CREATE TABLE events_replica ON CLUSTER 'default' AS db.events ENGINE = ReplicatedMergeTree ORDER BY id;
+
+-- Copy the structure and the data
+CREATE TABLE events_clone CLONE AS db.events;
+
+-- Take the structure of a query without inserting its rows
+CREATE TABLE events_empty ENGINE = MergeTree ORDER BY id EMPTY AS SELECT id, name FROM db.events;
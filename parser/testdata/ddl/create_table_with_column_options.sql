@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS test.events
+(
+    `id` UInt64 PRIMARY KEY,
+    `unhexed` String EPHEMERAL,
+    `hexed` FixedString(4) DEFAULT unhex(unhexed),
+    `flag` UInt8 EPHEMERAL 1 COMMENT 'ephemeral with default',
+    `payload` String COMMENT 'raw payload' CODEC(ZSTD(1)) STATISTICS(tdigest, uniq) SETTINGS (max_compress_block_size = 1048576, min_compress_block_size = 65536),
+    `ts` DateTime CODEC(Delta, ZSTD(1)) TTL ts + INTERVAL 1 DAY
+)
+ENGINE = MergeTree
+ORDER BY id;
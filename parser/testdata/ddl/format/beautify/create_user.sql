@@ -95,7 +95,7 @@ HOST NONE;
 CREATE USER user15
 HOST NAME 'localhost';
 CREATE USER user16
-HOST REGEXP '.*\.example\.com';
+HOST REGEXP '.*\\.example\\.com';
 CREATE USER user17
 HOST IP '192.168.1.1';
 CREATE USER user18
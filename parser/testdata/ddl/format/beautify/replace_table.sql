@@ -0,0 +1,20 @@
+-- Origin SQL:
+REPLACE TABLE db.events ENGINE = MergeTree ORDER BY id AS SELECT id, name FROM db.events_staging;
+REPLACE TEMPORARY TABLE tmp (id UInt64) ENGINE = Memory;
+
+
+-- Beautify SQL:
+REPLACE TABLE db.events
+ENGINE = MergeTree
+ORDER BY
+  id
+AS SELECT
+  id,
+  name
+FROM
+  db.events_staging;
+REPLACE TEMPORARY TABLE tmp
+(
+  id UInt64
+)
+ENGINE = Memory;
@@ -11,4 +11,4 @@ ENGINE = Distributed(
 
 
 -- Format SQL:
-CREATE TABLE test.event_all ON CLUSTER 'default_cluster'  AS test.evnets_local ENGINE = Distributed(default_cluster, test, events_local, rand()) SETTINGS fsync_after_insert=0;
+CREATE TABLE test.event_all ON CLUSTER 'default_cluster' AS test.evnets_local ENGINE = Distributed(default_cluster, test, events_local, rand()) SETTINGS fsync_after_insert=0;
@@ -77,7 +77,7 @@ CREATE USER user12 HOST LOCAL;
 CREATE USER user13 HOST ANY;
 CREATE USER user14 HOST NONE;
 CREATE USER user15 HOST NAME 'localhost';
-CREATE USER user16 HOST REGEXP '.*\.example\.com';
+CREATE USER user16 HOST REGEXP '.*\\.example\\.com';
 CREATE USER user17 HOST IP '192.168.1.1';
 CREATE USER user18 HOST LIKE 'test%';
 CREATE USER user19 DEFAULT ROLE role1;
@@ -0,0 +1,12 @@
+-- Origin SQL:
+DESCRIBE (SELECT 1 AS a, 'x' AS b);
+DESC TABLE s3('https://bucket/data/*.parquet', 'Parquet');
+DESCRIBE TABLE db.events SETTINGS describe_include_subcolumns = 1;
+DESCRIBE file('data.csv', 'CSVWithNames') SETTINGS describe_include_subcolumns = 1, describe_extend_object_types = 1;
+
+
+-- Format SQL:
+DESCRIBE (SELECT 1 AS a, 'x' AS b);
+DESCRIBE TABLE s3('https://bucket/data/*.parquet', 'Parquet');
+DESCRIBE TABLE db.events SETTINGS describe_include_subcolumns=1;
+DESCRIBE file('data.csv', 'CSVWithNames') SETTINGS describe_include_subcolumns=1, describe_extend_object_types=1;
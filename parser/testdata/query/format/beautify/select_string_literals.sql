@@ -0,0 +1,29 @@
+-- Origin SQL:
+SELECT
+    'it''s' AS doubled_quote,
+    'tab\tnew\nline' AS control_escapes,
+    'a\\b' AS backslash,
+    $$heredoc with 'quotes' and \n$$ AS heredoc,
+    $tag$nested $$ inside$tag$ AS tagged_heredoc,
+    x'4142' AS hex_string,
+    X'4' AS short_hex,
+    b'0100000101000010' AS binary_string,
+    `back\`tick` AS quoted_ident,
+    "double""quote" AS double_quoted_ident
+FROM t;
+
+
+-- Beautify SQL:
+SELECT
+  'it\'s' AS doubled_quote,
+  'tab\tnew\nline' AS control_escapes,
+  'a\\b' AS backslash,
+  $$heredoc with 'quotes' and \n$$ AS heredoc,
+  $tag$nested $$ inside$tag$ AS tagged_heredoc,
+  x'4142' AS hex_string,
+  x'4' AS short_hex,
+  b'0100000101000010' AS binary_string,
+  `back\`tick` AS quoted_ident,
+  "double\"quote" AS double_quoted_ident
+FROM
+  t;
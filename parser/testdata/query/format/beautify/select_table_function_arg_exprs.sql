@@ -13,6 +13,8 @@ SELECT * FROM remote('127.0.0.1', (SELECT 1));
 SELECT * FROM numbers(CAST(1 + 1 AS UInt64));
 SELECT * FROM numbers(CAST('10', 'UInt64'));
 SELECT * FROM cluster('c', numbers(CAST(1 AS UInt64)));
+SELECT * FROM numbers(?);
+SELECT * FROM url(?, ?);
 
 
 -- Beautify SQL:
@@ -75,3 +77,11 @@ SELECT
   *
 FROM
   cluster('c', numbers(CAST(1 AS UInt64)));
+SELECT
+  *
+FROM
+  numbers(?);
+SELECT
+  *
+FROM
+  url(?, ?);
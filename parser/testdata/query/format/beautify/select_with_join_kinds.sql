@@ -0,0 +1,30 @@
+-- Origin SQL:
+SELECT *
+FROM t1
+LEFT ANY JOIN t2 ON t1.id = t2.id
+GLOBAL SEMI RIGHT JOIN t3 USING id
+ASOF LEFT JOIN t4 ON t1.id = t4.id AND t1.ts >= t4.ts
+PASTE JOIN t5
+CROSS JOIN t6
+LEFT ARRAY JOIN t1.tags AS tag;
+
+
+-- Beautify SQL:
+SELECT
+  *
+FROM
+  t1
+  ANY LEFT JOIN
+    t2 ON t1.id = t2.id
+  GLOBAL SEMI RIGHT JOIN
+    t3 USING id
+  ASOF LEFT JOIN
+    t4 ON t1.id = t4.id
+    AND
+      t1.ts >= t4.ts
+  PASTE JOIN
+    t5
+  CROSS JOIN
+    t6
+  LEFT ARRAY JOIN
+    t1.tags AS tag;
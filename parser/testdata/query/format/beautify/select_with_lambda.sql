@@ -0,0 +1,17 @@
+-- Origin SQL:
+SELECT
+    arrayMap(x -> x * 2, arr) AS doubled,
+    arrayFilter((k, v) -> v > 0 AND k != '', keys, vals) AS kept,
+    arrayMap(x -> arrayMap(y -> x + y, arr2), arr1) AS nested
+FROM t;
+
+
+-- Beautify SQL:
+SELECT
+  arrayMap(x -> x * 2, arr) AS doubled,
+  arrayFilter((k, v) -> v > 0
+  AND
+    k != '', keys, vals) AS kept,
+  arrayMap(x -> arrayMap(y -> x + y, arr2), arr1) AS nested
+FROM
+  t;
@@ -32,7 +32,7 @@ SELECT
 FROM
   test_table
 SETTINGS
-  additional_table_filters={'test_table': 'value = ''test'''};
+  additional_table_filters={'test_table': 'value = \'test\''};
 SELECT
   *
 FROM
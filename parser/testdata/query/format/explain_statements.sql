@@ -0,0 +1,18 @@
+-- Origin SQL:
+EXPLAIN SELECT 1;
+EXPLAIN PLAN header = 1, actions = 1 SELECT sum(number) FROM numbers(10);
+EXPLAIN QUERY TREE SELECT a FROM t WHERE b > 1;
+EXPLAIN indexes = 1 SELECT * FROM t WHERE a = 1;
+EXPLAIN AST INSERT INTO t (a) VALUES (1);
+EXPLAIN SYNTAX DESCRIBE (SELECT 1);
+EXPLAIN ESTIMATE SELECT count() FROM t;
+
+
+-- Format SQL:
+EXPLAIN SELECT 1;
+EXPLAIN PLAN header=1, actions=1 SELECT sum(number) FROM numbers(10);
+EXPLAIN QUERY TREE SELECT a FROM t WHERE b > 1;
+EXPLAIN indexes=1 SELECT * FROM t WHERE a = 1;
+EXPLAIN AST INSERT INTO t (a) VALUES (1);
+EXPLAIN SYNTAX DESCRIBE (SELECT 1);
+EXPLAIN ESTIMATE SELECT count() FROM t;
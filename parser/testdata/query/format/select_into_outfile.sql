@@ -0,0 +1,14 @@
+-- Origin SQL:
+SELECT * FROM events WHERE day = today() INTO OUTFILE 'events.csv' FORMAT CSV;
+SELECT * FROM events INTO OUTFILE 'events.tsv' APPEND;
+SELECT * FROM events INTO OUTFILE 'events.csv.gz' TRUNCATE COMPRESSION 'gzip' LEVEL 3 AND STDOUT FORMAT CSVWithNames;
+SELECT id FROM a UNION ALL SELECT id FROM b INTO OUTFILE 'ids.txt' AND STDOUT;
+(SELECT 1) INTO OUTFILE 'one.txt';
+
+
+-- Format SQL:
+SELECT * FROM events WHERE day = today() INTO OUTFILE 'events.csv' FORMAT CSV;
+SELECT * FROM events INTO OUTFILE 'events.tsv' APPEND;
+SELECT * FROM events INTO OUTFILE 'events.csv.gz' AND STDOUT TRUNCATE COMPRESSION 'gzip' LEVEL 3 FORMAT CSVWithNames;
+SELECT id FROM a UNION ALL SELECT id FROM b INTO OUTFILE 'ids.txt' AND STDOUT;
+(SELECT 1) INTO OUTFILE 'one.txt';
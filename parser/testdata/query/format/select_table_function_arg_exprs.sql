@@ -13,6 +13,8 @@ SELECT * FROM remote('127.0.0.1', (SELECT 1));
 SELECT * FROM numbers(CAST(1 + 1 AS UInt64));
 SELECT * FROM numbers(CAST('10', 'UInt64'));
 SELECT * FROM cluster('c', numbers(CAST(1 AS UInt64)));
+SELECT * FROM numbers(?);
+SELECT * FROM url(?, ?);
 
 
 -- Format SQL:
@@ -30,3 +32,5 @@ SELECT * FROM remote('127.0.0.1', (SELECT 1));
 SELECT * FROM numbers(CAST(1 + 1 AS UInt64));
 SELECT * FROM numbers(CAST('10', 'UInt64'));
 SELECT * FROM cluster('c', numbers(CAST(1 AS UInt64)));
+SELECT * FROM numbers(?);
+SELECT * FROM url(?, ?);
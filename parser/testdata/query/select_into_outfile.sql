@@ -0,0 +1,5 @@
+SELECT * FROM events WHERE day = today() INTO OUTFILE 'events.csv' FORMAT CSV;
+SELECT * FROM events INTO OUTFILE 'events.tsv' APPEND;
+SELECT * FROM events INTO OUTFILE 'events.csv.gz' TRUNCATE COMPRESSION 'gzip' LEVEL 3 AND STDOUT FORMAT CSVWithNames;
+SELECT id FROM a UNION ALL SELECT id FROM b INTO OUTFILE 'ids.txt' AND STDOUT;
+(SELECT 1) INTO OUTFILE 'one.txt';
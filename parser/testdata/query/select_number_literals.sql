@@ -0,0 +1,11 @@
+SELECT
+    1_000_000 AS separated,
+    0b1010 AS binary,
+    0X1F AS upper_hex,
+    0x1.8p3 AS hex_float,
+    1e-3 AS exponent,
+    inf AS positive_infinity,
+    -inf AS negative_infinity,
+    nan AS not_a_number
+FROM numbers(1)
+WHERE x IN (-1, 0xFF_FF, 1.5e1_0);
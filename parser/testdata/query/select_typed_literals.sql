@@ -0,0 +1,8 @@
+SELECT
+    DATE '2024-01-01' AS day,
+    TIMESTAMP '2024-01-01 00:00:00' + INTERVAL '2 hours 30 minutes' AS shifted,
+    INTERVAL '1 day' AS one_day,
+    INTERVAL '1' DAY AS unit_interval
+FROM events
+WHERE event_time >= now() - INTERVAL '7 DAYS'
+  AND event_date BETWEEN DATE '2024-01-01' AND DATE '2024-12-31';
@@ -0,0 +1,7 @@
+SELECT
+    имя,
+    sum(数量) AS 合計,
+    café.naïve
+FROM таблица AS café
+WHERE 名前 != ''
+GROUP BY имя, café.naïve;
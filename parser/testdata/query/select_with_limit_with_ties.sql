@@ -0,0 +1,5 @@
+SELECT name, score FROM players ORDER BY score DESC LIMIT 3 WITH TIES;
+SELECT name, score FROM players ORDER BY score DESC LIMIT 10, 3 WITH TIES;
+SELECT name, score FROM players ORDER BY score DESC OFFSET 10 ROWS FETCH NEXT 5 ROWS ONLY;
+SELECT name, score FROM players ORDER BY score DESC OFFSET 1 ROW FETCH FIRST 1 ROW WITH TIES SETTINGS max_threads = 1;
+SELECT name FROM players ORDER BY name OFFSET 20 ROWS;
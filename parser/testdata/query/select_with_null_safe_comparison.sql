@@ -0,0 +1,8 @@
+SELECT
+    a IS DISTINCT FROM b AS changed,
+    a IS NOT DISTINCT FROM b AS same,
+    a <=> NULL AS is_null
+FROM t
+WHERE price > ANY (SELECT price FROM discounts WHERE active)
+  AND qty <= ALL (SELECT max_qty FROM limits)
+  AND region = SOME (SELECT region FROM regions);
@@ -12,12 +12,13 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-// The package has two independent traversal engines: Accept/ASTVisitor and
-// Walk/WalkFunc. Each encodes every node's children separately, so a new AST
-// node type added to one can silently be forgotten in the other. This test
-// statically asserts that every type with an Accept method also has a Visit
-// method on the ASTVisitor interface and a case in Walk's type switch (and
-// vice versa), so the engines cannot drift.
+// The package has two independent traversal engines, Accept/ASTVisitor and
+// Walk/WalkFunc, and rewriteChildren, which follows Walk to rewrite trees.
+// Each encodes every node's children separately, so a new AST node type
+// added to one can silently be forgotten in the others. This test statically
+// asserts that every type with an Accept method also has a Visit method on
+// the ASTVisitor interface and a case in the type switches of Walk and
+// rewriteChildren (and vice versa), so the engines cannot drift.
 func TestTraversalEnginesCoverSameNodeTypes(t *testing.T) {
 	entries, err := os.ReadDir(".")
 	require.NoError(t, err)
@@ -26,6 +27,7 @@ func TestTraversalEnginesCoverSameNodeTypes(t *testing.T) {
 	acceptTypes := map[string]bool{}
 	visitorTypes := map[string]bool{}
 	walkTypes := map[string]bool{}
+	rewriteTypes := map[string]bool{}
 
 	for _, entry := range entries {
 		name := entry.Name()
@@ -44,7 +46,11 @@ func TestTraversalEnginesCoverSameNodeTypes(t *testing.T) {
 							acceptTypes[ident.Name] = true
 						}
 					}
-				case d.Name.Name == "Walk" && d.Recv == nil:
+				case (d.Name.Name == "Walk" || d.Name.Name == "rewriteChildren") && d.Recv == nil:
+					types := walkTypes
+					if d.Name.Name == "rewriteChildren" {
+						types = rewriteTypes
+					}
 					ast.Inspect(d.Body, func(n ast.Node) bool {
 						cc, ok := n.(*ast.CaseClause)
 						if !ok {
@@ -53,7 +59,7 @@ func TestTraversalEnginesCoverSameNodeTypes(t *testing.T) {
 						for _, expr := range cc.List {
 							if star, ok := expr.(*ast.StarExpr); ok {
 								if ident, ok := star.X.(*ast.Ident); ok {
-									walkTypes[ident.Name] = true
+									types[ident.Name] = true
 								}
 							}
 						}
@@ -89,6 +95,7 @@ func TestTraversalEnginesCoverSameNodeTypes(t *testing.T) {
 	require.NotEmpty(t, acceptTypes)
 	require.NotEmpty(t, visitorTypes)
 	require.NotEmpty(t, walkTypes)
+	require.NotEmpty(t, rewriteTypes)
 
 	require.Empty(t, diffSet(acceptTypes, visitorTypes),
 		"types with an Accept method but no ASTVisitor Visit method")
@@ -98,6 +105,10 @@ func TestTraversalEnginesCoverSameNodeTypes(t *testing.T) {
 		"types with an Accept method but no case in Walk's type switch")
 	require.Empty(t, diffSet(walkTypes, acceptTypes),
 		"types with a case in Walk's type switch but no Accept method")
+	require.Empty(t, diffSet(acceptTypes, rewriteTypes),
+		"types with an Accept method but no case in rewriteChildren's type switch")
+	require.Empty(t, diffSet(rewriteTypes, acceptTypes),
+		"types with a case in rewriteChildren's type switch but no Accept method")
 }
 
 // diffSet returns the members of a that are not in b, sorted.
@@ -138,7 +149,7 @@ func TestVisitJoinTableExprWithSampleRatio(t *testing.T) {
 
 // TestTraversalEnginesVisitSameFields statically asserts that, for every node
 // type, the set of child fields referenced by its Accept method matches the
-// set referenced by its case in Walk's type switch. The type-level test above
+// set referenced by its case in the type switches of Walk and rewriteChildren. The type-level test above
 // cannot catch a child field that one engine traverses and the other forgot
 // (e.g. Walk missing InsertStmt.Values while Accept visits it).
 func TestTraversalEnginesVisitSameFields(t *testing.T) {
@@ -148,6 +159,7 @@ func TestTraversalEnginesVisitSameFields(t *testing.T) {
 	fset := token.NewFileSet()
 	acceptFields := map[string]map[string]bool{}
 	walkFields := map[string]map[string]bool{}
+	rewriteFields := map[string]map[string]bool{}
 
 	// collectSelectors records every selector `<base>.<Field>` in node whose
 	// base is the identifier baseName, into out.
@@ -190,7 +202,11 @@ func TestTraversalEnginesVisitSameFields(t *testing.T) {
 				fields := map[string]bool{}
 				collectSelectors(d.Body, recvName, fields)
 				acceptFields[typeIdent.Name] = fields
-			case d.Name.Name == "Walk" && d.Recv == nil:
+			case (d.Name.Name == "Walk" || d.Name.Name == "rewriteChildren") && d.Recv == nil:
+				typeFields := walkFields
+				if d.Name.Name == "rewriteChildren" {
+					typeFields = rewriteFields
+				}
 				ast.Inspect(d.Body, func(n ast.Node) bool {
 					cc, ok := n.(*ast.CaseClause)
 					if !ok {
@@ -206,7 +222,7 @@ func TestTraversalEnginesVisitSameFields(t *testing.T) {
 							continue
 						}
 						if ident, ok := star.X.(*ast.Ident); ok {
-							walkFields[ident.Name] = fields
+							typeFields[ident.Name] = fields
 						}
 					}
 					return true
@@ -217,6 +233,7 @@ func TestTraversalEnginesVisitSameFields(t *testing.T) {
 
 	require.NotEmpty(t, acceptFields)
 	require.NotEmpty(t, walkFields)
+	require.NotEmpty(t, rewriteFields)
 
 	var problems []string
 	for typeName, aFields := range acceptFields {
@@ -232,9 +249,18 @@ func TestTraversalEnginesVisitSameFields(t *testing.T) {
 			problems = append(problems,
 				typeName+"."+field+" is traversed by Walk but not by Accept")
 		}
+		rFields := rewriteFields[typeName]
+		for _, field := range diffSet(aFields, rFields) {
+			problems = append(problems,
+				typeName+"."+field+" is traversed by Accept but not by rewriteChildren")
+		}
+		for _, field := range diffSet(rFields, aFields) {
+			problems = append(problems,
+				typeName+"."+field+" is traversed by rewriteChildren but not by Accept")
+		}
 	}
 	sort.Strings(problems)
-	require.Empty(t, problems, "child-field traversal drift between Accept and Walk")
+	require.Empty(t, problems, "child-field traversal drift between Accept, Walk and rewriteChildren")
 }
 
 // TestInsertStmtEndWithoutValues guards that End() does not panic on
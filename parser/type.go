@@ -1,3 +1,73 @@
 package parser
 
-var intervalUnits = NewSet("MILLISECOND", "SECOND", "MINUTE", "HOUR", "DAY", "WEEK", "MONTH", "QUARTER", "YEAR")
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var intervalUnits = NewSet("NANOSECOND", "MICROSECOND", "MILLISECOND", "SECOND", "MINUTE", "HOUR", "DAY", "WEEK", "MONTH", "QUARTER", "YEAR")
+
+// intervalUnitDurations holds the length of every interval unit that has a
+// fixed one; MONTH, QUARTER and YEAR depend on the calendar.
+var intervalUnitDurations = map[string]time.Duration{
+	"NANOSECOND":  time.Nanosecond,
+	"MICROSECOND": time.Microsecond,
+	"MILLISECOND": time.Millisecond,
+	"SECOND":      time.Second,
+	"MINUTE":      time.Minute,
+	"HOUR":        time.Hour,
+	"DAY":         24 * time.Hour,
+	"WEEK":        7 * 24 * time.Hour,
+}
+
+// parseIntervalParts splits the body of a string interval such as
+// '2 hours 30 minutes' into its parts. Units are case-insensitive and may be
+// plural.
+func parseIntervalParts(value string) ([]IntervalPart, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return nil, fmt.Errorf("invalid interval %q: expected <amount> <unit> pairs", value)
+	}
+	parts := make([]IntervalPart, 0, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		amount, err := strconv.ParseInt(fields[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q: %q is not an integer", value, fields[i])
+		}
+		unit := strings.ToUpper(fields[i+1])
+		if !intervalUnits.Contains(unit) {
+			unit = strings.TrimSuffix(unit, "S")
+		}
+		if !intervalUnits.Contains(unit) {
+			return nil, fmt.Errorf("invalid interval %q: unknown interval type %q", value, fields[i+1])
+		}
+		parts = append(parts, IntervalPart{Amount: amount, Unit: unit})
+	}
+	return parts, nil
+}
+
+// typedLiteralLayouts lists the accepted spellings of each typed literal,
+// most specific first.
+var typedLiteralLayouts = map[string][]string{
+	KeywordDate: {"2006-01-02"},
+	KeywordTimestamp: {
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02",
+	},
+}
+
+func parseTypedLiteralTime(typ, value string) (time.Time, error) {
+	layouts, ok := typedLiteralLayouts[typ]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown literal type %s", typ)
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid %s literal %q", typ, value)
+}
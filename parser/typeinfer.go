@@ -0,0 +1,842 @@
+package parser
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ColumnTypeProvider is a SchemaProvider that also knows column types.
+// Catalog implements it.
+type ColumnTypeProvider interface {
+	SchemaProvider
+	// ColumnType returns the type of a column, or nil if it is unknown.
+	ColumnType(database, table, column string) *DataType
+}
+
+// TypedColumn is an output column of a query.
+type TypedColumn struct {
+	Name string
+	// Type is nil when it could not be inferred.
+	Type *DataType
+}
+
+// InferTypes infers the ClickHouse result type of the expressions of a
+// SELECT, an INSERT ... SELECT, a view or a materialized view, or of a
+// single expression. The result maps every node whose type could be
+// inferred to its type; nodes that are not expressions, such as clauses,
+// and expressions of unknown type are left out.
+//
+// Column types come from schema when it is a ColumnTypeProvider; schema may
+// be nil. functions nil means DefaultFunctionTypes.
+func InferTypes(stmt Expr, schema SchemaProvider, functions FunctionTypes) map[Expr]*DataType {
+	inferrer := newTypeInferrer(schema, functions)
+	if query := definitionQuery(stmt); query != nil {
+		inferrer.query(query, nil)
+	} else if stmt != nil {
+		inferrer.infer(stmt, newScope[*DataType](nil))
+	}
+	return inferrer.types
+}
+
+// OutputColumns returns the names and types of the columns returned by a
+// SELECT, a view or a materialized view, as DESCRIBE would show them. It
+// fails when * expands over a table whose columns are unknown.
+func OutputColumns(stmt Expr, schema SchemaProvider, functions FunctionTypes) ([]TypedColumn, error) {
+	query := definitionQuery(stmt)
+	if query == nil {
+		return nil, fmt.Errorf("output columns are not supported for %T", stmt)
+	}
+	inferrer := newTypeInferrer(schema, functions)
+	columns := inferrer.query(query, nil)
+	if inferrer.err != nil {
+		return nil, inferrer.err
+	}
+	return columns, nil
+}
+
+// definitionQuery returns the query of a SELECT, INSERT ... SELECT or view.
+func definitionQuery(stmt Expr) *SelectQuery {
+	switch s := stmt.(type) {
+	case *SelectQuery:
+		return s
+	case *InsertStmt:
+		return s.SelectExpr
+	case *CreateView:
+		if s.SubQuery != nil {
+			return s.SubQuery.Select
+		}
+	case *CreateMaterializedView:
+		if s.SubQuery != nil {
+			return s.SubQuery.Select
+		}
+	case *CreateLiveView:
+		if s.SubQuery != nil {
+			return s.SubQuery.Select
+		}
+	}
+	return nil
+}
+
+type typeInferrer struct {
+	schema      SchemaProvider
+	columnTypes ColumnTypeProvider
+	functions   FunctionTypes
+	// lowerFunctions indexes functions by lower-cased name, for functions
+	// that ClickHouse matches case-insensitively.
+	lowerFunctions map[string]ReturnTypeFunc
+	types          map[Expr]*DataType
+	// err is the first * that could not be expanded.
+	err      error
+	resolver *resolver[*DataType]
+}
+
+func newTypeInferrer(schema SchemaProvider, functions FunctionTypes) *typeInferrer {
+	if functions == nil {
+		functions = DefaultFunctionTypes()
+	}
+	inferrer := &typeInferrer{
+		schema:         schema,
+		functions:      functions,
+		lowerFunctions: make(map[string]ReturnTypeFunc, len(functions)),
+		types:          make(map[Expr]*DataType),
+	}
+	inferrer.columnTypes, _ = schema.(ColumnTypeProvider)
+	inferrer.resolver = &resolver[*DataType]{analysis: inferrer}
+	for name, fn := range functions {
+		inferrer.lowerFunctions[strings.ToLower(name)] = fn
+	}
+	return inferrer
+}
+
+// typeScope holds the names visible in one SELECT or lambda.
+type typeScope = scope[*DataType]
+
+// typeRelation is a table, subquery or CTE in a FROM clause.
+type typeRelation = relation[*DataType]
+
+// query returns the output columns of a query. The columns of set
+// operations get the common type of their branches.
+func (i *typeInferrer) query(q *SelectQuery, parent *typeScope) []TypedColumn {
+	resolved := i.resolver.query(q, parent)
+	columns := make([]TypedColumn, len(resolved))
+	for j, column := range resolved {
+		columns[j] = TypedColumn{Name: column.name, Type: column.value}
+	}
+	return columns
+}
+
+func (i *typeInferrer) expr(expr Expr, scope *typeScope) *DataType {
+	return i.infer(expr, scope)
+}
+
+func (i *typeInferrer) tableColumns(table *TableIdentifier) []scopeColumn[*DataType] {
+	if i.schema == nil {
+		return nil
+	}
+	var database string
+	if table.Database != nil {
+		database = table.Database.Name
+	}
+	var columns []scopeColumn[*DataType]
+	for _, name := range i.schema.TableColumns(database, table.Table.Name) {
+		column := scopeColumn[*DataType]{name: name}
+		if i.columnTypes != nil {
+			column.value = i.columnTypes.ColumnType(database, table.Table.Name, name)
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// unknownColumn accepts any column of a table or table function whose
+// columns are unknown, without a type.
+func (i *typeInferrer) unknownColumn(relation *typeRelation, name string) (*DataType, bool) {
+	return nil, true
+}
+
+func (i *typeInferrer) unknownStar(relation *typeRelation) []scopeColumn[*DataType] {
+	if i.err == nil {
+		name := "subquery"
+		if relation.source != nil {
+			name = Format(relation.source)
+		}
+		i.err = fmt.Errorf("cannot expand * over %s: its columns are unknown", name)
+	}
+	return nil
+}
+
+func (i *typeInferrer) element(t *DataType) (*DataType, bool) {
+	if t != nil && t.Unwrap().Name == "Array" {
+		return t.Unwrap().Elem, true
+	}
+	return nil, false
+}
+
+func (i *typeInferrer) field(t *DataType, name string) *DataType {
+	return subcolumnType(t, name)
+}
+
+func (i *typeInferrer) merge(a, b *DataType) *DataType {
+	return commonType(a, b)
+}
+
+// clauses types the nodes of the clauses that do not change the output.
+func (i *typeInferrer) clauses(q *SelectQuery, scope *typeScope) {
+	var clauses []Expr
+	if q.From != nil {
+		clauses = joinConditions(q.From.Expr, clauses)
+	}
+	if q.Prewhere != nil {
+		clauses = append(clauses, q.Prewhere.Expr)
+	}
+	if q.Where != nil {
+		clauses = append(clauses, q.Where.Expr)
+	}
+	if q.GroupBy != nil {
+		clauses = append(clauses, q.GroupBy.Expr)
+	}
+	if q.Having != nil {
+		clauses = append(clauses, q.Having.Expr)
+	}
+	if q.OrderBy != nil {
+		for _, item := range q.OrderBy.Items {
+			if order, ok := item.(*OrderExpr); ok {
+				clauses = append(clauses, order.Expr)
+			}
+		}
+	}
+	for _, clause := range clauses {
+		i.infer(clause, scope)
+	}
+}
+
+// infer returns the type of an expression and records the types of it and
+// its subexpressions.
+func (i *typeInferrer) infer(expr Expr, scope *typeScope) *DataType {
+	if expr == nil {
+		return nil
+	}
+	t := i.inferNode(expr, scope)
+	if t != nil {
+		i.types[expr] = t
+	}
+	return t
+}
+
+func (i *typeInferrer) inferNode(expr Expr, scope *typeScope) *DataType {
+	switch e := expr.(type) {
+	case *ColumnExpr:
+		return i.infer(e.Expr, scope)
+	case *AliasExpr:
+		return i.infer(e.Expr, scope)
+	case *NumberLiteral:
+		return numberLiteralType(e)
+	case *StringLiteral:
+		return &DataType{Name: "String"}
+	case *TypedLiteral:
+		switch strings.ToUpper(e.Type.Name) {
+		case KeywordDate:
+			return &DataType{Name: "Date"}
+		case KeywordTimestamp:
+			return &DataType{Name: "DateTime"}
+		}
+		return nil
+	case *QueryParam:
+		t, _ := NewDataType(e.Type)
+		return t
+	case *TypedPlaceholder:
+		t, _ := NewDataType(e.Type)
+		return t
+	case *Ident:
+		switch strings.ToLower(e.Name) {
+		case "*":
+			return nil
+		case "null":
+			return &DataType{Name: "Nullable", Elem: &DataType{Name: "Nothing"}}
+		case "true", "false":
+			return &DataType{Name: "Bool"}
+		}
+		return i.column([]string{e.Name}, scope)
+	case *NestedIdentifier, *Path:
+		return i.column(identifierParts(e), scope)
+	case *ColumnExprList:
+		// the expressions of GROUP BY and PARTITION BY
+		for _, item := range e.Items {
+			i.infer(item, scope)
+		}
+		return nil
+	case *ParamExprList:
+		if e.Items == nil {
+			return nil
+		}
+		if len(e.Items.Items) == 1 {
+			return i.infer(e.Items.Items[0], scope)
+		}
+		tuple := &DataType{Name: "Tuple"}
+		for _, item := range e.Items.Items {
+			t := i.infer(item, scope)
+			if t == nil {
+				return nil
+			}
+			tuple.Fields = append(tuple.Fields, DataTypeField{Type: t})
+		}
+		return tuple
+	case *ArrayParamList:
+		var elems []*DataType
+		if e.Items != nil {
+			for _, item := range e.Items.Items {
+				elems = append(elems, i.infer(item, scope))
+			}
+		}
+		if len(elems) == 0 {
+			return &DataType{Name: "Array", Elem: &DataType{Name: "Nothing"}}
+		}
+		if elem := commonType(elems...); elem != nil {
+			return &DataType{Name: "Array", Elem: elem}
+		}
+		return nil
+	case *MapLiteral:
+		var values []*DataType
+		for _, kv := range e.KeyValues {
+			values = append(values, i.infer(kv.Value, scope))
+		}
+		if len(values) == 0 {
+			return &DataType{Name: "Map", Key: &DataType{Name: "Nothing"}, Value: &DataType{Name: "Nothing"}}
+		}
+		if value := commonType(values...); value != nil {
+			return &DataType{Name: "Map", Key: &DataType{Name: "String"}, Value: value}
+		}
+		return nil
+	case *IntervalExpr:
+		i.infer(e.Expr, scope)
+		if e.Unit == nil {
+			return nil
+		}
+		name := canonicalTypeName("Interval" + strings.TrimSuffix(strings.ToLower(e.Unit.Name), "s"))
+		if _, ok := dataTypeNames[strings.ToLower(name)]; !ok {
+			return nil
+		}
+		return &DataType{Name: name}
+	case *BinaryOperation:
+		if e.Operation == TokenKindDash {
+			i.infer(e.LeftExpr, scope)
+			t, _ := ParseDataType(Format(e.RightExpr))
+			return t
+		}
+		op := e.Operator()
+		if op == OperatorUnknown {
+			i.infer(e.LeftExpr, scope)
+			i.infer(e.RightExpr, scope)
+			return nil
+		}
+		return i.call(op.FunctionName(), []Expr{e.LeftExpr, e.RightExpr}, scope)
+	case *UnaryExpr:
+		if literal, ok := e.Expr.(*NumberLiteral); ok && e.Operator() == OperatorNegate {
+			return negativeLiteralType(literal)
+		}
+		if op := e.Operator(); op != OperatorUnknown {
+			return i.call(op.FunctionName(), []Expr{e.Expr}, scope)
+		}
+		return i.infer(e.Expr, scope)
+	case *NotExpr:
+		return i.call("not", []Expr{e.Expr}, scope)
+	case *NegateExpr:
+		if literal, ok := e.Expr.(*NumberLiteral); ok {
+			return negativeLiteralType(literal)
+		}
+		return i.call("negate", []Expr{e.Expr}, scope)
+	case *IsNullExpr:
+		i.infer(e.Expr, scope)
+		return &DataType{Name: "UInt8"}
+	case *IsNotNullExpr:
+		i.infer(e.Expr, scope)
+		return &DataType{Name: "UInt8"}
+	case *BetweenClause:
+		i.infer(e.Expr, scope)
+		i.infer(e.Between, scope)
+		i.infer(e.And, scope)
+		return &DataType{Name: "UInt8"}
+	case *DistinctFromExpr:
+		i.infer(e.LeftExpr, scope)
+		i.infer(e.RightExpr, scope)
+		return &DataType{Name: "UInt8"}
+	case *TernaryOperation:
+		return i.call("if", []Expr{e.Condition, e.TrueExpr, e.FalseExpr}, scope)
+	case *CaseExpr:
+		i.infer(e.Expr, scope)
+		var results []*DataType
+		for _, when := range e.Whens {
+			i.infer(when.When, scope)
+			results = append(results, i.infer(when.Then, scope))
+		}
+		if e.Else != nil {
+			results = append(results, i.infer(e.Else, scope))
+		} else {
+			results = append(results, &DataType{Name: "Nullable", Elem: &DataType{Name: "Nothing"}})
+		}
+		return commonType(results...)
+	case *CastExpr:
+		i.infer(e.Expr, scope)
+		switch typ := e.AsType.(type) {
+		case ColumnType:
+			t, _ := NewDataType(typ)
+			return t
+		case *StringLiteral:
+			t, _ := ParseDataType(typ.Value())
+			return t
+		}
+		return nil
+	case *FunctionExpr:
+		return i.function(e, scope)
+	case *WindowFunctionExpr:
+		if window, ok := e.OverExpr.(*WindowExpr); ok {
+			// the columns partitioning and ordering the window are typed too
+			if window.PartitionBy != nil {
+				i.infer(window.PartitionBy.Expr, scope)
+			}
+			if window.OrderBy != nil {
+				for _, item := range window.OrderBy.Items {
+					if order, ok := item.(*OrderExpr); ok {
+						i.infer(order.Expr, scope)
+					}
+				}
+			}
+		}
+		return i.infer(e.Function, scope)
+	case *ObjectParams:
+		object := i.infer(e.Object, scope)
+		if e.Params != nil && e.Params.Items != nil {
+			for _, item := range e.Params.Items.Items {
+				i.infer(item, scope)
+			}
+		}
+		if object == nil {
+			return nil
+		}
+		switch unwrapped := object.Unwrap(); unwrapped.Name {
+		case "Array":
+			return unwrapped.Elem
+		case "Map":
+			return unwrapped.Value
+		}
+		return nil
+	case *IndexOperation:
+		object := i.infer(e.Object, scope)
+		if object == nil {
+			return nil
+		}
+		switch index := e.Index.(type) {
+		case *NumberLiteral:
+			if n, ok := index.Value().(int64); ok && object.Name == "Tuple" && n >= 1 && int(n) <= len(object.Fields) {
+				return object.Fields[n-1].Type
+			}
+		case *Ident:
+			return subcolumnType(object, index.Name)
+		}
+		return nil
+	case *ExtractExpr:
+		var unit string
+		for _, param := range e.Parameters {
+			if from, ok := param.(*IntervalFrom); ok {
+				unit = from.Interval.Name
+				i.infer(from.FromExpr, scope)
+			}
+		}
+		if strings.EqualFold(unit, KeywordYear) {
+			return &DataType{Name: "UInt16"}
+		}
+		if unit != "" {
+			return &DataType{Name: "UInt8"}
+		}
+		return nil
+	case *SubQuery:
+		if e.Select == nil {
+			return nil
+		}
+		return i.infer(e.Select, scope)
+	case *SelectQuery:
+		// a scalar subquery has the type of its single column
+		if columns := i.query(e, scope); len(columns) == 1 {
+			return columns[0].Type
+		}
+		return nil
+	}
+	return nil
+}
+
+// joinConditions appends the ON conditions of the joins of a FROM clause.
+func joinConditions(expr Expr, conditions []Expr) []Expr {
+	join, ok := expr.(*JoinExpr)
+	if !ok {
+		return conditions
+	}
+	conditions = joinConditions(join.Left, conditions)
+	conditions = joinConditions(join.Right, conditions)
+	if on, ok := join.Constraints.(*OnClause); ok {
+		conditions = append(conditions, on.On)
+	}
+	return conditions
+}
+
+// column resolves a column reference given as its dotted parts.
+func (i *typeInferrer) column(parts []string, scope *typeScope) *DataType {
+	return i.resolver.column(parts, scope)
+}
+
+// subcolumnType returns the type of a subcolumn such as tuple.field,
+// map.keys or nullable.null, or nil.
+func subcolumnType(t *DataType, name string) *DataType {
+	if t == nil {
+		return nil
+	}
+	if t.Name == "Nullable" && name == "null" {
+		return &DataType{Name: "UInt8"}
+	}
+	t = t.Unwrap()
+	switch t.Name {
+	case "Tuple":
+		for _, field := range t.Fields {
+			if field.Name == name {
+				return field.Type
+			}
+		}
+	case "Map":
+		switch name {
+		case "keys":
+			return &DataType{Name: "Array", Elem: t.Key}
+		case "values":
+			return &DataType{Name: "Array", Elem: t.Value}
+		}
+	case "Array":
+		if name == "size0" {
+			return &DataType{Name: "UInt64"}
+		}
+	}
+	return nil
+}
+
+func (i *typeInferrer) function(f *FunctionExpr, scope *typeScope) *DataType {
+	var args []Expr
+	if f.Params != nil {
+		if f.Params.ColumnArgList != nil {
+			// the parameters of a parametric aggregate such as quantile(0.9)(x)
+			if f.Params.Items != nil {
+				for _, param := range f.Params.Items.Items {
+					i.infer(param, scope)
+				}
+			}
+			args = f.Params.ColumnArgList.Items
+		} else if f.Params.Items != nil {
+			args = f.Params.Items.Items
+		}
+	}
+	return i.call(f.Name.Name, args, scope)
+}
+
+func (i *typeInferrer) call(name string, exprs []Expr, scope *typeScope) *DataType {
+	args := make([]FunctionArg, len(exprs))
+	var arrays []*DataType
+	for j, expr := range exprs {
+		args[j].Expr = expr
+		if _, ok := unwrapColumnExpr(expr).(*LambdaExpr); ok {
+			continue
+		}
+		args[j].Type = i.infer(expr, scope)
+		if t := args[j].Type; t != nil && t.Unwrap().Name == "Array" {
+			arrays = append(arrays, t.Unwrap().Elem)
+		} else {
+			arrays = append(arrays, nil)
+		}
+	}
+	// a lambda takes the elements of the arrays that follow it
+	for j, expr := range exprs {
+		lambda, ok := unwrapColumnExpr(expr).(*LambdaExpr)
+		if !ok {
+			continue
+		}
+		lambdaScope := newScope[*DataType](scope)
+		for k, param := range lambda.Params {
+			if k < len(arrays) {
+				lambdaScope.params[param.Name] = arrays[k]
+			}
+		}
+		args[j].Type = i.infer(lambda.Body, lambdaScope)
+	}
+	fn := i.lookupFunction(name)
+	if fn == nil {
+		return nil
+	}
+	return fn(args)
+}
+
+// aggregateCombinators are the suffixes that adapt an aggregate function,
+// longest first.
+var aggregateCombinators = []string{
+	"SimpleState", "OrDefault", "Distinct", "ForEach", "OrNull", "State", "Merge", "Array", "If",
+}
+
+// lookupFunction finds the type function of name, which may carry
+// aggregate combinators such as sumIf or uniqMerge.
+func (i *typeInferrer) lookupFunction(name string) ReturnTypeFunc {
+	if fn, ok := i.functions[name]; ok {
+		return fn
+	}
+	if fn, ok := i.lowerFunctions[strings.ToLower(name)]; ok {
+		return fn
+	}
+	for _, combinator := range aggregateCombinators {
+		base, ok := strings.CutSuffix(name, combinator)
+		if !ok || base == "" {
+			continue
+		}
+		fn := i.lookupFunction(base)
+		if fn == nil {
+			continue
+		}
+		return combine(base, combinator, fn)
+	}
+	return nil
+}
+
+func combine(base, combinator string, fn ReturnTypeFunc) ReturnTypeFunc {
+	switch combinator {
+	case "If":
+		return func(args []FunctionArg) *DataType {
+			if len(args) == 0 {
+				return nil
+			}
+			return fn(args[:len(args)-1])
+		}
+	case "Array", "ForEach":
+		return func(args []FunctionArg) *DataType {
+			elems := make([]FunctionArg, len(args))
+			for j, arg := range args {
+				if arg.Type == nil || arg.Type.Unwrap().Name != "Array" {
+					return nil
+				}
+				elems[j] = FunctionArg{Expr: arg.Expr, Type: arg.Type.Unwrap().Elem}
+			}
+			t := fn(elems)
+			if t == nil || combinator == "Array" {
+				return t
+			}
+			return &DataType{Name: "Array", Elem: t}
+		}
+	case "OrNull":
+		return func(args []FunctionArg) *DataType {
+			return nullableType(fn(args))
+		}
+	case "State":
+		return func(args []FunctionArg) *DataType {
+			state := &DataType{Name: "AggregateFunction", Args: []*DataType{{Name: base}}}
+			for _, arg := range args {
+				if arg.Type == nil {
+					return nil
+				}
+				state.Args = append(state.Args, arg.Type)
+			}
+			return state
+		}
+	case "SimpleState":
+		return func(args []FunctionArg) *DataType {
+			t := fn(args)
+			if t == nil {
+				return nil
+			}
+			return &DataType{Name: "SimpleAggregateFunction", Args: []*DataType{{Name: base}, t}}
+		}
+	case "Merge":
+		return func(args []FunctionArg) *DataType {
+			if len(args) != 1 || args[0].Type == nil || args[0].Type.Name != "AggregateFunction" || len(args[0].Type.Args) == 0 {
+				return nil
+			}
+			var states []FunctionArg
+			for _, t := range args[0].Type.Args[1:] {
+				states = append(states, FunctionArg{Type: t})
+			}
+			return fn(states)
+		}
+	}
+	// Distinct and OrDefault keep the result type
+	return fn
+}
+
+// numberLiteralType returns the smallest type that holds a literal, as
+// ClickHouse does: 1 is UInt8, -1 is Int8 and 1.5 is Float64.
+func numberLiteralType(n *NumberLiteral) *DataType {
+	switch v := n.Value().(type) {
+	case int64:
+		return &DataType{Name: smallestIntegerType(v)}
+	case uint64:
+		return &DataType{Name: "UInt64"}
+	case *big.Int:
+		for _, name := range []string{"UInt128", "Int128", "UInt256", "Int256"} {
+			info := integerTypes[name]
+			bits := info.bits
+			if info.signed {
+				bits--
+			}
+			if v.Sign() >= 0 || info.signed {
+				if v.BitLen() <= bits {
+					return &DataType{Name: name}
+				}
+			}
+		}
+		return nil
+	case float64:
+		return &DataType{Name: "Float64"}
+	}
+	return nil
+}
+
+// negativeLiteralType returns the type of a negated literal, which
+// ClickHouse folds into a constant: -1 is Int8, not negate(UInt8).
+func negativeLiteralType(n *NumberLiteral) *DataType {
+	switch v := n.Value().(type) {
+	case int64:
+		return &DataType{Name: smallestIntegerType(-v)}
+	case uint64:
+		if v <= 1<<63 {
+			return &DataType{Name: "Int64"}
+		}
+		return &DataType{Name: "Int128"}
+	case float64:
+		return &DataType{Name: "Float64"}
+	}
+	return nil
+}
+
+func smallestIntegerType(v int64) string {
+	if v >= 0 {
+		switch {
+		case v <= 1<<8-1:
+			return "UInt8"
+		case v <= 1<<16-1:
+			return "UInt16"
+		case v <= 1<<32-1:
+			return "UInt32"
+		}
+		return "UInt64"
+	}
+	switch {
+	case v >= -1<<7:
+		return "Int8"
+	case v >= -1<<15:
+		return "Int16"
+	case v >= -1<<31:
+		return "Int32"
+	}
+	return "Int64"
+}
+
+// nullableType wraps a type in Nullable, inside LowCardinality, unless it
+// already admits NULL or cannot be inside Nullable.
+func nullableType(t *DataType) *DataType {
+	if t == nil || t.IsNullable() {
+		return t
+	}
+	switch t.Name {
+	case "LowCardinality":
+		return &DataType{Name: "LowCardinality", Elem: nullableType(t.Elem)}
+	case "Array", "Map", "Tuple", "Nested", "AggregateFunction", "SimpleAggregateFunction", "Dynamic", "Variant", "JSON", "Object":
+		return t
+	}
+	return &DataType{Name: "Nullable", Elem: t}
+}
+
+// commonType returns the type that all of types convert to, as the
+// branches of CASE or UNION ALL do, or nil if there is none or a type is
+// unknown. NULL makes the result Nullable.
+func commonType(types ...*DataType) *DataType {
+	nullable := false
+	var bare []*DataType
+	for _, t := range types {
+		if t == nil {
+			return nil
+		}
+		if t.IsNullable() {
+			nullable = true
+		}
+		if t = t.Unwrap(); t.Name != "Nothing" {
+			bare = append(bare, t)
+		}
+	}
+	if len(bare) == 0 {
+		return &DataType{Name: "Nullable", Elem: &DataType{Name: "Nothing"}}
+	}
+	result := bare[0]
+	for _, t := range bare[1:] {
+		if result = commonTypeOf(result, t); result == nil {
+			return nil
+		}
+	}
+	if nullable {
+		return nullableType(result)
+	}
+	return result
+}
+
+func commonTypeOf(a, b *DataType) *DataType {
+	switch {
+	case a.Equal(b):
+		return a
+	case a.IsInteger() && b.IsInteger():
+		return commonIntegerType(a, b)
+	case a.IsNumeric() && b.IsNumeric():
+		if a.Name == "Decimal" || b.Name == "Decimal" {
+			if a.Name != "Decimal" || b.Name != "Decimal" {
+				return nil
+			}
+			scale := max(a.Scale, b.Scale)
+			precision := min(max(a.Precision-a.Scale, b.Precision-b.Scale)+scale, 76)
+			return &DataType{Name: "Decimal", Precision: precision, Scale: scale}
+		}
+		return &DataType{Name: "Float64"}
+	case a.IsString() && b.IsString():
+		return &DataType{Name: "String"}
+	case a.IsDateOrTime() && b.IsDateOrTime():
+		if a.AssignableTo(b) {
+			return b
+		}
+		if b.AssignableTo(a) {
+			return a
+		}
+		return nil
+	case a.Name == "Array" && b.Name == "Array":
+		if elem := commonType(a.Elem, b.Elem); elem != nil {
+			return &DataType{Name: "Array", Elem: elem}
+		}
+	}
+	return nil
+}
+
+// commonIntegerType returns the smallest integer type holding the values
+// of both, or nil for UInt64 and a signed type.
+func commonIntegerType(a, b *DataType) *DataType {
+	ai, bi := integerTypes[a.Name], integerTypes[b.Name]
+	if ai.signed == bi.signed {
+		if ai.bits >= bi.bits {
+			return a
+		}
+		return b
+	}
+	signed, unsigned := ai, bi
+	if bi.signed {
+		signed, unsigned = bi, ai
+	}
+	bits := max(signed.bits, unsigned.bits*2)
+	if unsigned.bits >= 64 && bits > 64 {
+		return nil
+	}
+	return &DataType{Name: integerTypeName(bits, true)}
+}
+
+func integerTypeName(bits int, signed bool) string {
+	for name, info := range integerTypes {
+		if info.bits == bits && info.signed == signed {
+			return name
+		}
+	}
+	return ""
+}
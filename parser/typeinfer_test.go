@@ -0,0 +1,225 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const typeSchemaDDL = `
+CREATE TABLE events (
+	id UInt64, user_id UInt32, ts DateTime, amount Nullable(Float32), score Int16,
+	country LowCardinality(String), tags Array(String), attrs Map(String, UInt8),
+	point Tuple(x Float64, y Float64), n Nested(key String, value UInt16),
+	state AggregateFunction(uniq, UInt64), price Decimal(10, 2), rate Decimal32(4)
+) ENGINE = MergeTree ORDER BY id;
+CREATE TABLE users (id UInt64, name String, age Nullable(UInt8)) ENGINE = MergeTree ORDER BY id;
+`
+
+func TestInferTypes(t *testing.T) {
+	schema := catalogOf(t, typeSchemaDDL)
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"1", "UInt8"},
+		{"-1", "Int8"},
+		{"300", "UInt16"},
+		{"1.5", "Float64"},
+		{"'a'", "String"},
+		{"NULL", "Nullable(Nothing)"},
+		{"true", "Bool"},
+		{"DATE '2024-01-01'", "Date"},
+		{"[1, 2, -3]", "Array(Int16)"},
+		{"[]", "Array(Nothing)"},
+		{"[1, NULL]", "Array(Nullable(UInt8))"},
+		{"(1, 'a')", "Tuple(UInt8, String)"},
+		{"{'a': 1, 'b': 1000}", "Map(String, UInt16)"},
+		{"INTERVAL 1 DAY", "IntervalDay"},
+		{"{p: Date32}", "Date32"},
+		{"id", "UInt64"},
+		{"e.ts", "DateTime"},
+		{"n.key", "Array(String)"},
+		{"point.x", "Float64"},
+		{"tags[1]", "String"},
+		{"attrs['k']", "UInt8"},
+		{"point.1", "Float64"},
+		{"user_id + 1", "UInt64"},
+		{"score - user_id", "Int64"},
+		{"score * 2", "Int32"},
+		{"id / 2", "Float64"},
+		{"amount + 1", "Nullable(Float64)"},
+		{"price + 1", "Decimal(18, 2)"},
+		{"rate * 2", "Decimal(9, 4)"},
+		{"rate - id", "Decimal(9, 4)"},
+		{"price + rate", "Decimal(18, 4)"},
+		{"price * rate", "Decimal(18, 6)"},
+		{"price / rate", "Decimal(18, 2)"},
+		{"rate / price", "Decimal(18, 4)"},
+		{"price / 2", "Decimal(18, 2)"},
+		{"1 / rate", "Decimal(9, 4)"},
+		{"price * 1.5", "Float64"},
+		{"ts + INTERVAL 1 HOUR", "DateTime"},
+		{"-user_id", "Int64"},
+		{"abs(score)", "UInt16"},
+		{"abs(toNullable(score))", "Nullable(UInt16)"},
+		{"abs(user_id)", "UInt32"},
+		{"abs(price)", "Decimal(10, 2)"},
+		{"id > 1 AND NOT score = 1", "UInt8"},
+		{"amount IS NULL", "UInt8"},
+		{"id BETWEEN 1 AND 2", "UInt8"},
+		{"id IN (1, 2)", "UInt8"},
+		{"country || 'x'", "String"},
+		{"id::String", "String"},
+		{"CAST(id AS Nullable(String))", "Nullable(String)"},
+		{"CAST(id, 'Decimal(10, 2)')", "Decimal(10, 2)"},
+		{"id > 1 ? score : -1", "Int16"},
+		{"CASE WHEN id > 1 THEN user_id ELSE score END", "Int64"},
+		{"CASE id WHEN 1 THEN 'a' END", "Nullable(String)"},
+		{"EXTRACT(YEAR FROM ts)", "UInt16"},
+		{"upper(country)", "String"},
+		{"toDate(ts)", "Date"},
+		{"toDecimal32(id, 2)", "Decimal(9, 2)"},
+		{"toInt32OrNull('1')", "Nullable(Int32)"},
+		{"toDateTime(ts, 'UTC')", "DateTime('UTC')"},
+		{"coalesce(NULL, user_id)", "UInt32"},
+		{"ifNull(amount, 0)", "Float64"},
+		{"multiIf(id = 1, 'a', id = 2, 'b', 'c')", "String"},
+		{"arrayMap(x -> length(x), tags)", "Array(UInt64)"},
+		{"arrayFilter(x -> x != '', tags)", "Array(String)"},
+		{"tuple(1, 'a').2", "String"},
+		{"count()", "UInt64"},
+		{"sum(user_id)", "UInt64"},
+		{"sum(score)", "Int64"},
+		{"sum(amount)", "Nullable(Float64)"},
+		{"avg(id)", "Float64"},
+		{"max(ts)", "DateTime"},
+		{"countIf(id > 1)", "UInt64"},
+		{"sumIf(score, id > 1)", "Int64"},
+		{"uniqState(id)", "AggregateFunction(uniq, UInt64)"},
+		{"uniqMerge(state)", "UInt64"},
+		{"sumOrNull(id)", "Nullable(UInt64)"},
+		{"groupArray(country)", "Array(LowCardinality(String))"},
+		{"quantile(0.9)(score)", "Float64"},
+		{"quantiles(0.5, 0.9)(score)", "Array(Float64)"},
+		{"row_number() OVER (ORDER BY id)", "UInt64"},
+		{"COUNT(*)", "UInt64"},
+		{"(SELECT max(age) FROM users)", "Nullable(UInt8)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			stmt := parseOneStmt(t, "SELECT "+tt.expr+" FROM events AS e")
+			columns, err := OutputColumns(stmt, schema, nil)
+			require.NoError(t, err)
+			require.Len(t, columns, 1)
+			require.NotNil(t, columns[0].Type, "type of %s is unknown", tt.expr)
+			require.Equal(t, tt.expected, columns[0].Type.String())
+		})
+	}
+}
+
+func TestInferTypesUnknown(t *testing.T) {
+	schema := catalogOf(t, typeSchemaDDL)
+	for _, expr := range []string{"missing", "unknownFunction(id)", "id + 'a'", "[1, 'a']", "rate * rate * rate"} {
+		columns, err := OutputColumns(parseOneStmt(t, "SELECT "+expr+" FROM events"), schema, nil)
+		require.NoError(t, err)
+		require.Nil(t, columns[0].Type, expr)
+	}
+}
+
+func TestInferTypesNodes(t *testing.T) {
+	schema := catalogOf(t, typeSchemaDDL)
+	stmt := parseOneStmt(t, "SELECT id FROM events WHERE toDate(ts) = today() AND score > 1")
+	types := InferTypes(stmt, schema, nil)
+
+	where := stmt.(*SelectQuery).Where.Expr.(*BinaryOperation)
+	require.Equal(t, "UInt8", types[where].String())
+	equals := where.LeftExpr.(*BinaryOperation)
+	require.Equal(t, "Date", types[equals.LeftExpr].String())
+	require.Equal(t, "Date", types[equals.RightExpr].String())
+	require.Equal(t, "Int16", types[where.RightExpr.(*BinaryOperation).LeftExpr].String())
+
+	stmt = parseOneStmt(t, "SELECT sum(score) OVER (PARTITION BY toDate(ts) ORDER BY id) FROM events")
+	window := InferTypes(stmt, schema, nil)
+	over := stmt.(*SelectQuery).SelectItems[0].Expr.(*WindowFunctionExpr).OverExpr.(*WindowExpr)
+	require.Equal(t, "Date", window[over.PartitionBy.Expr.(*ColumnExprList).Items[0]].String())
+
+	expr := parseOneStmt(t, "SELECT toString(1) || 'a'").(*SelectQuery).SelectItems[0].Expr
+	require.Equal(t, "String", InferTypes(expr, nil, nil)[expr].String())
+}
+
+func TestOutputColumns(t *testing.T) {
+	schema := catalogOf(t, typeSchemaDDL)
+	describe := func(sql string) []string {
+		columns, err := OutputColumns(parseOneStmt(t, sql), schema, nil)
+		require.NoError(t, err)
+		var described []string
+		for _, column := range columns {
+			typ := "?"
+			if column.Type != nil {
+				typ = column.Type.String()
+			}
+			described = append(described, column.Name+" "+typ)
+		}
+		return described
+	}
+
+	require.Equal(t, []string{"id UInt64", "name String", "age Nullable(UInt8)"}, describe("SELECT * FROM users"))
+	require.Equal(t,
+		[]string{"id UInt64", "name String", "age Nullable(String)", "id UInt64"},
+		describe("SELECT * REPLACE (toString(age) AS age), u.id FROM users u"),
+	)
+	require.Equal(t,
+		[]string{"day Date", "users UInt64", "total Nullable(Float64)"},
+		describe("CREATE MATERIALIZED VIEW mv ENGINE = SummingMergeTree ORDER BY day AS "+
+			"SELECT toDate(ts) AS day, uniq(user_id) AS users, sum(amount) AS total FROM events GROUP BY day"),
+	)
+	require.Equal(t,
+		[]string{"id UInt64", "n UInt64"},
+		describe("WITH t AS (SELECT id, count() AS n FROM events GROUP BY id) SELECT id, n FROM t"),
+	)
+	require.Equal(t,
+		[]string{"tag String", "k String"},
+		describe("SELECT tag, n.key AS k FROM events ARRAY JOIN tags AS tag, n"),
+	)
+	require.Equal(t,
+		[]string{"tags String", "m.value UInt16"},
+		describe("SELECT tags, m.value FROM events ARRAY JOIN tags, n AS m"),
+	)
+	require.Equal(t,
+		[]string{"x Int16"},
+		describe("SELECT 1 AS x UNION ALL SELECT -300"),
+	)
+	require.Equal(t,
+		[]string{"y Nullable(UInt8)"},
+		describe("SELECT y FROM (SELECT age AS y FROM users)"),
+	)
+
+	_, err := OutputColumns(parseOneStmt(t, "SELECT * FROM unknown_table"), schema, nil)
+	require.Error(t, err)
+	_, err = OutputColumns(parseOneStmt(t, "DROP TABLE users"), schema, nil)
+	require.Error(t, err)
+}
+
+func TestInferTypesCustomFunctions(t *testing.T) {
+	functions := DefaultFunctionTypes()
+	functions["myUDF"] = fixedType("IPv4")
+	columns, err := OutputColumns(parseOneStmt(t, "SELECT myUDF(1), myUDFIf(1, 1)"), nil, functions)
+	require.NoError(t, err)
+	require.Equal(t, "IPv4", columns[0].Type.String())
+	require.Equal(t, "IPv4", columns[1].Type.String())
+	_, ok := DefaultFunctionTypes()["myUDF"]
+	require.False(t, ok)
+}
+
+func TestCatalogViewColumnTypes(t *testing.T) {
+	catalog := catalogOf(t, typeSchemaDDL+`
+CREATE VIEW v AS SELECT id, toDate(ts) AS day, n.value FROM events;
+`)
+	require.Equal(t, "Date", catalog.ColumnType("", "v", "day").String())
+	require.Equal(t, "Array(UInt16)", catalog.ColumnType("", "v", "n.value").String())
+	require.Nil(t, catalog.ColumnType("", "v", "missing"))
+	columns, err := OutputColumns(parseOneStmt(t, "SELECT day + 1 FROM v"), catalog, nil)
+	require.NoError(t, err)
+	require.Equal(t, "Date", columns[0].Type.String())
+}
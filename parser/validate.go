@@ -0,0 +1,349 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DiagnosticKind identifies the problem a Diagnostic reports.
+type DiagnosticKind string
+
+const (
+	DiagnosticUnknownDatabase     DiagnosticKind = "UNKNOWN_DATABASE"
+	DiagnosticUnknownTable        DiagnosticKind = "UNKNOWN_TABLE"
+	DiagnosticUnknownColumn       DiagnosticKind = "UNKNOWN_COLUMN"
+	DiagnosticAmbiguousColumn     DiagnosticKind = "AMBIGUOUS_COLUMN"
+	DiagnosticColumnCountMismatch DiagnosticKind = "COLUMN_COUNT_MISMATCH"
+
+	DiagnosticUnknownFunction       DiagnosticKind = "UNKNOWN_FUNCTION"
+	DiagnosticUnknownTableFunction  DiagnosticKind = "UNKNOWN_TABLE_FUNCTION"
+	DiagnosticArgumentCountMismatch DiagnosticKind = "NUMBER_OF_ARGUMENTS_DOESNT_MATCH"
+	DiagnosticIllegalAggregation    DiagnosticKind = "ILLEGAL_AGGREGATION"
+	DiagnosticIllegalWindowFunction DiagnosticKind = "ILLEGAL_WINDOW_FUNCTION"
+)
+
+// Diagnostic is a semantic problem found by Validate. Pos and End delimit
+// the offending node, like the Pos of a ParseError, so that editors can
+// underline it.
+type Diagnostic struct {
+	Kind DiagnosticKind
+	Pos  Pos
+	End  Pos
+	Msg  string
+}
+
+// DatabaseProvider is implemented by schemas that know which databases
+// exist, so that Validate can tell an unknown database from an unknown
+// table. Catalog implements it.
+type DatabaseProvider interface {
+	HasDatabase(name string) bool
+}
+
+// Validate checks the tables and columns a statement references against a
+// schema, such as a Catalog built from DDL statements. It reports unknown
+// databases, tables and columns, unqualified columns that several joined
+// tables have, and INSERT rows or queries whose column count differs from
+// the INSERT column list.
+//
+// A column is only reported when every table it could come from is known;
+// table functions and tables of the system and information_schema
+// databases are trusted, as are virtual columns such as _part.
+func Validate(stmt Expr, schema SchemaProvider) []Diagnostic {
+	if stmt == nil || schema == nil {
+		return nil
+	}
+	v := &validator{
+		schema:   schema,
+		using:    make(map[*lineageScope]map[string]bool),
+		reported: make(map[Diagnostic]bool),
+	}
+	v.analyzer = newLineageAnalyzer(schema, LambdaBindings(stmt), v)
+	switch s := stmt.(type) {
+	case *SelectQuery:
+		v.analyzer.resolver.query(s, nil)
+	case *InsertStmt:
+		v.checkInsert(s)
+	case *CreateView:
+		if s.SubQuery != nil && s.SubQuery.Select != nil {
+			v.analyzer.resolver.query(s.SubQuery.Select, nil)
+		}
+	case *CreateMaterializedView:
+		if s.Destination != nil {
+			v.tableScope(s.Destination.TableIdentifier)
+		}
+		if s.SubQuery != nil && s.SubQuery.Select != nil {
+			v.analyzer.resolver.query(s.SubQuery.Select, nil)
+		}
+	case *CreateLiveView:
+		if s.SubQuery != nil && s.SubQuery.Select != nil {
+			v.analyzer.resolver.query(s.SubQuery.Select, nil)
+		}
+	case *DeleteClause:
+		v.analyzer.exprSources(s.WhereExpr, v.tableScope(s.Table))
+	case *AlterTable:
+		v.checkAlterTable(s)
+	}
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		return v.diagnostics[i].Pos < v.diagnostics[j].Pos
+	})
+	return v.diagnostics
+}
+
+type validator struct {
+	schema   SchemaProvider
+	analyzer *lineageAnalyzer
+	// using holds the USING columns of the joins of each scope, which are
+	// not ambiguous.
+	using       map[*lineageScope]map[string]bool
+	diagnostics []Diagnostic
+	// reported drops duplicates, as an alias is resolved every time it is
+	// referenced.
+	reported map[Diagnostic]bool
+}
+
+func (v *validator) report(kind DiagnosticKind, node Expr, format string, args ...any) {
+	diagnostic := Diagnostic{Kind: kind, Pos: node.Pos(), End: node.End(), Msg: fmt.Sprintf(format, args...)}
+	if v.reported[diagnostic] {
+		return
+	}
+	v.reported[diagnostic] = true
+	v.diagnostics = append(v.diagnostics, diagnostic)
+}
+
+// tableScope returns a scope holding a single table, for the statements
+// that act on one table without a FROM clause.
+func (v *validator) tableScope(table *TableIdentifier) *lineageScope {
+	scope := newScope[[]ColumnSource](nil)
+	if table == nil || table.Table == nil {
+		return scope
+	}
+	if relation := v.analyzer.resolver.tableRelation(&TableExpr{Expr: table}, scope); relation != nil {
+		scope.relations = append(scope.relations, relation)
+	}
+	return scope
+}
+
+// checkTable is called for a table whose columns the schema does not know.
+func (v *validator) checkTable(table *TableIdentifier) {
+	if table.Database != nil {
+		switch strings.ToLower(table.Database.Name) {
+		case "system", "information_schema":
+			return
+		}
+		if databases, ok := v.schema.(DatabaseProvider); ok && !databases.HasDatabase(table.Database.Name) {
+			v.report(DiagnosticUnknownDatabase, table.Database, "unknown database %s", table.Database.Name)
+			return
+		}
+	}
+	v.report(DiagnosticUnknownTable, table, "unknown table %s", Format(table))
+}
+
+// checkClauses checks the clauses of a SELECT that do not produce output
+// columns. Its scope already holds the relations and aliases of the SELECT.
+func (v *validator) checkClauses(q *SelectQuery, scope *lineageScope) {
+	if q.From != nil {
+		v.checkJoins(q.From.Expr, scope)
+	}
+	var clauses []Expr
+	if q.Prewhere != nil {
+		clauses = append(clauses, q.Prewhere.Expr)
+	}
+	if q.Where != nil {
+		clauses = append(clauses, q.Where.Expr)
+	}
+	if q.GroupBy != nil {
+		clauses = append(clauses, q.GroupBy.Expr)
+	}
+	if q.Having != nil {
+		clauses = append(clauses, q.Having.Expr)
+	}
+	if q.OrderBy != nil {
+		clauses = append(clauses, q.OrderBy.Items...)
+	}
+	if q.LimitBy != nil && q.LimitBy.ByExpr != nil {
+		clauses = append(clauses, q.LimitBy.ByExpr)
+	}
+	for _, clause := range clauses {
+		v.analyzer.exprSources(clause, scope)
+	}
+}
+
+func (v *validator) checkJoins(expr Expr, scope *lineageScope) {
+	join, ok := expr.(*JoinExpr)
+	if !ok {
+		return
+	}
+	v.checkJoins(join.Left, scope)
+	v.checkJoins(join.Right, scope)
+	switch constraint := join.Constraints.(type) {
+	case *OnClause:
+		v.analyzer.exprSources(constraint.On, scope)
+	case *UsingClause:
+		if v.using[scope] == nil {
+			v.using[scope] = make(map[string]bool)
+		}
+		for _, item := range constraint.Using.Items {
+			if ident, ok := unwrapColumnExpr(item).(*Ident); ok {
+				v.using[scope][ident.Name] = true
+			}
+		}
+	}
+}
+
+// columnLookup is the outcome of looking a column up in one scope.
+type columnLookup int
+
+const (
+	columnNotFound columnLookup = iota
+	columnFound
+	// columnUnknown means the column may come from a relation whose columns
+	// are unknown.
+	columnUnknown
+	columnMissing
+	columnAmbiguous
+)
+
+func (v *validator) checkColumn(node Expr, parts []string, scope *lineageScope) {
+	name := strings.Join(parts, ".")
+	if strings.HasPrefix(parts[len(parts)-1], "_") {
+		return
+	}
+	if len(parts) == 1 {
+		switch strings.ToLower(name) {
+		case "true", "false", "null":
+			return
+		}
+	}
+	for s := scope; s != nil; s = s.parent {
+		switch v.lookupColumn(parts, s) {
+		case columnFound, columnUnknown:
+			return
+		case columnMissing:
+			v.report(DiagnosticUnknownColumn, node, "unknown column %s", name)
+			return
+		case columnAmbiguous:
+			v.report(DiagnosticAmbiguousColumn, node, "column %s is ambiguous, qualify it with a table name", name)
+			return
+		}
+	}
+	v.report(DiagnosticUnknownColumn, node, "unknown column %s", name)
+}
+
+// lookupColumn follows resolver.columnLocal, telling apart the outcomes it
+// does not distinguish.
+func (v *validator) lookupColumn(parts []string, s *lineageScope) columnLookup {
+	name := strings.Join(parts, ".")
+	if _, ok := s.arrayJoins[name]; ok {
+		return columnFound
+	}
+	if len(parts) == 1 {
+		if _, ok := s.params[name]; ok {
+			return columnFound
+		}
+		if _, ok := s.aliases[name]; ok {
+			return columnFound
+		}
+	}
+	matches := 0
+	for _, relation := range s.relations {
+		if relation.columns == nil {
+			continue
+		}
+		if _, ok := relation.column(name); ok {
+			matches++
+		}
+	}
+	if matches > 1 && len(parts) == 1 && !v.using[s][name] {
+		return columnAmbiguous
+	}
+	if matches > 0 {
+		return columnFound
+	}
+	for i := len(parts) - 1; i > 0; i-- {
+		qualifier := strings.Join(parts[:i], ".")
+		for _, relation := range s.relations {
+			if !relation.hasName(qualifier) {
+				continue
+			}
+			if relation.columns == nil {
+				return columnUnknown
+			}
+			// the column may be dotted itself, or have subcolumns
+			if _, ok := relation.column(strings.Join(parts[i:], ".")); ok {
+				return columnFound
+			}
+			if _, ok := relation.column(parts[i]); ok {
+				return columnFound
+			}
+			return columnMissing
+		}
+	}
+	for _, relation := range s.relations {
+		if relation.columns == nil {
+			return columnUnknown
+		}
+	}
+	if len(parts) > 1 {
+		// a subcolumn such as tuple_column.field
+		return v.lookupColumn(parts[:1], s)
+	}
+	return columnNotFound
+}
+
+func (v *validator) checkInsert(s *InsertStmt) {
+	table, ok := s.Table.(*TableIdentifier)
+	if !ok {
+		return
+	}
+	scope := v.tableScope(table)
+	if s.ColumnNames != nil {
+		for i := range s.ColumnNames.ColumnNames {
+			name := &s.ColumnNames.ColumnNames[i]
+			v.checkColumn(name, identifierParts(name), scope)
+		}
+	}
+	var columns []lineageColumn
+	if s.SelectExpr != nil {
+		columns = v.analyzer.resolver.query(s.SelectExpr, nil)
+	}
+	if s.ColumnNames == nil {
+		return
+	}
+	expected := len(s.ColumnNames.ColumnNames)
+	for _, row := range s.Values {
+		if len(row.Values) != expected {
+			v.report(DiagnosticColumnCountMismatch, row, "INSERT has %d columns but the row has %d values", expected, len(row.Values))
+		}
+	}
+	if s.SelectExpr == nil {
+		return
+	}
+	for _, column := range columns {
+		if column.name == "*" {
+			// the columns of the table behind * are unknown
+			return
+		}
+	}
+	if len(columns) != expected {
+		v.report(DiagnosticColumnCountMismatch, s.SelectExpr, "INSERT has %d columns but the query returns %d", expected, len(columns))
+	}
+}
+
+// checkAlterTable checks the table of an ALTER TABLE, and the columns read
+// by its mutations.
+func (v *validator) checkAlterTable(s *AlterTable) {
+	scope := v.tableScope(s.TableIdentifier)
+	for _, clause := range s.AlterExprs {
+		switch c := clause.(type) {
+		case *AlterTableUpdate:
+			for _, assignment := range c.Assignments {
+				v.checkColumn(assignment.Column, identifierParts(assignment.Column), scope)
+				v.analyzer.exprSources(assignment.Expr, scope)
+			}
+			v.analyzer.exprSources(c.WhereClause, scope)
+		case *AlterTableDelete:
+			v.analyzer.exprSources(c.WhereClause, scope)
+		}
+	}
+}
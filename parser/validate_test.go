@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const validateSchemaDDL = `
+CREATE DATABASE db;
+CREATE TABLE db.events (id UInt64, user_id UInt64, ts DateTime, tags Array(String), n Nested(key String, value String)) ENGINE = MergeTree ORDER BY id;
+CREATE TABLE users (id UInt64, name String, country String) ENGINE = MergeTree ORDER BY id;
+`
+
+func TestValidate(t *testing.T) {
+	schema := catalogOf(t, validateSchemaDDL)
+	tests := []struct {
+		name     string
+		sql      string
+		expected []string
+	}{
+		{
+			name: "valid query",
+			sql: "WITH recent AS (SELECT user_id, max(ts) AS last FROM db.events GROUP BY user_id) " +
+				"SELECT u.name, r.last, toDate(last) AS day, tag, _part, {p: UInt8} " +
+				"FROM users u JOIN recent r ON u.id = r.user_id ARRAY JOIN ['a'] AS tag " +
+				"WHERE day > today() - 7 AND arrayExists(x -> x > 1, [1, 2]) AND u.id IN (SELECT id FROM db.events) " +
+				"ORDER BY day",
+		},
+		{
+			name: "EXTRACT units are not columns",
+			sql:  "SELECT EXTRACT(DAY FROM ts) FROM db.events",
+		},
+		{
+			name: ":: types are not columns",
+			sql:  "SELECT id::String, name::Nullable(String) FROM users",
+		},
+		{
+			name:     "window specifications",
+			sql:      "SELECT sum(id) OVER (PARTITION BY user_id ORDER BY tss) FROM db.events",
+			expected: []string{"UNKNOWN_COLUMN tss"},
+		},
+		{
+			name:     "unknown database and table",
+			sql:      "SELECT 1 FROM nodb.t, db.missing, system.numbers, numbers(10)",
+			expected: []string{"UNKNOWN_DATABASE nodb", "UNKNOWN_TABLE db.missing"},
+		},
+		{
+			name:     "unknown columns",
+			sql:      "SELECT id, nope, u.missing FROM users u WHERE other > 1 ORDER BY id",
+			expected: []string{"UNKNOWN_COLUMN nope", "UNKNOWN_COLUMN u.missing", "UNKNOWN_COLUMN other"},
+		},
+		{
+			name:     "columns of unknown tables are not reported",
+			sql:      "SELECT a, b FROM db.missing JOIN users ON users.id = missing.x",
+			expected: []string{"UNKNOWN_TABLE db.missing"},
+		},
+		{
+			name:     "ambiguous columns",
+			sql:      "SELECT id, name, e.id FROM users JOIN db.events e ON users.id = e.user_id WHERE id > 1",
+			expected: []string{"AMBIGUOUS_COLUMN id", "AMBIGUOUS_COLUMN id"},
+		},
+		{
+			name: "nested and array columns",
+			sql:  "SELECT n.key, e.n.value, t, tags FROM db.events AS e ARRAY JOIN tags AS t",
+		},
+		{
+			name: "USING columns are not ambiguous",
+			sql:  "SELECT id FROM users JOIN db.events USING (id)",
+		},
+		{
+			name:     "subqueries see outer columns",
+			sql:      "SELECT name, (SELECT count() FROM db.events e WHERE e.user_id = users.id AND ghost = 1) FROM users",
+			expected: []string{"UNKNOWN_COLUMN ghost"},
+		},
+		{
+			name:     "insert values",
+			sql:      "INSERT INTO users (id, name) VALUES (1, 'a'), (2, 'b', 'c')",
+			expected: []string{"COLUMN_COUNT_MISMATCH (2, 'b', 'c'"},
+		},
+		{
+			name:     "insert columns",
+			sql:      "INSERT INTO users (id, nickname) VALUES (1, 'a')",
+			expected: []string{"UNKNOWN_COLUMN nickname"},
+		},
+		{
+			name:     "insert select",
+			sql:      "INSERT INTO users (id, name) SELECT id, user_id, ts FROM db.events",
+			expected: []string{"COLUMN_COUNT_MISMATCH SELECT id, user_id, ts FROM db.events"},
+		},
+		{
+			name:     "insert into unknown table",
+			sql:      "INSERT INTO db.nope (a) VALUES (1)",
+			expected: []string{"UNKNOWN_TABLE db.nope"},
+		},
+		{
+			name:     "materialized view",
+			sql:      "CREATE MATERIALIZED VIEW mv TO db.daily AS SELECT toDate(ts) AS day, count() AS c FROM db.events GROUP BY day, bogus",
+			expected: []string{"UNKNOWN_TABLE db.daily", "UNKNOWN_COLUMN bogus"},
+		},
+		{
+			name:     "mutations",
+			sql:      "ALTER TABLE users UPDATE name = upper(nam) WHERE countryy = 'x'",
+			expected: []string{"UNKNOWN_COLUMN nam", "UNKNOWN_COLUMN countryy"},
+		},
+		{
+			name:     "lightweight delete",
+			sql:      "DELETE FROM db.events WHERE uid = 1",
+			expected: []string{"UNKNOWN_COLUMN uid"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, diagnostic := range Validate(parseOneStmt(t, tt.sql), schema) {
+				got = append(got, string(diagnostic.Kind)+" "+tt.sql[diagnostic.Pos:diagnostic.End])
+			}
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestValidateMapSchema(t *testing.T) {
+	schema := MapSchema{"users": {"id", "name"}}
+	diagnostics := Validate(parseOneStmt(t, "SELECT id, age FROM users JOIN db.t ON users.id = t.id"), schema)
+	require.Len(t, diagnostics, 1)
+	require.Equal(t, DiagnosticUnknownTable, diagnostics[0].Kind)
+	require.Equal(t, "unknown table db.t", diagnostics[0].Msg)
+}
@@ -66,6 +66,9 @@ func Walk(node Expr, fn WalkFunc) bool {
 		if !Walk(n.Settings, fn) {
 			return false
 		}
+		if !Walk(n.IntoOutfile, fn) {
+			return false
+		}
 		if !Walk(n.UnionAll, fn) {
 			return false
 		}
@@ -131,6 +134,13 @@ func Walk(node Expr, fn WalkFunc) bool {
 		// Leaf node
 	case *StringLiteral:
 		// Leaf node
+	case *TypedLiteral:
+		if !Walk(n.Type, fn) {
+			return false
+		}
+		if !Walk(n.Value, fn) {
+			return false
+		}
 	case *BoolLiteral:
 		// Leaf node
 	case *NullLiteral:
@@ -153,6 +163,29 @@ func Walk(node Expr, fn WalkFunc) bool {
 		if !Walk(n.RightExpr, fn) {
 			return false
 		}
+	case *LambdaExpr:
+		for _, param := range n.Params {
+			if !Walk(param, fn) {
+				return false
+			}
+		}
+		if !Walk(n.Body, fn) {
+			return false
+		}
+	case *QuantifiedComparisonExpr:
+		if !Walk(n.LeftExpr, fn) {
+			return false
+		}
+		if !Walk(n.SubQuery, fn) {
+			return false
+		}
+	case *DistinctFromExpr:
+		if !Walk(n.LeftExpr, fn) {
+			return false
+		}
+		if !Walk(n.RightExpr, fn) {
+			return false
+		}
 	case *WhenClause:
 		if !Walk(n.When, fn) {
 			return false
@@ -322,6 +355,16 @@ func Walk(node Expr, fn WalkFunc) bool {
 		if !Walk(n.Format, fn) {
 			return false
 		}
+	case *IntoOutfileClause:
+		if !Walk(n.FileName, fn) {
+			return false
+		}
+		if !Walk(n.Compression, fn) {
+			return false
+		}
+		if !Walk(n.CompressionLevel, fn) {
+			return false
+		}
 	case *InsertStmt:
 		if !Walk(n.Table, fn) {
 			return false
@@ -479,6 +522,10 @@ func Walk(node Expr, fn WalkFunc) bool {
 		if !Walk(n.Unit, fn) {
 			return false
 		}
+	case *IntervalLiteral:
+		if !Walk(n.Value, fn) {
+			return false
+		}
 	case *DropStmt:
 		if !Walk(n.Name, fn) {
 			return false
@@ -570,6 +617,11 @@ func Walk(node Expr, fn WalkFunc) bool {
 			return false
 		}
 	case *ExplainStmt:
+		for _, setting := range n.Settings {
+			if !Walk(setting, fn) {
+				return false
+			}
+		}
 		if !Walk(n.Statement, fn) {
 			return false
 		}
@@ -633,12 +685,18 @@ func Walk(node Expr, fn WalkFunc) bool {
 		if !Walk(n.Name, fn) {
 			return false
 		}
+		if !Walk(n.FromPath, fn) {
+			return false
+		}
 		if !Walk(n.UUID, fn) {
 			return false
 		}
 		if !Walk(n.OnCluster, fn) {
 			return false
 		}
+		if !Walk(n.SourceTable, fn) {
+			return false
+		}
 		if !Walk(n.TableSchema, fn) {
 			return false
 		}
@@ -1060,12 +1118,21 @@ func Walk(node Expr, fn WalkFunc) bool {
 		if !Walk(n.AliasExpr, fn) {
 			return false
 		}
+		if !Walk(n.EphemeralExpr, fn) {
+			return false
+		}
 		if !Walk(n.Codec, fn) {
 			return false
 		}
+		if !Walk(n.Statistics, fn) {
+			return false
+		}
 		if !Walk(n.TTL, fn) {
 			return false
 		}
+		if !Walk(n.Settings, fn) {
+			return false
+		}
 		if !Walk(n.Comment, fn) {
 			return false
 		}
@@ -1127,6 +1194,12 @@ func Walk(node Expr, fn WalkFunc) bool {
 		if !Walk(n.Level, fn) {
 			return false
 		}
+	case *StatisticsClause:
+		for _, t := range n.Types {
+			if !Walk(t, fn) {
+				return false
+			}
+		}
 	case *EngineExpr:
 		if !Walk(n.Params, fn) {
 			return false
@@ -1585,6 +1658,9 @@ func Walk(node Expr, fn WalkFunc) bool {
 		if !Walk(n.Target, fn) {
 			return false
 		}
+		if !Walk(n.Settings, fn) {
+			return false
+		}
 	case *DistinctOn:
 		for _, ident := range n.Idents {
 			if !Walk(ident, fn) {
@@ -1648,6 +1724,823 @@ func walkJSONPath(path *JSONPath, fn WalkFunc) bool {
 	return true
 }
 
+// rewriteExpr rewrites the tree rooted at node bottom-up: fn sees each node
+// after its children have been rewritten, and its result replaces the node.
+// Only the nodes held where any expression fits, in a field or slice of type
+// Expr, are replaced; those held by a field of a concrete type, such as the
+// *ParamExprList of a function call or the *NumberLiteral of TOP n, have
+// their children rewritten and stay in place. The root is always replaced.
+func rewriteExpr(node Expr, fn func(Expr) Expr) Expr {
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		return node
+	}
+	rewriteChildren(node, fn)
+	return fn(node)
+}
+
+// rewriteChildren rewrites the children of node, following Walk.
+func rewriteChildren(node Expr, fn func(Expr) Expr) {
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		return
+	}
+
+	switch n := node.(type) {
+	case *SelectQuery:
+		rewriteChildren(n.InnerQuery, fn)
+		rewriteChildren(n.With, fn)
+		rewriteChildren(n.DistinctOn, fn)
+		rewriteChildren(n.Top, fn)
+		for _, item := range n.SelectItems {
+			rewriteChildren(item, fn)
+		}
+		rewriteChildren(n.From, fn)
+		rewriteChildren(n.Window, fn)
+		rewriteChildren(n.Prewhere, fn)
+		rewriteChildren(n.Where, fn)
+		rewriteChildren(n.GroupBy, fn)
+		rewriteChildren(n.Having, fn)
+		rewriteChildren(n.OrderBy, fn)
+		rewriteChildren(n.LimitBy, fn)
+		rewriteChildren(n.Limit, fn)
+		rewriteChildren(n.Settings, fn)
+		rewriteChildren(n.IntoOutfile, fn)
+		rewriteChildren(n.UnionAll, fn)
+		rewriteChildren(n.UnionDistinct, fn)
+		rewriteChildren(n.Except, fn)
+		rewriteChildren(n.Intersect, fn)
+		rewriteChildren(n.Format, fn)
+	case *SubQuery:
+		rewriteChildren(n.Select, fn)
+	case *SelectItem:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		for _, modifier := range n.Modifiers {
+			rewriteChildren(modifier, fn)
+		}
+		rewriteChildren(n.Alias, fn)
+	case *TableExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		rewriteChildren(n.Alias, fn)
+	case *AliasExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		n.Alias = rewriteExpr(n.Alias, fn)
+	case *FunctionExpr:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.Params, fn)
+	case *TableIdentifier:
+		rewriteChildren(n.Database, fn)
+		rewriteChildren(n.Table, fn)
+	case *Ident:
+		// Leaf node
+	case *NumberLiteral:
+		// Leaf node
+	case *StringLiteral:
+		// Leaf node
+	case *TypedLiteral:
+		rewriteChildren(n.Type, fn)
+		rewriteChildren(n.Value, fn)
+	case *BoolLiteral:
+		// Leaf node
+	case *NullLiteral:
+		// Leaf node
+	case *NotNullLiteral:
+		rewriteChildren(n.NullLiteral, fn)
+	case *ColumnExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		rewriteChildren(n.Alias, fn)
+	case *BinaryOperation:
+		n.LeftExpr = rewriteExpr(n.LeftExpr, fn)
+		n.RightExpr = rewriteExpr(n.RightExpr, fn)
+	case *LambdaExpr:
+		for _, param := range n.Params {
+			rewriteChildren(param, fn)
+		}
+		n.Body = rewriteExpr(n.Body, fn)
+	case *QuantifiedComparisonExpr:
+		n.LeftExpr = rewriteExpr(n.LeftExpr, fn)
+		rewriteChildren(n.SubQuery, fn)
+	case *DistinctFromExpr:
+		n.LeftExpr = rewriteExpr(n.LeftExpr, fn)
+		n.RightExpr = rewriteExpr(n.RightExpr, fn)
+	case *WhenClause:
+		n.When = rewriteExpr(n.When, fn)
+		n.Then = rewriteExpr(n.Then, fn)
+		n.Else = rewriteExpr(n.Else, fn)
+	case *CaseExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		for _, when := range n.Whens {
+			rewriteChildren(when, fn)
+		}
+		n.Else = rewriteExpr(n.Else, fn)
+	case *CastExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		n.AsType = rewriteExpr(n.AsType, fn)
+	case *Path:
+		for _, field := range n.Fields {
+			rewriteChildren(field, fn)
+		}
+	case *WithClause:
+		for _, cte := range n.CTEs {
+			rewriteChildren(cte, fn)
+		}
+	case *CTEStmt:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		n.Alias = rewriteExpr(n.Alias, fn)
+	case *FromClause:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *JoinExpr:
+		n.Left = rewriteExpr(n.Left, fn)
+		n.Right = rewriteExpr(n.Right, fn)
+		n.Constraints = rewriteExpr(n.Constraints, fn)
+	case *JoinTableExpr:
+		rewriteChildren(n.Table, fn)
+		rewriteChildren(n.SampleRatio, fn)
+	case *OnClause:
+		rewriteChildren(n.On, fn)
+	case *UsingClause:
+		rewriteChildren(n.Using, fn)
+	case *WhereClause:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *PrewhereClause:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *GroupByClause:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *HavingClause:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *OrderByClause:
+		for i := range n.Items {
+			n.Items[i] = rewriteExpr(n.Items[i], fn)
+		}
+		rewriteChildren(n.Interpolate, fn)
+	case *OrderExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		rewriteChildren(n.Alias, fn)
+		rewriteChildren(n.Fill, fn)
+	case *Fill:
+		n.From = rewriteExpr(n.From, fn)
+		n.To = rewriteExpr(n.To, fn)
+		n.Step = rewriteExpr(n.Step, fn)
+		n.Staleness = rewriteExpr(n.Staleness, fn)
+	case *InterpolateClause:
+		for _, item := range n.Items {
+			rewriteChildren(item, fn)
+		}
+	case *InterpolateItem:
+		rewriteChildren(n.Column, fn)
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *LimitClause:
+		n.Limit = rewriteExpr(n.Limit, fn)
+		n.Offset = rewriteExpr(n.Offset, fn)
+	case *LimitByClause:
+		rewriteChildren(n.Limit, fn)
+		rewriteChildren(n.ByExpr, fn)
+	case *SettingsClause:
+		for _, item := range n.Items {
+			rewriteChildren(item, fn)
+		}
+	case *SettingExpr:
+		rewriteChildren(n.Name, fn)
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *FormatClause:
+		rewriteChildren(n.Format, fn)
+	case *IntoOutfileClause:
+		rewriteChildren(n.FileName, fn)
+		rewriteChildren(n.Compression, fn)
+		rewriteChildren(n.CompressionLevel, fn)
+	case *InsertStmt:
+		n.Table = rewriteExpr(n.Table, fn)
+		rewriteChildren(n.ColumnNames, fn)
+		rewriteChildren(n.Format, fn)
+		for _, value := range n.Values {
+			rewriteChildren(value, fn)
+		}
+		rewriteChildren(n.SelectExpr, fn)
+	case *ColumnNamesExpr:
+		for i := range n.ColumnNames {
+			rewriteChildren(&n.ColumnNames[i], fn)
+		}
+	case *AssignmentValues:
+		for i := range n.Values {
+			n.Values[i] = rewriteExpr(n.Values[i], fn)
+		}
+	case *TableFunctionExpr:
+		n.Name = rewriteExpr(n.Name, fn)
+		rewriteChildren(n.Args, fn)
+	case *TableArgListExpr:
+		for i := range n.Args {
+			n.Args[i] = rewriteExpr(n.Args[i], fn)
+		}
+	case *NestedIdentifier:
+		rewriteChildren(n.Ident, fn)
+		rewriteChildren(n.DotIdent, fn)
+	case *ArrayParamList:
+		rewriteChildren(n.Items, fn)
+	case *ColumnExprList:
+		for i := range n.Items {
+			n.Items[i] = rewriteExpr(n.Items[i], fn)
+		}
+	case *ParamExprList:
+		rewriteChildren(n.Items, fn)
+		rewriteChildren(n.ColumnArgList, fn)
+	case *ColumnArgList:
+		for i := range n.Items {
+			n.Items[i] = rewriteExpr(n.Items[i], fn)
+		}
+	case *WindowClause:
+		for _, window := range n.Windows {
+			if window == nil {
+				continue
+			}
+			rewriteChildren(window.Name, fn)
+			rewriteChildren(window.Expr, fn)
+		}
+	case *WindowExpr:
+		rewriteChildren(n.WindowName, fn)
+		rewriteChildren(n.PartitionBy, fn)
+		rewriteChildren(n.OrderBy, fn)
+		rewriteChildren(n.Frame, fn)
+	case *PartitionByClause:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *WindowFrameClause:
+		n.Extend = rewriteExpr(n.Extend, fn)
+	case *WindowFrameExtendExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *BetweenClause:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		n.Between = rewriteExpr(n.Between, fn)
+		n.And = rewriteExpr(n.And, fn)
+	case *WindowFrameCurrentRow:
+		// Leaf node
+	case *WindowFrameUnbounded:
+		// Leaf node
+	case *WindowFrameNumber:
+		rewriteChildren(n.Number, fn)
+	case *WindowFrameParam:
+		rewriteChildren(n.Param, fn)
+	case *TopClause:
+		rewriteChildren(n.Number, fn)
+	case *SampleClause:
+		rewriteChildren(n.Ratio, fn)
+		rewriteChildren(n.Offset, fn)
+	case *RatioExpr:
+		rewriteChildren(n.Numerator, fn)
+		rewriteChildren(n.Denominator, fn)
+	case *IntervalExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		rewriteChildren(n.Unit, fn)
+	case *IntervalLiteral:
+		rewriteChildren(n.Value, fn)
+	case *DropStmt:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.OnCluster, fn)
+	case *DropDatabase:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.OnCluster, fn)
+	case *DropUserOrRole:
+		for _, name := range n.Names {
+			rewriteChildren(name, fn)
+		}
+		rewriteChildren(n.From, fn)
+	case *TruncateTable:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.OnCluster, fn)
+	case *CheckStmt:
+		rewriteChildren(n.Table, fn)
+		rewriteChildren(n.Partition, fn)
+	case *OptimizeStmt:
+		rewriteChildren(n.Table, fn)
+		rewriteChildren(n.OnCluster, fn)
+		rewriteChildren(n.Partition, fn)
+		rewriteChildren(n.Deduplicate, fn)
+	case *DeduplicateClause:
+		rewriteChildren(n.By, fn)
+		rewriteChildren(n.Except, fn)
+	case *SystemStmt:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *SystemFlushExpr:
+		rewriteChildren(n.Distributed, fn)
+	case *SystemReloadExpr:
+		rewriteChildren(n.OnCluster, fn)
+		rewriteChildren(n.Dictionary, fn)
+	case *SystemSyncExpr:
+		rewriteChildren(n.Cluster, fn)
+	case *SystemCtrlExpr:
+		rewriteChildren(n.Cluster, fn)
+	case *SystemDropExpr:
+		// Leaf node
+	case *UseStmt:
+		rewriteChildren(n.Database, fn)
+	case *SetStmt:
+		rewriteChildren(n.Settings, fn)
+	case *ExplainStmt:
+		for _, setting := range n.Settings {
+			rewriteChildren(setting, fn)
+		}
+		n.Statement = rewriteExpr(n.Statement, fn)
+	case *GrantPrivilegeStmt:
+		for _, privilege := range n.Privileges {
+			rewriteChildren(privilege, fn)
+		}
+		rewriteChildren(n.On, fn)
+		for _, role := range n.To {
+			rewriteChildren(role, fn)
+		}
+		rewriteChildren(n.OnCluster, fn)
+	case *PrivilegeClause:
+		rewriteChildren(n.Params, fn)
+	case *RenameStmt:
+		for _, pair := range n.TargetPairList {
+			rewriteChildren(pair.Old, fn)
+			rewriteChildren(pair.New, fn)
+		}
+		rewriteChildren(n.OnCluster, fn)
+	case *DeleteClause:
+		rewriteChildren(n.Table, fn)
+		rewriteChildren(n.OnCluster, fn)
+		n.WhereExpr = rewriteExpr(n.WhereExpr, fn)
+	case *CreateDatabase:
+		n.Name = rewriteExpr(n.Name, fn)
+		rewriteChildren(n.OnCluster, fn)
+		rewriteChildren(n.Engine, fn)
+		rewriteChildren(n.Comment, fn)
+	case *CreateTable:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.FromPath, fn)
+		rewriteChildren(n.UUID, fn)
+		rewriteChildren(n.OnCluster, fn)
+		rewriteChildren(n.SourceTable, fn)
+		rewriteChildren(n.TableSchema, fn)
+		rewriteChildren(n.Engine, fn)
+		rewriteChildren(n.SubQuery, fn)
+		rewriteChildren(n.TableFunction, fn)
+		rewriteChildren(n.Comment, fn)
+	case *CreateView:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.UUID, fn)
+		rewriteChildren(n.OnCluster, fn)
+		rewriteChildren(n.TableSchema, fn)
+		rewriteChildren(n.Comment, fn)
+		rewriteChildren(n.SubQuery, fn)
+	case *CreateMaterializedView:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.OnCluster, fn)
+		rewriteChildren(n.Refresh, fn)
+		rewriteChildren(n.RandomizeFor, fn)
+		for _, dep := range n.DependsOn {
+			rewriteChildren(dep, fn)
+		}
+		rewriteChildren(n.Settings, fn)
+		rewriteChildren(n.TableSchema, fn)
+		rewriteChildren(n.Engine, fn)
+		rewriteChildren(n.Destination, fn)
+		rewriteChildren(n.SubQuery, fn)
+		rewriteChildren(n.Comment, fn)
+		rewriteChildren(n.Definer, fn)
+	case *CreateLiveView:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.UUID, fn)
+		rewriteChildren(n.OnCluster, fn)
+		rewriteChildren(n.Destination, fn)
+		rewriteChildren(n.TableSchema, fn)
+		rewriteChildren(n.WithTimeout, fn)
+		rewriteChildren(n.SubQuery, fn)
+	case *CreateDictionary:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.UUID, fn)
+		rewriteChildren(n.OnCluster, fn)
+		rewriteChildren(n.Schema, fn)
+		rewriteChildren(n.Engine, fn)
+		rewriteChildren(n.Comment, fn)
+	case *CreateFunction:
+		rewriteChildren(n.FunctionName, fn)
+		rewriteChildren(n.OnCluster, fn)
+		rewriteChildren(n.Params, fn)
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *CreateNamedCollection:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.OnCluster, fn)
+		for _, param := range n.Params {
+			rewriteChildren(param, fn)
+		}
+	case *NamedCollectionParam:
+		rewriteChildren(n.Name, fn)
+		n.Value = rewriteExpr(n.Value, fn)
+	case *CreateRole:
+		for _, name := range n.RoleNames {
+			rewriteChildren(name, fn)
+		}
+		rewriteChildren(n.AccessStorageType, fn)
+		for _, setting := range n.Settings {
+			rewriteChildren(setting, fn)
+		}
+	case *CreateUser:
+		for _, name := range n.UserNames {
+			rewriteChildren(name, fn)
+		}
+		rewriteChildren(n.Authentication, fn)
+		rewriteChildren(n.ValidUntil, fn)
+		for _, host := range n.Hosts {
+			rewriteChildren(host, fn)
+		}
+		rewriteChildren(n.DefaultRole, fn)
+		rewriteChildren(n.DefaultDatabase, fn)
+		rewriteChildren(n.Grantees, fn)
+		for _, setting := range n.Settings {
+			rewriteChildren(setting, fn)
+		}
+	case *AlterTable:
+		rewriteChildren(n.TableIdentifier, fn)
+		rewriteChildren(n.OnCluster, fn)
+		for _, expr := range n.AlterExprs {
+			rewriteChildren(expr, fn)
+		}
+	case *AlterTableAttachPartition:
+		rewriteChildren(n.Partition, fn)
+		rewriteChildren(n.From, fn)
+	case *AlterTableDetachPartition:
+		rewriteChildren(n.Partition, fn)
+		rewriteChildren(n.Settings, fn)
+	case *AlterTableDropPartition:
+		rewriteChildren(n.Partition, fn)
+		rewriteChildren(n.Settings, fn)
+	case *AlterTableMaterializeProjection:
+		rewriteChildren(n.ProjectionName, fn)
+		rewriteChildren(n.Partition, fn)
+	case *AlterTableMaterializeIndex:
+		rewriteChildren(n.IndexName, fn)
+		rewriteChildren(n.Partition, fn)
+	case *AlterTableFreezePartition:
+		rewriteChildren(n.Partition, fn)
+	case *AlterTableAddColumn:
+		rewriteChildren(n.Column, fn)
+		rewriteChildren(n.After, fn)
+		rewriteChildren(n.Settings, fn)
+	case *AlterTableAddIndex:
+		rewriteChildren(n.Index, fn)
+		rewriteChildren(n.After, fn)
+	case *AlterTableAddProjection:
+		rewriteChildren(n.TableProjection, fn)
+		rewriteChildren(n.After, fn)
+	case *AlterTableDropColumn:
+		rewriteChildren(n.ColumnName, fn)
+	case *AlterTableDropIndex:
+		rewriteChildren(n.IndexName, fn)
+	case *AlterTableDropProjection:
+		rewriteChildren(n.ProjectionName, fn)
+	case *AlterTableRemoveTTL:
+		// Leaf node
+	case *AlterTableClearColumn:
+		rewriteChildren(n.ColumnName, fn)
+		rewriteChildren(n.PartitionExpr, fn)
+	case *AlterTableClearIndex:
+		rewriteChildren(n.IndexName, fn)
+		rewriteChildren(n.PartitionExpr, fn)
+	case *AlterTableClearProjection:
+		rewriteChildren(n.ProjectionName, fn)
+		rewriteChildren(n.PartitionExpr, fn)
+	case *AlterTableRenameColumn:
+		rewriteChildren(n.OldColumnName, fn)
+		rewriteChildren(n.NewColumnName, fn)
+	case *AlterTableModifyQuery:
+		rewriteChildren(n.SelectExpr, fn)
+	case *AlterTableModifyOrderBy:
+		n.OrderBy = rewriteExpr(n.OrderBy, fn)
+	case *AlterTableModifyTTL:
+		rewriteChildren(n.TTL, fn)
+	case *AlterTableModifyColumn:
+		rewriteChildren(n.Column, fn)
+		rewriteChildren(n.RemovePropertyType, fn)
+	case *AlterTableModifySetting:
+		for _, setting := range n.Settings {
+			rewriteChildren(setting, fn)
+		}
+	case *AlterTableResetSetting:
+		for _, setting := range n.Settings {
+			rewriteChildren(setting, fn)
+		}
+	case *AlterTableReplacePartition:
+		rewriteChildren(n.Partition, fn)
+		rewriteChildren(n.Table, fn)
+	case *AlterTableDelete:
+		n.WhereClause = rewriteExpr(n.WhereClause, fn)
+	case *AlterTableUpdate:
+		for _, assignment := range n.Assignments {
+			rewriteChildren(assignment, fn)
+		}
+		rewriteChildren(n.InPartition, fn)
+		n.WhereClause = rewriteExpr(n.WhereClause, fn)
+	case *UpdateAssignment:
+		rewriteChildren(n.Column, fn)
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *AlterRole:
+		for _, pair := range n.RoleRenamePairs {
+			rewriteChildren(pair, fn)
+		}
+		for _, setting := range n.Settings {
+			rewriteChildren(setting, fn)
+		}
+	case *RoleRenamePair:
+		rewriteChildren(n.RoleName, fn)
+		n.NewName = rewriteExpr(n.NewName, fn)
+	case *TableSchemaClause:
+		for i := range n.Columns {
+			n.Columns[i] = rewriteExpr(n.Columns[i], fn)
+		}
+		rewriteChildren(n.AliasTable, fn)
+		rewriteChildren(n.TableFunction, fn)
+	case *ColumnDef:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.Type, fn)
+		rewriteChildren(n.NotNull, fn)
+		rewriteChildren(n.Nullable, fn)
+		n.DefaultExpr = rewriteExpr(n.DefaultExpr, fn)
+		n.MaterializedExpr = rewriteExpr(n.MaterializedExpr, fn)
+		n.AliasExpr = rewriteExpr(n.AliasExpr, fn)
+		n.EphemeralExpr = rewriteExpr(n.EphemeralExpr, fn)
+		rewriteChildren(n.Codec, fn)
+		rewriteChildren(n.Statistics, fn)
+		rewriteChildren(n.TTL, fn)
+		rewriteChildren(n.Settings, fn)
+		rewriteChildren(n.Comment, fn)
+		rewriteChildren(n.CompressionCodec, fn)
+	case *ScalarType:
+		rewriteChildren(n.Name, fn)
+	case *JSONType:
+		rewriteChildren(n.Name, fn)
+		rewriteJSONOptions(n.Options, fn)
+	case *PropertyType:
+		rewriteChildren(n.Name, fn)
+	case *TypeWithParams:
+		rewriteChildren(n.Name, fn)
+		for _, param := range n.Params {
+			rewriteChildren(param, fn)
+		}
+	case *ComplexType:
+		rewriteChildren(n.Name, fn)
+		for _, param := range n.Params {
+			rewriteChildren(param, fn)
+		}
+	case *NestedType:
+		rewriteChildren(n.Name, fn)
+		for i := range n.Columns {
+			n.Columns[i] = rewriteExpr(n.Columns[i], fn)
+		}
+	case *CompressionCodec:
+		rewriteChildren(n.Type, fn)
+		rewriteChildren(n.TypeLevel, fn)
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.Level, fn)
+	case *StatisticsClause:
+		for _, t := range n.Types {
+			rewriteChildren(t, fn)
+		}
+	case *EngineExpr:
+		rewriteChildren(n.Params, fn)
+		rewriteChildren(n.PrimaryKey, fn)
+		rewriteChildren(n.PartitionBy, fn)
+		rewriteChildren(n.SampleBy, fn)
+		rewriteChildren(n.TTL, fn)
+		rewriteChildren(n.Settings, fn)
+		rewriteChildren(n.OrderBy, fn)
+	case *PrimaryKeyClause:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *SampleByClause:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *TTLClause:
+		for _, item := range n.Items {
+			rewriteChildren(item, fn)
+		}
+	case *TTLExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		rewriteChildren(n.Policy, fn)
+	case *TTLPolicy:
+		rewriteChildren(n.Item, fn)
+		rewriteChildren(n.Where, fn)
+	case *TTLPolicyRule:
+		rewriteChildren(n.ToVolume, fn)
+		rewriteChildren(n.ToDisk, fn)
+		rewriteChildren(n.Action, fn)
+		rewriteChildren(n.GroupBy, fn)
+		for _, set := range n.Set {
+			rewriteChildren(set, fn)
+		}
+	case *TTLPolicyRuleAction:
+		rewriteChildren(n.Codec, fn)
+	case *RefreshExpr:
+		rewriteChildren(n.Interval, fn)
+		rewriteChildren(n.Offset, fn)
+	case *DestinationClause:
+		rewriteChildren(n.TableIdentifier, fn)
+		rewriteChildren(n.TableSchema, fn)
+	case *ConstraintClause:
+		rewriteChildren(n.Constraint, fn)
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *RoleName:
+		n.Name = rewriteExpr(n.Name, fn)
+		rewriteChildren(n.Scope, fn)
+		rewriteChildren(n.OnCluster, fn)
+	case *SettingPair:
+		rewriteChildren(n.Name, fn)
+		n.Value = rewriteExpr(n.Value, fn)
+	case *RoleSetting:
+		for _, pair := range n.SettingPairs {
+			rewriteChildren(pair, fn)
+		}
+		rewriteChildren(n.Modifier, fn)
+	case *AuthenticationClause:
+		rewriteChildren(n.AuthValue, fn)
+		rewriteChildren(n.LdapServer, fn)
+		rewriteChildren(n.KerberosRealm, fn)
+	case *HostClause:
+		rewriteChildren(n.HostValue, fn)
+	case *DefaultRoleClause:
+		for _, role := range n.Roles {
+			rewriteChildren(role, fn)
+		}
+	case *GranteesClause:
+		for _, grantee := range n.Grantees {
+			rewriteChildren(grantee, fn)
+		}
+		for _, except := range n.ExceptUsers {
+			rewriteChildren(except, fn)
+		}
+	case *WithTimeoutClause:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		rewriteChildren(n.Number, fn)
+	case *DictionarySchemaClause:
+		for _, attr := range n.Attributes {
+			rewriteChildren(attr, fn)
+		}
+	case *DictionaryAttribute:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.Type, fn)
+		rewriteChildren(n.Default, fn)
+		n.Expression = rewriteExpr(n.Expression, fn)
+	case *DictionaryEngineClause:
+		rewriteChildren(n.PrimaryKey, fn)
+		rewriteChildren(n.Source, fn)
+		rewriteChildren(n.Lifetime, fn)
+		rewriteChildren(n.Layout, fn)
+		rewriteChildren(n.Range, fn)
+		rewriteChildren(n.Settings, fn)
+	case *DictionaryPrimaryKeyClause:
+		rewriteChildren(n.Keys, fn)
+	case *DictionarySourceClause:
+		rewriteChildren(n.Source, fn)
+		for _, arg := range n.Args {
+			rewriteChildren(arg, fn)
+		}
+	case *DictionaryArgExpr:
+		rewriteChildren(n.Name, fn)
+		n.Value = rewriteExpr(n.Value, fn)
+		for _, arg := range n.Args {
+			rewriteChildren(arg, fn)
+		}
+	case *DictionaryLifetimeClause:
+		rewriteChildren(n.Value, fn)
+		rewriteChildren(n.Min, fn)
+		rewriteChildren(n.Max, fn)
+	case *DictionaryLayoutClause:
+		rewriteChildren(n.Layout, fn)
+		for _, arg := range n.Args {
+			rewriteChildren(arg, fn)
+		}
+	case *DictionaryRangeClause:
+		rewriteChildren(n.Min, fn)
+		rewriteChildren(n.Max, fn)
+	case *PlaceHolder:
+		// Leaf node
+	case *TypedPlaceholder:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.Type, fn)
+	case *QueryParam:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.Type, fn)
+	case *MapLiteral:
+		for i := range n.KeyValues {
+			rewriteChildren(&n.KeyValues[i].Key, fn)
+			n.KeyValues[i].Value = rewriteExpr(n.KeyValues[i].Value, fn)
+		}
+	case *NamedParameterExpr:
+		rewriteChildren(n.Name, fn)
+		n.Value = rewriteExpr(n.Value, fn)
+	case *ObjectParams:
+		n.Object = rewriteExpr(n.Object, fn)
+		rewriteChildren(n.Params, fn)
+	case *WindowFunctionExpr:
+		rewriteChildren(n.Function, fn)
+		n.OverExpr = rewriteExpr(n.OverExpr, fn)
+	case *NotExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *NegateExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *GlobalInOperation:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *ExtractExpr:
+		for i := range n.Parameters {
+			n.Parameters[i] = rewriteExpr(n.Parameters[i], fn)
+		}
+	case *IntervalFrom:
+		rewriteChildren(n.Interval, fn)
+		n.FromExpr = rewriteExpr(n.FromExpr, fn)
+	case *IsNullExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *IsNotNullExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *TernaryOperation:
+		n.Condition = rewriteExpr(n.Condition, fn)
+		n.TrueExpr = rewriteExpr(n.TrueExpr, fn)
+		n.FalseExpr = rewriteExpr(n.FalseExpr, fn)
+	case *IndexOperation:
+		n.Object = rewriteExpr(n.Object, fn)
+		n.Index = rewriteExpr(n.Index, fn)
+	case *OperationExpr:
+		// Leaf node
+	case *TableIndex:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.ColumnExpr, fn)
+		n.ColumnType = rewriteExpr(n.ColumnType, fn)
+		rewriteChildren(n.Granularity, fn)
+	case *ProjectionOrderByClause:
+		rewriteChildren(n.Columns, fn)
+	case *ProjectionSelectStmt:
+		rewriteChildren(n.With, fn)
+		rewriteChildren(n.SelectColumns, fn)
+		rewriteChildren(n.GroupBy, fn)
+		rewriteChildren(n.OrderBy, fn)
+	case *TableProjection:
+		rewriteChildren(n.Identifier, fn)
+		rewriteChildren(n.Select, fn)
+	case *RemovePropertyType:
+		n.PropertyType = rewriteExpr(n.PropertyType, fn)
+	case *EnumType:
+		rewriteChildren(n.Name, fn)
+		for i := range n.Values {
+			rewriteChildren(&n.Values[i], fn)
+		}
+	case *EnumValue:
+		rewriteChildren(n.Name, fn)
+		rewriteChildren(n.Value, fn)
+	case *ClusterClause:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *PartitionClause:
+		n.Expr = rewriteExpr(n.Expr, fn)
+		rewriteChildren(n.ID, fn)
+	case *UUID:
+		rewriteChildren(n.Value, fn)
+	case *ColumnTypeExpr:
+		rewriteChildren(n.Name, fn)
+	case *UnaryExpr:
+		n.Expr = rewriteExpr(n.Expr, fn)
+	case *JoinConstraintClause:
+		rewriteChildren(n.On, fn)
+		rewriteChildren(n.Using, fn)
+	case *TargetPair:
+		rewriteChildren(n.Old, fn)
+		rewriteChildren(n.New, fn)
+	case *ShowStmt:
+		rewriteChildren(n.Target, fn)
+		n.LikePattern = rewriteExpr(n.LikePattern, fn)
+		n.Limit = rewriteExpr(n.Limit, fn)
+		rewriteChildren(n.OutFile, fn)
+		rewriteChildren(n.Format, fn)
+	case *DescribeStmt:
+		n.Target = rewriteExpr(n.Target, fn)
+		rewriteChildren(n.Settings, fn)
+	case *DistinctOn:
+		for _, ident := range n.Idents {
+			rewriteChildren(ident, fn)
+		}
+	}
+}
+
+func rewriteJSONOptions(options *JSONOptions, fn func(Expr) Expr) {
+	if options == nil {
+		return
+	}
+	for _, option := range options.Items {
+		if option == nil {
+			continue
+		}
+		rewriteJSONPath(option.SkipPath, fn)
+		rewriteChildren(option.SkipRegex, fn)
+		rewriteChildren(option.MaxDynamicPaths, fn)
+		rewriteChildren(option.MaxDynamicTypes, fn)
+		if option.Column != nil {
+			rewriteJSONPath(option.Column.Path, fn)
+			rewriteChildren(option.Column.Type, fn)
+		}
+	}
+}
+
+func rewriteJSONPath(path *JSONPath, fn func(Expr) Expr) {
+	if path == nil {
+		return
+	}
+	for _, ident := range path.Idents {
+		rewriteChildren(ident, fn)
+	}
+}
+
 // WalkWithBreak allows for early termination of tree traversal.
 // The provided function should return true to continue walking,
 // or false to stop the traversal entirely.
@@ -1724,3 +2617,69 @@ func Transform(root Expr, transformer func(Expr) Expr) Expr {
 
 	return transformed
 }
+
+// LambdaBindings resolves identifiers that refer to lambda parameters.
+// The returned map holds every *Ident in root that names a parameter of an
+// enclosing LambdaExpr, mapped to the innermost lambda that declares it.
+// Identifiers absent from the map are not lambda-bound. They are column,
+// alias, or other references, which is what tooling should check against a schema.
+// Parameter declarations, function names, aliases and member names after a
+// dot are never bound.
+func LambdaBindings(root Expr) map[*Ident]*LambdaExpr {
+	var lambdas []*LambdaExpr
+	nonRefs := make(map[*Ident]bool)
+	Walk(root, func(node Expr) bool {
+		switch n := node.(type) {
+		case *LambdaExpr:
+			lambdas = append(lambdas, n)
+			for _, param := range n.Params {
+				nonRefs[param] = true
+			}
+		case *FunctionExpr:
+			nonRefs[n.Name] = true
+		case *ColumnExpr:
+			if n.Alias != nil {
+				nonRefs[n.Alias] = true
+			}
+		case *AliasExpr:
+			if alias, ok := n.Alias.(*Ident); ok {
+				nonRefs[alias] = true
+			}
+		case *NestedIdentifier:
+			if n.DotIdent != nil {
+				nonRefs[n.DotIdent] = true
+			}
+		case *Path:
+			for i, field := range n.Fields {
+				if i > 0 {
+					nonRefs[field] = true
+				}
+			}
+		case *IndexOperation:
+			if index, ok := n.Index.(*Ident); ok && n.Operation == TokenKindDot {
+				nonRefs[index] = true
+			}
+		}
+		return true
+	})
+
+	// Walk visits outer lambdas before the ones nested in their bodies, so an
+	// inner lambda overwrites the binding of a name it shadows.
+	bindings := make(map[*Ident]*LambdaExpr)
+	for _, lambda := range lambdas {
+		Walk(lambda.Body, func(node Expr) bool {
+			ident, ok := node.(*Ident)
+			if !ok || nonRefs[ident] {
+				return true
+			}
+			for _, param := range lambda.Params {
+				if param.Name == ident.Name {
+					bindings[ident] = lambda
+					break
+				}
+			}
+			return true
+		})
+	}
+	return bindings
+}
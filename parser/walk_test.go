@@ -294,3 +294,62 @@ func TestWalk_KeywordArgFunction(t *testing.T) {
 	// s, a, b, c, d plus the two function names.
 	require.Equal(t, 7, idents)
 }
+
+func TestLambdaBindings(t *testing.T) {
+	sql := `SELECT arrayMap(x -> arrayMap(y -> x + y + z, arr2), arr1), x, arrayFilter((k, x) -> x > k.size, m) FROM t;`
+	stmts, err := NewParser(sql).ParseStmts()
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+
+	bindings := LambdaBindings(stmts[0])
+
+	bound := map[string][]string{}
+	var free []string
+	Walk(stmts[0], func(node Expr) bool {
+		if lambda, ok := node.(*LambdaExpr); ok {
+			// Parameter declarations are not references.
+			for _, param := range lambda.Params {
+				require.NotContains(t, bindings, param)
+			}
+			return true
+		}
+		ident, ok := node.(*Ident)
+		if !ok {
+			return true
+		}
+		if lambda, ok := bindings[ident]; ok {
+			bound[ident.Name] = append(bound[ident.Name], Format(lambda))
+		} else {
+			free = append(free, ident.Name)
+		}
+		return true
+	})
+
+	require.Equal(t, map[string][]string{
+		"x": {"x -> arrayMap(y -> x + y + z, arr2)", "(k, x) -> x > k.size"},
+		"y": {"y -> x + y + z"},
+		"k": {"(k, x) -> x > k.size"},
+	}, bound)
+	// Lambda parameters are visited by Walk but are declarations, so they show
+	// up here alongside genuine column references and function names.
+	require.Subset(t, free, []string{"arrayMap", "z", "arr2", "arr1", "x", "arrayFilter", "size", "m", "t"})
+}
+
+func TestRewriteExpr_ReplacesOnlyWhereAnyExprFits(t *testing.T) {
+	stmts, err := NewParser("SELECT TOP 5 a + 1, f(2) FROM t WHERE b IN (3, 4) LIMIT 6").ParseStmts()
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+
+	var offered []string
+	rewritten := rewriteExpr(stmts[0], func(node Expr) Expr {
+		if literal, ok := node.(*NumberLiteral); ok {
+			offered = append(offered, literal.Literal)
+			return &PlaceHolder{PlaceholderPos: literal.Pos(), PlaceHolderEnd: literal.End(), Type: "?"}
+		}
+		return node
+	})
+
+	// the *NumberLiteral of TOP n holds no other node, so it is not offered
+	require.Equal(t, []string{"1", "2", "3", "4", "6"}, offered)
+	require.Equal(t, "SELECT TOP 5 a + ?, f(?) FROM t WHERE b IN (?, ?) LIMIT ?", Format(rewritten))
+}